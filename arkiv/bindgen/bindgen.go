@@ -0,0 +1,139 @@
+// Package bindgen generates Go bindings for Arkiv entities out of a schema of
+// their content types, annotations and payload fields.
+//
+// For every entity type the bindings have a struct, the operations creating
+// and updating entities from it, a decoder from the entities of query results
+// and operations, and typed query builders producing arkiv_query expressions.
+// The generated code uses the arkiv/bindings package at runtime.
+package bindgen
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"slices"
+	"strconv"
+	"text/template"
+)
+
+//go:embed source.go.tpl
+var tmplSource string
+
+var tmpl = template.Must(template.New("").Parse(tmplSource))
+
+type tmplData struct {
+	Package    string
+	StdImports []string
+	Imports    []string
+	Entities   []*tmplEntity
+}
+
+type tmplEntity struct {
+	Name        string
+	ContentType string
+	JSON        bool // whether the payload is a JSON object of the fields
+	Text        bool // whether the payload is a string
+	Annotations []*tmplAnnotation
+	Fields      []*tmplField
+	Constants   bool // whether some annotations are constant
+	Variables   bool // whether some annotations are not constant
+}
+
+type tmplAnnotation struct {
+	Key     string
+	Field   string
+	Numeric bool
+	Value   string // Go literal of the constant value, empty if not constant
+}
+
+type tmplField struct {
+	Name  string
+	Field string
+	Type  string
+}
+
+// Generate returns the Go source of the bindings of the entities of the schema,
+// in the given package, or in the package of the schema if pkg is empty.
+func Generate(schema *Schema, pkg string) (string, error) {
+	if err := schema.validate(); err != nil {
+		return "", err
+	}
+	if pkg == "" {
+		pkg = schema.Package
+	}
+	if !token.IsIdentifier(pkg) {
+		return "", errors.New("no valid package name given")
+	}
+
+	data := &tmplData{
+		Package:    pkg,
+		StdImports: []string{"fmt"},
+		Imports: []string{
+			"github.com/ethereum/go-ethereum/arkiv/bindings",
+			"github.com/ethereum/go-ethereum/common",
+			"github.com/ethereum/go-ethereum/golem-base/storagetx",
+		},
+	}
+	addImport := func(path string) {
+		if !slices.Contains(data.Imports, path) {
+			data.Imports = append(data.Imports, path)
+		}
+	}
+
+	for _, e := range schema.Entities {
+		kind := e.payloadKind()
+		te := &tmplEntity{
+			Name:        e.Name,
+			ContentType: e.ContentType,
+			JSON:        kind == jsonPayload,
+			Text:        kind == textPayload,
+		}
+		if te.JSON && !slices.Contains(data.StdImports, "encoding/json") {
+			data.StdImports = append(data.StdImports, "encoding/json")
+		}
+		for _, a := range e.Annotations {
+			field, _ := fieldName(a.Name, a.Field)
+			ta := &tmplAnnotation{
+				Key:     a.Name,
+				Field:   field,
+				Numeric: a.Type == "numeric",
+			}
+			if a.Value == nil {
+				te.Variables = true
+			} else {
+				te.Constants = true
+				if ta.Numeric {
+					ta.Value = *a.Value
+				} else {
+					ta.Value = strconv.Quote(*a.Value)
+				}
+			}
+			te.Annotations = append(te.Annotations, ta)
+		}
+		for _, f := range e.Payload {
+			field, _ := fieldName(f.Name, f.Field)
+			typ, _ := goType(f.Type)
+			switch f.Type {
+			case "bytes", "[]bytes":
+				addImport("github.com/ethereum/go-ethereum/common/hexutil")
+			}
+			te.Fields = append(te.Fields, &tmplField{Name: f.Name, Field: field, Type: typ})
+		}
+		data.Entities = append(data.Entities, te)
+	}
+	slices.Sort(data.StdImports)
+	slices.Sort(data.Imports)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%v\n%s", err, buf.String())
+	}
+	return string(code), nil
+}
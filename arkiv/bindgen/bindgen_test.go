@@ -0,0 +1,66 @@
+package bindgen
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateContacts checks that the bindings of internal/contacts, which are
+// tested there, are up to date.
+func TestGenerateContacts(t *testing.T) {
+	data, err := os.ReadFile("testdata/contacts.yaml")
+	require.NoError(t, err)
+	schema, err := ParseSchema(data)
+	require.NoError(t, err)
+
+	code, err := Generate(schema, "")
+	require.NoError(t, err)
+
+	want, err := os.ReadFile("internal/contacts/contacts.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), code, "run go generate ./arkiv/bindgen/...")
+}
+
+func TestSchemaErrors(t *testing.T) {
+	for _, tt := range []struct {
+		schema string
+		err    string
+	}{
+		{`package: p`, "no entities"},
+		{`{"entities": [{"name": "E", "contentType": "text/plain"}], "unknown": 1}`, "field unknown not found"},
+		{`entities: [{name: e, contentType: text/plain}]`, "not an exported Go identifier"},
+		{`entities: [{name: E}]`, "content type is empty"},
+		{`entities: [{name: E, contentType: text/plain, payload: [{name: a, type: string}]}]`, "need a JSON content type"},
+		{`entities: [{name: E, contentType: text/plain, annotations: [{name: $owner, type: string}]}]`, "invalid annotation name"},
+		{`entities: [{name: E, contentType: text/plain, annotations: [{name: a, type: bool}]}]`, "unknown type"},
+		{`entities: [{name: E, contentType: text/plain, annotations: [{name: a, type: numeric, value: x}]}]`, "invalid numeric value"},
+		{`entities: [{name: E, contentType: text/plain, annotations: [{name: a, type: string}, {name: a, type: numeric}]}]`, "defined twice"},
+		{`entities: [{name: E, contentType: text/plain, annotations: [{name: payload, type: string}]}]`, "reserved"},
+		{`entities: [{name: E, contentType: application/json, annotations: [{name: create, type: string}]}]`, "reserved"},
+		{`entities: [{name: E, contentType: application/json, annotations: [{name: a, type: string}], payload: [{name: a, type: string}]}]`, "defined twice"},
+		{`entities: [{name: E, contentType: application/json, payload: [{name: a, type: map}]}]`, "unknown type"},
+		{`entities: [{name: E, contentType: text/plain, annotations: [{name: 数, type: string}]}]`, "set the field name"},
+		{`entities: [{name: E, contentType: text/plain}, {name: E, contentType: text/plain}]`, "generated twice"},
+	} {
+		_, err := ParseSchema([]byte(tt.schema))
+		require.Error(t, err, tt.schema)
+		require.True(t, strings.Contains(err.Error(), tt.err), "%s: %v", tt.schema, err)
+	}
+}
+
+func TestGeneratePackage(t *testing.T) {
+	schema, err := ParseSchema([]byte(`entities: [{name: E, contentType: application/vnd.api+json, payload: [{name: a, type: "[]bytes"}]}]`))
+	require.NoError(t, err)
+
+	_, err = Generate(schema, "")
+	require.ErrorContains(t, err, "no valid package name")
+
+	code, err := Generate(schema, "other")
+	require.NoError(t, err)
+	require.Contains(t, code, "package other\n")
+	require.Contains(t, code, "A []hexutil.Bytes `json:\"a,omitempty\"`")
+	require.Contains(t, code, "return json.Marshal(e)")
+}
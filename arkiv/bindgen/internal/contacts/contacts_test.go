@@ -0,0 +1,155 @@
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/ethereum/go-ethereum/arkiv/bindings"
+	"github.com/ethereum/go-ethereum/arkiv/simulated"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	person := &Person{
+		Name:     "Ada \"the first\" Lovelace",
+		Age:      36,
+		Email:    "ada@example.com",
+		Bio:      "Wrote the first program",
+		Tags:     []string{"math", "poetry"},
+		Avatar:   []byte{0x01, 0x02},
+		Verified: true,
+		Extra:    json.RawMessage(`{"born":1815}`),
+	}
+	create, err := person.Create(100)
+	require.NoError(t, err)
+	require.Equal(t, PersonContentType, create.ContentType)
+	require.NoError(t, (&storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create}}).Validate())
+
+	decoded, err := DecodePerson(bindings.FromCreate(create))
+	require.NoError(t, err)
+	require.Equal(t, person, decoded)
+
+	update, err := person.Update(common.Hash{1}, 10)
+	require.NoError(t, err)
+	decoded, err = DecodePerson(bindings.FromUpdate(update))
+	require.NoError(t, err)
+	require.Equal(t, person, decoded)
+
+	note := &Note{Author: "ada", Priority: 2, Payload: "remember the milk"}
+	create, err = note.Create(100)
+	require.NoError(t, err)
+	decodedNote, err := DecodeNote(bindings.FromCreate(create))
+	require.NoError(t, err)
+	require.Equal(t, note, decodedNote)
+
+	attachment := &Attachment{Payload: []byte{0xff, 0x00}}
+	create, err = attachment.Create(100)
+	require.NoError(t, err)
+	decodedAttachment, err := DecodeAttachment(bindings.FromCreate(create))
+	require.NoError(t, err)
+	require.Equal(t, attachment, decodedAttachment)
+
+	// Entities of other types are rejected
+	_, err = DecodePerson(bindings.FromCreate(create))
+	require.ErrorContains(t, err, "not of type Person")
+
+	create, err = person.Create(100)
+	require.NoError(t, err)
+	create.StringAnnotations[0].Value = "robot"
+	_, err = DecodePerson(bindings.FromCreate(create))
+	require.ErrorContains(t, err, "not of type Person")
+
+	create, err = person.Create(100)
+	require.NoError(t, err)
+	create.NumericAnnotations = create.NumericAnnotations[:1]
+	_, err = DecodePerson(bindings.FromCreate(create))
+	require.ErrorContains(t, err, `missing numeric annotation "age"`)
+}
+
+func TestQueries(t *testing.T) {
+	for _, tt := range []struct {
+		query bindings.Query
+		want  string
+	}{
+		{QueryPerson(), `type = "person" && version = 1`},
+		{
+			QueryPerson(PersonQuery.Age.Ge(18), PersonQuery.Name.Glob("A*")),
+			`type = "person" && version = 1 && age >= 18 && name ~ "A*"`,
+		},
+		{
+			QueryPerson(bindings.Or(PersonQuery.Email.Eq(`a"b\c`), PersonQuery.Email.In("x", "y"))),
+			`type = "person" && version = 1 && (e_mail = "a\"b\\c" || e_mail IN ("x" "y"))`,
+		},
+		{QueryNote(), `$all`},
+		{QueryNote(bindings.Not(NoteQuery.Priority.In(1, 2))), `!(priority IN (1 2))`},
+		{QueryAttachment(), `kind = 7`},
+	} {
+		require.Equal(t, tt.want, tt.query.String())
+		_, err := query.Parse(tt.query.String())
+		require.NoError(t, err, tt.want)
+	}
+}
+
+func TestSimulated(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim, err := simulated.NewBackend(types.GenesisAlloc{
+		owner: {Balance: big.NewInt(params.Ether)},
+	})
+	require.NoError(t, err)
+	defer sim.Close()
+
+	ctx := context.Background()
+
+	people := []*Person{
+		{Name: "Ada", Age: 36, Email: "ada@example.com", Tags: []string{"math"}},
+		{Name: "Alan", Age: 41, Email: "alan@example.com"},
+		{Name: `Bobby "Tables"`, Age: 12, Email: "bobby@example.com"},
+	}
+	atx := &storagetx.ArkivTransaction{}
+	for _, p := range people {
+		create, err := p.Create(100)
+		require.NoError(t, err)
+		atx.Create = append(atx.Create, create)
+	}
+	note, err := (&Note{Author: "Ada", Priority: 1, Payload: "hi"}).Create(100)
+	require.NoError(t, err)
+	atx.Create = append(atx.Create, note)
+
+	_, err = sim.SendArkivTransaction(ctx, key, atx)
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+
+	run := func(q bindings.Query) []*Person {
+		var res struct {
+			Data []*bindings.Entity `json:"data"`
+		}
+		err := sim.RPC().CallContext(ctx, &res, "arkiv_query", q.String(), map[string]any{"includeData": bindings.IncludeData})
+		require.NoError(t, err)
+		var found []*Person
+		for _, ent := range res.Data {
+			require.Equal(t, owner, ent.Owner)
+			p, err := DecodePerson(ent)
+			require.NoError(t, err)
+			found = append(found, p)
+		}
+		return found
+	}
+
+	require.ElementsMatch(t, people, run(QueryPerson()))
+	require.ElementsMatch(t, people[:2], run(QueryPerson(PersonQuery.Age.Ge(18))))
+	require.ElementsMatch(t, people[2:], run(QueryPerson(PersonQuery.Name.Eq(`Bobby "Tables"`))))
+	require.ElementsMatch(t, people[:1], run(QueryPerson(bindings.Owner(owner), PersonQuery.Name.Eq("Ada"))))
+	require.Empty(t, run(QueryPerson(PersonQuery.Email.In())))
+}
@@ -0,0 +1,5 @@
+// Package contacts holds the bindings generated from testdata/contacts.yaml, to
+// test the generated code.
+package contacts
+
+//go:generate go run ../../../../cmd/arkivgen --schema ../../testdata/contacts.yaml --out contacts.go
@@ -0,0 +1,258 @@
+package bindgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes the entities to generate bindings for. It is read from YAML,
+// or from JSON, which is valid YAML.
+//
+//	package: contacts
+//	entities:
+//	  - name: Person
+//	    contentType: application/json
+//	    annotations:
+//	      - {name: type, type: string, value: person}
+//	      - {name: name, type: string}
+//	      - {name: age, type: numeric}
+//	    payload:
+//	      - {name: bio, type: string}
+//	      - {name: tags, type: "[]string"}
+type Schema struct {
+	Package  string   `yaml:"package"`
+	Entities []Entity `yaml:"entities"`
+}
+
+// Entity is an entity type. Its payload is encoded as a JSON object of the
+// payload fields if its content type is JSON, as a string if it is a text
+// content type and as raw bytes otherwise.
+type Entity struct {
+	Name        string       `yaml:"name"`
+	ContentType string       `yaml:"contentType"`
+	Annotations []Annotation `yaml:"annotations"`
+	Payload     []Field      `yaml:"payload"`
+}
+
+// Annotation is an annotation of the entities. Annotations with a value have
+// that value for all the entities of the type, and are used to tell them apart
+// from other entities.
+type Annotation struct {
+	Name  string  `yaml:"name"`
+	Type  string  `yaml:"type"`  // string or numeric
+	Field string  `yaml:"field"` // Go field name, derived from the name if empty
+	Value *string `yaml:"value"`
+}
+
+// Field is a field of the JSON payload of the entities.
+type Field struct {
+	Name  string `yaml:"name"`
+	Type  string `yaml:"type"`
+	Field string `yaml:"field"` // Go field name, derived from the name if empty
+}
+
+// The Go types of the payload fields.
+var payloadTypes = map[string]string{
+	"string":  "string",
+	"bool":    "bool",
+	"int64":   "int64",
+	"uint64":  "uint64",
+	"float64": "float64",
+	"bytes":   "hexutil.Bytes",
+	"json":    "json.RawMessage",
+}
+
+// The names of the methods of the generated structs, which fields cannot have.
+var methodNames = []string{"Annotations", "EncodePayload", "Create", "Update"}
+
+// ParseSchema parses and validates a schema.
+func ParseSchema(data []byte) (*Schema, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	schema := new(Schema)
+	if err := dec.Decode(schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (s *Schema) validate() error {
+	if len(s.Entities) == 0 {
+		return errors.New("schema has no entities")
+	}
+	names := make(map[string]bool)
+	for _, e := range s.Entities {
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("entity %s: %w", e.Name, err)
+		}
+		// All the generated top level names must be unique
+		for _, name := range e.names() {
+			if names[name] {
+				return fmt.Errorf("entity %s: name %s is generated twice", e.Name, name)
+			}
+			names[name] = true
+		}
+	}
+	return nil
+}
+
+func (e *Entity) validate() error {
+	if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+		return errors.New("name is not an exported Go identifier")
+	}
+	if e.ContentType == "" {
+		return errors.New("content type is empty")
+	}
+	if len(e.ContentType) > 128 {
+		return errors.New("content type is too long")
+	}
+	if len(e.Payload) > 0 && e.payloadKind() != jsonPayload {
+		return fmt.Errorf("payload fields need a JSON content type, not %s", e.ContentType)
+	}
+
+	fields := make(map[string]bool)
+	for _, name := range methodNames {
+		fields[name] = true
+	}
+	if e.payloadKind() != jsonPayload {
+		fields["Payload"] = true
+	}
+	addField := func(name, override string) error {
+		field, err := fieldName(name, override)
+		if err != nil {
+			return err
+		}
+		if fields[field] {
+			return fmt.Errorf("field %s is defined twice or is reserved", field)
+		}
+		fields[field] = true
+		return nil
+	}
+
+	keys := make(map[string]bool)
+	for _, a := range e.Annotations {
+		if !entity.AnnotationIdentRegexCompiled.MatchString(a.Name) {
+			return fmt.Errorf("invalid annotation name %q (must match `%s`)", a.Name, entity.AnnotationIdentRegexCompiled)
+		}
+		if keys[a.Name] {
+			return fmt.Errorf("annotation %s is defined twice", a.Name)
+		}
+		keys[a.Name] = true
+
+		switch a.Type {
+		case "string":
+		case "numeric":
+			if a.Value != nil {
+				if _, err := strconv.ParseUint(*a.Value, 10, 64); err != nil {
+					return fmt.Errorf("annotation %s: invalid numeric value %q", a.Name, *a.Value)
+				}
+			}
+		default:
+			return fmt.Errorf("annotation %s: unknown type %q, must be string or numeric", a.Name, a.Type)
+		}
+		if err := addField(a.Name, a.Field); err != nil {
+			return fmt.Errorf("annotation %s: %w", a.Name, err)
+		}
+	}
+
+	jsonNames := make(map[string]bool)
+	for _, f := range e.Payload {
+		if f.Name == "" || f.Name == "-" || strings.ContainsAny(f.Name, "\",`") {
+			return fmt.Errorf("invalid payload field name %q", f.Name)
+		}
+		if jsonNames[f.Name] {
+			return fmt.Errorf("payload field %s is defined twice", f.Name)
+		}
+		jsonNames[f.Name] = true
+
+		if _, err := goType(f.Type); err != nil {
+			return fmt.Errorf("payload field %s: %w", f.Name, err)
+		}
+		if err := addField(f.Name, f.Field); err != nil {
+			return fmt.Errorf("payload field %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// names returns the top level names generated for the entity.
+func (e *Entity) names() []string {
+	names := []string{e.Name, e.Name + "ContentType", e.Name + "Query", "Query" + e.Name, "Decode" + e.Name}
+	for _, a := range e.Annotations {
+		field, _ := fieldName(a.Name, a.Field)
+		names = append(names, e.Name+field+"Key")
+		if a.Value != nil {
+			names = append(names, e.Name+field+"Value")
+		}
+	}
+	return names
+}
+
+type payloadKind int
+
+const (
+	bytesPayload payloadKind = iota
+	textPayload
+	jsonPayload
+)
+
+func (e *Entity) payloadKind() payloadKind {
+	mediaType, _, _ := strings.Cut(e.ContentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return jsonPayload
+	case strings.HasPrefix(mediaType, "text/"):
+		return textPayload
+	default:
+		return bytesPayload
+	}
+}
+
+// fieldName returns the Go field name of an annotation or payload field: the
+// override if set, and otherwise the name in camel case.
+func fieldName(name, override string) (string, error) {
+	field := override
+	if field == "" {
+		var b strings.Builder
+		for _, part := range strings.Split(name, "_") {
+			r, size := utf8.DecodeRuneInString(part)
+			if size > 0 {
+				b.WriteRune(unicode.ToUpper(r))
+				b.WriteString(part[size:])
+			}
+		}
+		field = b.String()
+	}
+	if !token.IsIdentifier(field) || !token.IsExported(field) {
+		return "", fmt.Errorf("%q is not an exported Go identifier, set the field name", field)
+	}
+	return field, nil
+}
+
+// goType returns the Go type of a payload field type, one of the payloadTypes
+// or a list of them written as []type.
+func goType(typ string) (string, error) {
+	elem, list := strings.CutPrefix(typ, "[]")
+	t, ok := payloadTypes[elem]
+	if !ok {
+		return "", fmt.Errorf("unknown type %q", typ)
+	}
+	if list {
+		return "[]" + t, nil
+	}
+	return t, nil
+}
@@ -0,0 +1,118 @@
+package bindings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+)
+
+// Entity is an entity as decoded by the generated bindings. It can be built out
+// of a create or update operation, or decoded from the JSON of the results of
+// arkiv_query, which need to include the attributes, the content type and the
+// payload.
+type Entity struct {
+	Key                common.Hash                   `json:"key"`
+	Owner              common.Address                `json:"owner"`
+	ExpiresAt          uint64                        `json:"expiresAt"`
+	ContentType        string                        `json:"contentType"`
+	Payload            hexutil.Bytes                 `json:"value"`
+	StringAnnotations  []storagetx.StringAnnotation  `json:"stringAttributes"`
+	NumericAnnotations []storagetx.NumericAnnotation `json:"numericAttributes"`
+}
+
+// IncludeData are the arkiv_query options needed to decode the results as
+// entities.
+var IncludeData = map[string]bool{
+	"key":         true,
+	"attributes":  true,
+	"payload":     true,
+	"contentType": true,
+	"expiration":  true,
+	"owner":       true,
+}
+
+// FromCreate returns the entity created by an operation.
+func FromCreate(op storagetx.ArkivCreate) *Entity {
+	return &Entity{
+		ContentType:        op.ContentType,
+		Payload:            op.Payload,
+		StringAnnotations:  op.StringAnnotations,
+		NumericAnnotations: op.NumericAnnotations,
+	}
+}
+
+// FromUpdate returns the entity as set by an update operation.
+func FromUpdate(op storagetx.ArkivUpdate) *Entity {
+	return &Entity{
+		Key:                op.EntityKey,
+		ContentType:        op.ContentType,
+		Payload:            op.Payload,
+		StringAnnotations:  op.StringAnnotations,
+		NumericAnnotations: op.NumericAnnotations,
+	}
+}
+
+// StringAnnotation returns the value of a string annotation. The annotations
+// starting with $ are set by the store and are not part of the entity.
+func (e *Entity) StringAnnotation(key string) (string, error) {
+	for _, a := range e.StringAnnotations {
+		if a.Key == key {
+			return a.Value, nil
+		}
+	}
+	return "", fmt.Errorf("missing string annotation %q", key)
+}
+
+// NumericAnnotation returns the value of a numeric annotation.
+func (e *Entity) NumericAnnotation(key string) (uint64, error) {
+	for _, a := range e.NumericAnnotations {
+		if a.Key == key {
+			return a.Value, nil
+		}
+	}
+	return 0, fmt.Errorf("missing numeric annotation %q", key)
+}
+
+// CheckStringAnnotation returns an error if a string annotation is missing or
+// does not have the expected value.
+func (e *Entity) CheckStringAnnotation(key, value string) error {
+	v, err := e.StringAnnotation(key)
+	if err != nil {
+		return err
+	}
+	if v != value {
+		return fmt.Errorf("string annotation %q is %q, expected %q", key, v, value)
+	}
+	return nil
+}
+
+// CheckNumericAnnotation returns an error if a numeric annotation is missing or
+// does not have the expected value.
+func (e *Entity) CheckNumericAnnotation(key string, value uint64) error {
+	v, err := e.NumericAnnotation(key)
+	if err != nil {
+		return err
+	}
+	if v != value {
+		return fmt.Errorf("numeric annotation %q is %d, expected %d", key, v, value)
+	}
+	return nil
+}
+
+// CheckContentType returns an error if the content type of the entity is not
+// the expected one. Parameters of the content types, like the charset, are
+// ignored.
+func (e *Entity) CheckContentType(contentType string) error {
+	if !strings.EqualFold(mediaType(e.ContentType), mediaType(contentType)) {
+		return fmt.Errorf("content type is %q, expected %q", e.ContentType, contentType)
+	}
+	return nil
+}
+
+func mediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(mediaType)
+}
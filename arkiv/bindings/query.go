@@ -0,0 +1,284 @@
+// Package bindings is the runtime support of the entity bindings generated by
+// arkivgen. It builds arkiv_query expressions out of typed conditions, quoting
+// the values as the query language expects, and decodes the entities of query
+// results and operations into a common form.
+package bindings
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+)
+
+// Query is an arkiv_query expression. The zero value matches all entities.
+type Query struct {
+	expr string // empty for all entities
+	or   bool   // whether expr is a disjunction, to be parenthesized in conjunctions
+}
+
+// none matches no entity, as there is no entity with the zero key. The query
+// language cannot express it otherwise.
+var none = Query{expr: "$key = " + common.Hash{}.Hex()}
+
+// All returns the query matching all entities.
+func All() Query {
+	return Query{}
+}
+
+// String returns the expression of the query, as passed to arkiv_query.
+func (q Query) String() string {
+	if q.expr == "" {
+		return "$all"
+	}
+	return q.expr
+}
+
+// And returns the query matching the entities that match all the given queries.
+func And(queries ...Query) Query {
+	var terms []string
+	for _, q := range queries {
+		switch {
+		case q.expr == "":
+			continue
+		case q.or:
+			terms = append(terms, "("+q.expr+")")
+		default:
+			terms = append(terms, q.expr)
+		}
+	}
+	return Query{expr: strings.Join(terms, " && ")}
+}
+
+// Or returns the query matching the entities that match any of the given
+// queries. It matches no entity if no query is given.
+func Or(queries ...Query) Query {
+	if len(queries) == 0 {
+		return none
+	}
+	terms := make([]string, 0, len(queries))
+	for _, q := range queries {
+		if q.expr == "" {
+			return All()
+		}
+		terms = append(terms, q.expr)
+	}
+	return Query{expr: strings.Join(terms, " || "), or: len(terms) > 1}
+}
+
+// Not returns the query matching the entities that do not match the given one.
+func Not(q Query) Query {
+	if q.expr == "" {
+		return none
+	}
+	return Query{expr: "!(" + q.expr + ")"}
+}
+
+// Quote quotes a string value of a query.
+func Quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func condition(name, op, value string) Query {
+	return Query{expr: name + " " + op + " " + value}
+}
+
+func inclusion(name string, not bool, values []string) Query {
+	if len(values) == 0 {
+		if not {
+			return All()
+		}
+		return none
+	}
+	op := "IN"
+	if not {
+		op = "NOT IN"
+	}
+	return Query{expr: name + " " + op + " (" + strings.Join(values, " ") + ")"}
+}
+
+// StringField builds the conditions on a string annotation.
+type StringField string
+
+func (f StringField) Eq(v string) Query  { return condition(string(f), "=", Quote(v)) }
+func (f StringField) Neq(v string) Query { return condition(string(f), "!=", Quote(v)) }
+func (f StringField) Lt(v string) Query  { return condition(string(f), "<", Quote(v)) }
+func (f StringField) Le(v string) Query  { return condition(string(f), "<=", Quote(v)) }
+func (f StringField) Gt(v string) Query  { return condition(string(f), ">", Quote(v)) }
+func (f StringField) Ge(v string) Query  { return condition(string(f), ">=", Quote(v)) }
+
+// Glob matches the values against a glob pattern, with * and ? wildcards.
+func (f StringField) Glob(pattern string) Query { return condition(string(f), "~", Quote(pattern)) }
+
+// NotGlob matches the values that do not match a glob pattern.
+func (f StringField) NotGlob(pattern string) Query {
+	return condition(string(f), "!~", Quote(pattern))
+}
+
+// In matches any of the values. It matches no entity if no value is given.
+func (f StringField) In(vs ...string) Query { return inclusion(string(f), false, quoteAll(vs)) }
+
+// NotIn matches none of the values.
+func (f StringField) NotIn(vs ...string) Query { return inclusion(string(f), true, quoteAll(vs)) }
+
+func quoteAll(vs []string) []string {
+	quoted := make([]string, len(vs))
+	for i, v := range vs {
+		quoted[i] = Quote(v)
+	}
+	return quoted
+}
+
+// NumericField builds the conditions on a numeric annotation.
+type NumericField string
+
+func (f NumericField) Eq(v uint64) Query  { return condition(string(f), "=", number(v)) }
+func (f NumericField) Neq(v uint64) Query { return condition(string(f), "!=", number(v)) }
+func (f NumericField) Lt(v uint64) Query  { return condition(string(f), "<", number(v)) }
+func (f NumericField) Le(v uint64) Query  { return condition(string(f), "<=", number(v)) }
+func (f NumericField) Gt(v uint64) Query  { return condition(string(f), ">", number(v)) }
+func (f NumericField) Ge(v uint64) Query  { return condition(string(f), ">=", number(v)) }
+
+// In matches any of the values. It matches no entity if no value is given.
+func (f NumericField) In(vs ...uint64) Query { return inclusion(string(f), false, numbers(vs)) }
+
+// NotIn matches none of the values.
+func (f NumericField) NotIn(vs ...uint64) Query { return inclusion(string(f), true, numbers(vs)) }
+
+func number(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
+
+func numbers(vs []uint64) []string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = number(v)
+	}
+	return s
+}
+
+// IntField builds the conditions on a signed integer annotation.
+type IntField string
+
+func (f IntField) Eq(v int64) Query  { return condition(string(f), "=", intNumber(v)) }
+func (f IntField) Neq(v int64) Query { return condition(string(f), "!=", intNumber(v)) }
+func (f IntField) Lt(v int64) Query  { return condition(string(f), "<", intNumber(v)) }
+func (f IntField) Le(v int64) Query  { return condition(string(f), "<=", intNumber(v)) }
+func (f IntField) Gt(v int64) Query  { return condition(string(f), ">", intNumber(v)) }
+func (f IntField) Ge(v int64) Query  { return condition(string(f), ">=", intNumber(v)) }
+
+// In matches any of the values. It matches no entity if no value is given.
+func (f IntField) In(vs ...int64) Query { return inclusion(string(f), false, intNumbers(vs)) }
+
+// NotIn matches none of the values.
+func (f IntField) NotIn(vs ...int64) Query { return inclusion(string(f), true, intNumbers(vs)) }
+
+func intNumber(v int64) string {
+	return Quote(storagetx.IntAttribute(v))
+}
+
+func intNumbers(vs []int64) []string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = intNumber(v)
+	}
+	return s
+}
+
+// DecimalField builds the conditions on a decimal annotation. The methods panic
+// if a value is not a valid decimal annotation value.
+type DecimalField string
+
+func (f DecimalField) Eq(v string) Query  { return condition(string(f), "=", decimal(v)) }
+func (f DecimalField) Neq(v string) Query { return condition(string(f), "!=", decimal(v)) }
+func (f DecimalField) Lt(v string) Query  { return condition(string(f), "<", decimal(v)) }
+func (f DecimalField) Le(v string) Query  { return condition(string(f), "<=", decimal(v)) }
+func (f DecimalField) Gt(v string) Query  { return condition(string(f), ">", decimal(v)) }
+func (f DecimalField) Ge(v string) Query  { return condition(string(f), ">=", decimal(v)) }
+
+func decimal(v string) string {
+	attribute, err := storagetx.DecimalAttribute(v)
+	if err != nil {
+		panic(err)
+	}
+	return Quote(attribute)
+}
+
+// BoolField builds the conditions on a bool annotation.
+type BoolField string
+
+func (f BoolField) Eq(v bool) Query {
+	return condition(string(f), "=", number(storagetx.BoolAttribute(v)))
+}
+
+// AddressField builds the conditions on an address annotation.
+type AddressField string
+
+func (f AddressField) Eq(v common.Address) Query {
+	return condition(string(f), "=", Quote(storagetx.AddressAttribute(v)))
+}
+func (f AddressField) Neq(v common.Address) Query {
+	return condition(string(f), "!=", Quote(storagetx.AddressAttribute(v)))
+}
+
+// In matches any of the values. It matches no entity if no value is given.
+func (f AddressField) In(vs ...common.Address) Query {
+	quoted := make([]string, len(vs))
+	for i, v := range vs {
+		quoted[i] = Quote(storagetx.AddressAttribute(v))
+	}
+	return inclusion(string(f), false, quoted)
+}
+
+// Bytes32Field builds the conditions on a bytes32 annotation.
+type Bytes32Field string
+
+func (f Bytes32Field) Eq(v common.Hash) Query {
+	return condition(string(f), "=", Quote(storagetx.Bytes32Attribute(v)))
+}
+func (f Bytes32Field) Neq(v common.Hash) Query {
+	return condition(string(f), "!=", Quote(storagetx.Bytes32Attribute(v)))
+}
+
+// In matches any of the values. It matches no entity if no value is given.
+func (f Bytes32Field) In(vs ...common.Hash) Query {
+	quoted := make([]string, len(vs))
+	for i, v := range vs {
+		quoted[i] = Quote(storagetx.Bytes32Attribute(v))
+	}
+	return inclusion(string(f), false, quoted)
+}
+
+// Key matches the entities with any of the given keys.
+func Key(keys ...common.Hash) Query {
+	if len(keys) == 1 {
+		return condition("$key", "=", keys[0].Hex())
+	}
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = key.Hex()
+	}
+	return inclusion("$key", false, values)
+}
+
+// Owner matches the entities owned by any of the given addresses.
+func Owner(owners ...common.Address) Query {
+	return addressCondition("$owner", owners)
+}
+
+// Creator matches the entities created by any of the given addresses.
+func Creator(creators ...common.Address) Query {
+	return addressCondition("$creator", creators)
+}
+
+func addressCondition(name string, addrs []common.Address) Query {
+	if len(addrs) == 1 {
+		return condition(name, "=", addrs[0].Hex())
+	}
+	values := make([]string, len(addrs))
+	for i, addr := range addrs {
+		values[i] = addr.Hex()
+	}
+	return inclusion(name, false, values)
+}
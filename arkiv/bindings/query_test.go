@@ -0,0 +1,81 @@
+package bindings
+
+import (
+	"testing"
+
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	var (
+		name  = StringField("name")
+		age   = NumericField("age")
+		temp  = IntField("temp")
+		price = DecimalField("price")
+		done  = BoolField("done")
+		peer  = AddressField("peer")
+		hash  = Bytes32Field("hash")
+		addr  = common.HexToAddress("0x00000000000000000000000000000000000000Aa")
+		key   = common.HexToHash("0x01")
+	)
+	for _, tt := range []struct {
+		query Query
+		want  string
+	}{
+		{All(), `$all`},
+		{Query{}, `$all`},
+		{And(), `$all`},
+		{And(All(), name.Eq("a")), `name = "a"`},
+		{Or(All(), name.Eq("a")), `$all`},
+		{Or(), `$key = 0x0000000000000000000000000000000000000000000000000000000000000000`},
+		{Not(All()), `$key = 0x0000000000000000000000000000000000000000000000000000000000000000`},
+		{name.Eq(`quote " backslash \ newline` + "\n"), `name = "quote \" backslash \\ newline\n"`},
+		{name.Neq("ü"), `name != "ü"`},
+		{name.Glob("a*"), `name ~ "a*"`},
+		{name.NotGlob("a?"), `name !~ "a?"`},
+		{name.Lt("b"), `name < "b"`},
+		{name.In("a", "b"), `name IN ("a" "b")`},
+		{name.NotIn("a"), `name NOT IN ("a")`},
+		{name.NotIn(), `$all`},
+		{age.Le(1), `age <= 1`},
+		{age.Ge(18), `age >= 18`},
+		{age.In(), `$key = 0x0000000000000000000000000000000000000000000000000000000000000000`},
+		{age.NotIn(1, 2), `age NOT IN (1 2)`},
+		{
+			And(Or(name.Eq("a"), name.Eq("b")), age.Gt(1)),
+			`(name = "a" || name = "b") && age > 1`,
+		},
+		{
+			Or(And(name.Eq("a"), age.Lt(1)), Not(Or(age.Eq(2), age.Eq(3)))),
+			`name = "a" && age < 1 || !(age = 2 || age = 3)`,
+		},
+		{temp.Lt(-1), `temp < "0x7fffffffffffffff"`},
+		{temp.Ge(0), `temp >= "0x8000000000000000"`},
+		{temp.In(-2, 2), `temp IN ("0x7ffffffffffffffe" "0x8000000000000002")`},
+		{price.Gt("-0.5"), `price > "0x7ffffffffffffffffffffffffffffffffffffffffffffffff90fa4a62c4e0000"`},
+		{price.Eq("1.50"), `price = "0x80000000000000000000000000000000000000000000000014d1120d7b160000"`},
+		{done.Eq(true), `done = 1`},
+		{peer.Eq(addr), `peer = "0x00000000000000000000000000000000000000aa"`},
+		{hash.In(key), `hash IN ("0x0000000000000000000000000000000000000000000000000000000000000001")`},
+		{Key(key), `$key = 0x0000000000000000000000000000000000000000000000000000000000000001`},
+		{Owner(addr), `$owner = 0x00000000000000000000000000000000000000AA`},
+		{Creator(addr, addr), `$creator IN (0x00000000000000000000000000000000000000AA 0x00000000000000000000000000000000000000AA)`},
+	} {
+		require.Equal(t, tt.want, tt.query.String())
+		_, err := query.Parse(tt.query.String())
+		require.NoError(t, err, tt.want)
+	}
+}
+
+// TestQuote checks that quoted values are parsed back as they were.
+func TestQuote(t *testing.T) {
+	for _, v := range []string{"", "plain", `"`, `\`, `\"`, "tab\tnew\nline", "ünïcödé ☃", "\x00\x7f"} {
+		ast, err := query.Parse(StringField("a").Eq(v).String())
+		require.NoError(t, err, v)
+		got := ast.Expr.Or.Terms[0].Terms[0].Assign.Value.String
+		require.NotNil(t, got)
+		require.Equal(t, v, *got)
+	}
+}
@@ -9,17 +9,42 @@ import (
 	"github.com/ethereum/go-ethereum/golem-base/address"
 	"github.com/ethereum/go-ethereum/golem-base/logs"
 	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/holiman/uint256"
 )
 
 func blockToEvents(rawBlock *types.Block, rawReceipts []*types.Receipt) (*events.Block, error) {
 
+	operations, err := DecodeBlock(rawBlock, rawReceipts)
+	if err != nil {
+		return nil, err
+	}
+
 	bl := &events.Block{
 		Number:     rawBlock.NumberU64(),
-		Operations: []events.Operation{},
+		Operations: make([]events.Operation, 0, len(operations)),
 	}
+	for _, op := range operations {
+		bl.Operations = append(bl.Operations, op.Operation)
+	}
+
+	return bl, nil
+}
+
+// SentOperation is an Arkiv operation together with the account that issued it.
+// Expirations are issued by the housekeeping transaction and have no sender.
+type SentOperation struct {
+	events.Operation
+	Sender common.Address
+}
+
+// DecodeBlock decodes the Arkiv operations of a block, in the order of the event
+// stream, from its transactions and receipts.
+func DecodeBlock(rawBlock *types.Block, rawReceipts []*types.Receipt) ([]SentOperation, error) {
+
+	operations := []SentOperation{}
 
 	if len(rawReceipts) == 0 {
-		return bl, nil
+		return operations, nil
 	}
 
 	firstReceipt := rawReceipts[0]
@@ -31,27 +56,46 @@ func blockToEvents(rawBlock *types.Block, rawReceipts []*types.Receipt) (*events
 		if log.Topics[0] == logs.ArkivEntityExpired && len(log.Data) >= 32 {
 			entityKey := common.BytesToHash(log.Data[:32])
 			expire := events.OPExpire(entityKey.Bytes())
-			bl.Operations = append(bl.Operations, events.Operation{
-				TxIndex: 0,
-				OpIndex: uint64(opIndex),
-				Expire:  &expire,
+			operations = append(operations, SentOperation{
+				Operation: events.Operation{
+					TxIndex: 0,
+					OpIndex: uint64(opIndex),
+					Expire:  &expire,
+				},
+			})
+		}
+		// A renewed entity was extended by the housekeeping instead of expiring
+		if log.Topics[0] == logs.ArkivEntityRenewed && len(log.Topics) > 1 && len(log.Data) >= 64 {
+			oldExpiresAt := new(uint256.Int).SetBytes(log.Data[:32]).Uint64()
+			newExpiresAt := new(uint256.Int).SetBytes(log.Data[32:64]).Uint64()
+			operations = append(operations, SentOperation{
+				Operation: events.Operation{
+					TxIndex: 0,
+					OpIndex: uint64(opIndex),
+					ExtendBTL: &events.OPExtendBTL{
+						Key: log.Topics[1],
+						BTL: newExpiresAt - oldExpiresAt,
+					},
+				},
 			})
 		}
 	}
 
 	for i, transaction := range rawBlock.Transactions() {
-		transactionTo := transaction.To()
-		if transactionTo == nil {
-			continue
-		}
+		receipt := rawReceipts[i]
 
-		if *transactionTo != address.ArkivProcessorAddress {
+		if receipt.Status != types.ReceiptStatusSuccessful {
 			continue
 		}
 
-		receipt := rawReceipts[i]
-
-		if receipt.Status != types.ReceiptStatusSuccessful {
+		transactionTo := transaction.To()
+		if transactionTo == nil || *transactionTo != address.ArkivProcessorAddress {
+			// transactions to contracts can still issue arkiv transactions through a CALL
+			ops, err := contractCallsToOperations(uint64(i), receipt)
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, ops...)
 			continue
 		}
 
@@ -66,106 +110,141 @@ func blockToEvents(rawBlock *types.Block, rawReceipts []*types.Receipt) (*events
 			return nil, fmt.Errorf("failed to get sender from transaction: %w", err)
 		}
 
-		createdEntities := createdEntities(receipt)
-
-		for opIndex, create := range atx.Create {
-			createdEntityKey := createdEntities[0]
-			createdEntities = createdEntities[1:]
-
-			bl.Operations = append(bl.Operations, events.Operation{
-				TxIndex: uint64(i),
-				OpIndex: uint64(opIndex),
-				Create: &events.OPCreate{
-					Key:               createdEntityKey,
-					ContentType:       create.ContentType,
-					BTL:               create.BTL,
-					Owner:             from,
-					Content:           create.Payload,
-					StringAttributes:  stringAnnotationsToMap(create.StringAnnotations),
-					NumericAttributes: numericAnnotationsToMap(create.NumericAnnotations),
-				},
-			})
-		}
-
-		for opIndex, update := range atx.Update {
-
-			bl.Operations = append(bl.Operations, events.Operation{
-				TxIndex: uint64(i),
-				OpIndex: uint64(opIndex),
-				Update: &events.OPUpdate{
-					Key:               update.EntityKey,
-					ContentType:       update.ContentType,
-					BTL:               update.BTL,
-					Owner:             from,
-					Content:           update.Payload,
-					StringAttributes:  stringAnnotationsToMap(update.StringAnnotations),
-					NumericAttributes: numericAnnotationsToMap(update.NumericAnnotations),
-				},
-			})
-		}
-
-		for opIndex, extendBTL := range atx.Extend {
+		operations = append(operations, sentBy(from, transactionToOperations(uint64(i), atx, from, createdEntities(receipt.Logs)))...)
+	}
 
-			bl.Operations = append(bl.Operations, events.Operation{
-				TxIndex: uint64(i),
-				OpIndex: uint64(opIndex),
-				ExtendBTL: &events.OPExtendBTL{
-					Key: extendBTL.EntityKey,
-					BTL: extendBTL.NumberOfBlocks,
-				},
-			})
+	return operations, nil
+}
 
+// contractCallsToOperations decodes the arkiv transactions issued by contracts.
+// Each of them is announced by an ArkivContractCall log carrying the call data,
+// followed by the logs of the executed operations.
+//
+// The operation indices of a call continue after those of the previous calls of
+// the transaction, so that the operations of the calls don't collide, in the
+// $sequence of the created entities in particular.
+func contractCallsToOperations(txIndex uint64, receipt *types.Receipt) ([]SentOperation, error) {
+	operations := []SentOperation{}
+	opOffset := uint64(0)
+
+	for logIndex, log := range receipt.Logs {
+		// only the processor emits these logs, anything else is forged by a contract
+		if log.Address != address.ArkivProcessorAddress || len(log.Topics) < 2 || log.Topics[0] != logs.ArkivContractCall {
+			continue
 		}
-		for opIndex, changeOwner := range atx.ChangeOwner {
-
-			bl.Operations = append(bl.Operations, events.Operation{
-				TxIndex: uint64(i),
-				OpIndex: uint64(opIndex),
-				ChangeOwner: &events.OPChangeOwner{
-					Key:   changeOwner.EntityKey,
-					Owner: changeOwner.NewOwner,
-				},
-			})
 
+		atx, err := storagetx.UnpackArkivTransaction(log.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack arkiv contract call: %w", err)
 		}
-		for opIndex, delete := range atx.Delete {
-			event := events.OPDelete(delete)
 
-			bl.Operations = append(bl.Operations, events.Operation{
-				TxIndex: uint64(i),
-				OpIndex: uint64(opIndex),
-				Delete:  &event,
-			})
+		from := common.BytesToAddress(log.Topics[1].Bytes())
+
+		callOperations := transactionToOperations(txIndex, atx, from, createdEntities(receipt.Logs[logIndex+1:]))
+		for i := range callOperations {
+			callOperations[i].OpIndex += opOffset
 		}
+		opOffset += uint64(atx.OperationCount())
 
+		operations = append(operations, sentBy(from, callOperations)...)
 	}
 
-	return bl, nil
+	return operations, nil
 }
 
-func createdEntities(r *types.Receipt) []common.Hash {
-	entities := []common.Hash{}
-	for _, log := range r.Logs {
-		if log.Topics[0] == logs.ArkivEntityCreated {
-			entityKey := log.Topics[1]
-			entities = append(entities, entityKey)
-		}
+func transactionToOperations(txIndex uint64, atx *storagetx.ArkivTransaction, from common.Address, createdEntities []common.Hash) []events.Operation {
+	operations := []events.Operation{}
+
+	for opIndex, create := range atx.Create {
+		createdEntityKey := createdEntities[0]
+		createdEntities = createdEntities[1:]
+		stringAttributes, numericAttributes := create.Annotations().Attributes()
+
+		operations = append(operations, events.Operation{
+			TxIndex: txIndex,
+			OpIndex: uint64(opIndex),
+			Create: &events.OPCreate{
+				Key:               createdEntityKey,
+				ContentType:       create.ContentType,
+				BTL:               create.BTL,
+				Owner:             from,
+				Content:           create.Payload,
+				StringAttributes:  stringAttributes,
+				NumericAttributes: numericAttributes,
+			},
+		})
 	}
-	return entities
+
+	for opIndex, update := range atx.Update {
+		stringAttributes, numericAttributes := update.Annotations().Attributes()
+
+		operations = append(operations, events.Operation{
+			TxIndex: txIndex,
+			OpIndex: uint64(opIndex),
+			Update: &events.OPUpdate{
+				Key:               update.EntityKey,
+				ContentType:       update.ContentType,
+				BTL:               update.BTL,
+				Owner:             from,
+				Content:           update.Payload,
+				StringAttributes:  stringAttributes,
+				NumericAttributes: numericAttributes,
+			},
+		})
+	}
+
+	for opIndex, extendBTL := range atx.Extend {
+
+		operations = append(operations, events.Operation{
+			TxIndex: txIndex,
+			OpIndex: uint64(opIndex),
+			ExtendBTL: &events.OPExtendBTL{
+				Key: extendBTL.EntityKey,
+				BTL: extendBTL.NumberOfBlocks,
+			},
+		})
+
+	}
+	for opIndex, changeOwner := range atx.ChangeOwner {
+
+		operations = append(operations, events.Operation{
+			TxIndex: txIndex,
+			OpIndex: uint64(opIndex),
+			ChangeOwner: &events.OPChangeOwner{
+				Key:   changeOwner.EntityKey,
+				Owner: changeOwner.NewOwner,
+			},
+		})
+
+	}
+	for opIndex, delete := range atx.Delete {
+		event := events.OPDelete(delete)
+
+		operations = append(operations, events.Operation{
+			TxIndex: txIndex,
+			OpIndex: uint64(opIndex),
+			Delete:  &event,
+		})
+	}
+
+	return operations
 }
 
-func stringAnnotationsToMap(annotations []storagetx.StringAnnotation) map[string]string {
-	annotationsMap := make(map[string]string)
-	for _, annotation := range annotations {
-		annotationsMap[annotation.Key] = annotation.Value
+func sentBy(from common.Address, operations []events.Operation) []SentOperation {
+	sent := make([]SentOperation, 0, len(operations))
+	for _, op := range operations {
+		sent = append(sent, SentOperation{Operation: op, Sender: from})
 	}
-	return annotationsMap
+	return sent
 }
 
-func numericAnnotationsToMap(annotations []storagetx.NumericAnnotation) map[string]uint64 {
-	annotationsMap := make(map[string]uint64)
-	for _, annotation := range annotations {
-		annotationsMap[annotation.Key] = annotation.Value
+func createdEntities(receiptLogs []*types.Log) []common.Hash {
+	entities := []common.Hash{}
+	for _, log := range receiptLogs {
+		if log.Address == address.ArkivProcessorAddress && len(log.Topics) > 1 && log.Topics[0] == logs.ArkivEntityCreated {
+			entityKey := log.Topics[1]
+			entities = append(entities, entityKey)
+		}
 	}
-	return annotationsMap
+	return entities
 }
@@ -0,0 +1,66 @@
+package dbevents
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBlockContractCalls(t *testing.T) {
+	contract := common.HexToAddress("0xc0ffee")
+	callerHash := common.BytesToHash(contract.Bytes())
+
+	encoded, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			{BTL: 10, ContentType: "text/plain", Payload: []byte("a")},
+			{BTL: 10, ContentType: "text/plain", Payload: []byte("b")},
+		},
+	})
+	require.NoError(t, err)
+	call := compression.MustBrotliCompress(encoded)
+
+	// two processor calls in the same transaction, creating two entities each
+	logs := []*types.Log{}
+	for i := range 2 {
+		logs = append(logs, &types.Log{
+			Address: address.ArkivProcessorAddress,
+			Topics:  []common.Hash{arkivlogs.ArkivContractCall, callerHash, common.BigToHash(big.NewInt(int64(i))), {}},
+			Data:    call,
+		})
+		for j := range 2 {
+			logs = append(logs, &types.Log{
+				Address: address.ArkivProcessorAddress,
+				Topics:  []common.Hash{arkivlogs.ArkivEntityCreated, common.BigToHash(big.NewInt(int64(2*i + j + 1))), callerHash},
+			})
+		}
+	}
+
+	tx := types.NewTx(&types.LegacyTx{To: &contract})
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(types.Body{
+		Transactions: []*types.Transaction{tx},
+	})
+	receipts := []*types.Receipt{
+		{Status: types.ReceiptStatusSuccessful, Logs: logs},
+	}
+
+	operations, err := DecodeBlock(block, receipts)
+	require.NoError(t, err)
+	require.Len(t, operations, 4)
+
+	opIndices := map[uint64]bool{}
+	for i, op := range operations {
+		require.NotNil(t, op.Create)
+		require.Equal(t, contract, op.Sender)
+		require.Equal(t, common.BigToHash(big.NewInt(int64(i+1))), op.Create.Key)
+		opIndices[op.OpIndex] = true
+	}
+	require.Len(t, opIndices, 4)
+}
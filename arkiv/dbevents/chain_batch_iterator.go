@@ -1,6 +1,7 @@
 package dbevents
 
 import (
+	"context"
 	"sync"
 
 	arkivevents "github.com/Arkiv-Network/arkiv-events"
@@ -13,13 +14,25 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func NewChainBatchIterator(db ethdb.Database, lastBlock uint64) (
+// NewChainBatchIterator returns an iterator over the batches of the blocks after
+// lastBlock, and the function to call on every new head. The iterator stops
+// once ctx is cancelled.
+func NewChainBatchIterator(ctx context.Context, db ethdb.Database, lastBlock uint64) (
 	arkivevents.BatchIterator,
 	func(cc *params.ChainConfig, block *types.Block) error,
 ) {
 
 	cond := sync.NewCond(&sync.Mutex{})
 	var block *types.Block
+	done := false
+
+	// wake up the iterator waiting for a new head
+	context.AfterFunc(ctx, func() {
+		cond.L.Lock()
+		done = true
+		cond.Broadcast()
+		cond.L.Unlock()
+	})
 
 	var chainConfig *params.ChainConfig
 
@@ -46,9 +59,13 @@ func NewChainBatchIterator(db ethdb.Database, lastBlock uint64) (
 				func() {
 					cond.L.Lock()
 
-					for block == nil {
+					for block == nil && !done {
 						cond.Wait()
 					}
+					if done {
+						cond.L.Unlock()
+						return
+					}
 					newBlockNumber := block.NumberU64()
 
 					block = nil
@@ -102,6 +119,10 @@ func NewChainBatchIterator(db ethdb.Database, lastBlock uint64) (
 
 				}()
 
+				if ctx.Err() != nil {
+					return
+				}
+
 				if len(batch.Batch.Blocks) == 0 {
 					continue
 				}
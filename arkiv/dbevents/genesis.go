@@ -0,0 +1,37 @@
+package dbevents
+
+import (
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+)
+
+// GenesisBlock returns the events of block 0, which create the entities of the
+// Arkiv section of the genesis. The entities are numbered by the transaction and
+// operation indexes, so that their position in the genesis list is their sequence.
+func GenesisBlock(genesis *core.GenesisArkiv) *events.Block {
+	bl := &events.Block{
+		Number:     0,
+		Operations: make([]events.Operation, 0, len(genesis.Entities)),
+	}
+	for i, e := range genesis.Entities {
+		stringAttributes, numericAttributes := storagetx.Annotations{
+			String:  e.StringAnnotations,
+			Numeric: e.NumericAnnotations,
+		}.Attributes()
+		bl.Operations = append(bl.Operations, events.Operation{
+			TxIndex: uint64(i >> 16),
+			OpIndex: uint64(i & 0xffff),
+			Create: &events.OPCreate{
+				Key:               e.Key(i),
+				ContentType:       e.ContentType,
+				BTL:               e.ExpiresAtBlock(0),
+				Owner:             e.Owner,
+				Content:           e.Payload,
+				StringAttributes:  stringAttributes,
+				NumericAttributes: numericAttributes,
+			},
+		})
+	}
+	return bl
+}
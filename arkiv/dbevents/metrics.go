@@ -0,0 +1,46 @@
+package dbevents
+
+import (
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	createdMeter      = metrics.NewRegisteredMeter("arkiv/entities/created", nil)
+	updatedMeter      = metrics.NewRegisteredMeter("arkiv/entities/updated", nil)
+	deletedMeter      = metrics.NewRegisteredMeter("arkiv/entities/deleted", nil)
+	expiredMeter      = metrics.NewRegisteredMeter("arkiv/entities/expired", nil)
+	extendedMeter     = metrics.NewRegisteredMeter("arkiv/entities/extended", nil)
+	ownerChangedMeter = metrics.NewRegisteredMeter("arkiv/entities/ownerchanged", nil)
+	payloadBytesMeter = metrics.NewRegisteredMeter("arkiv/entities/payload", nil)
+
+	// Operations of a single block, to watch the distribution per block
+	blockOperationsHistogram = metrics.NewRegisteredHistogram("arkiv/block/operations", nil, metrics.NewExpDecaySample(1028, 0.015))
+
+	ingestedBlockGauge = metrics.NewRegisteredGauge("arkiv/ingest/block", nil)
+	ingestLagGauge     = metrics.NewRegisteredGauge("arkiv/ingest/lag", nil)
+	ingestTimer        = metrics.NewRegisteredTimer("arkiv/ingest/time", nil)
+)
+
+func recordBlock(block events.Block) {
+	blockOperationsHistogram.Update(int64(len(block.Operations)))
+
+	for _, op := range block.Operations {
+		switch {
+		case op.Create != nil:
+			createdMeter.Mark(1)
+			payloadBytesMeter.Mark(int64(len(op.Create.Content)))
+		case op.Update != nil:
+			updatedMeter.Mark(1)
+			payloadBytesMeter.Mark(int64(len(op.Update.Content)))
+		case op.Delete != nil:
+			deletedMeter.Mark(1)
+		case op.Expire != nil:
+			expiredMeter.Mark(1)
+		case op.ExtendBTL != nil:
+			extendedMeter.Mark(1)
+		case op.ChangeOwner != nil:
+			ownerChangedMeter.Mark(1)
+		}
+	}
+}
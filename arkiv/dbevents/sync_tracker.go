@@ -0,0 +1,117 @@
+package dbevents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+)
+
+// SyncTracker follows the progress of the Arkiv store, which is filled
+// asynchronously from the chain, so that readers can tell how far behind the
+// chain head it is and wait for it to ingest a given block.
+type SyncTracker struct {
+	mu       sync.Mutex
+	head     uint64
+	ingested uint64
+	// closed and replaced every time a batch is ingested
+	progress chan struct{}
+}
+
+// NewSyncTracker creates a tracker for a store that has ingested all the blocks
+// up to lastBlock.
+func NewSyncTracker(lastBlock uint64) *SyncTracker {
+	return &SyncTracker{
+		head:     lastBlock,
+		ingested: lastBlock,
+		progress: make(chan struct{}),
+	}
+}
+
+// Track wraps the iterator feeding the store. A batch is considered ingested
+// once the store returns from processing it. Only the iterator of the store
+// should be tracked, as it also reports the metrics of the ingested blocks.
+func (t *SyncTracker) Track(it arkivevents.BatchIterator) arkivevents.BatchIterator {
+	return func(yield func(arkivevents.BatchOrError) bool) {
+		for batch := range it {
+			if batch.Error != nil || len(batch.Batch.Blocks) == 0 {
+				if !yield(batch) {
+					return
+				}
+				continue
+			}
+
+			start := time.Now()
+			if !yield(batch) {
+				return
+			}
+			ingestTimer.UpdateSince(start)
+
+			for _, block := range batch.Batch.Blocks {
+				recordBlock(block)
+			}
+
+			t.setIngested(batch.Batch.Blocks[len(batch.Batch.Blocks)-1].Number)
+		}
+	}
+}
+
+// OnNewHead has to be called with the number of every new chain head.
+func (t *SyncTracker) OnNewHead(number uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.head = number
+	t.updateLag()
+}
+
+// Status returns the last block ingested by the store and the last chain head
+// reported to the tracker.
+func (t *SyncTracker) Status() (ingested, head uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.ingested, t.head
+}
+
+// WaitForBlock blocks until the store has ingested the given block or the
+// context is done.
+func (t *SyncTracker) WaitForBlock(ctx context.Context, number uint64) error {
+	for {
+		t.mu.Lock()
+		ingested, progress := t.ingested, t.progress
+		t.mu.Unlock()
+
+		if ingested >= number {
+			return nil
+		}
+
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return fmt.Errorf("arkiv store has not ingested block %d, last ingested block is %d: %w", number, ingested, ctx.Err())
+		}
+	}
+}
+
+func (t *SyncTracker) setIngested(number uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ingested = number
+	ingestedBlockGauge.Update(int64(number))
+	t.updateLag()
+
+	close(t.progress)
+	t.progress = make(chan struct{})
+}
+
+func (t *SyncTracker) updateLag() {
+	if t.head > t.ingested {
+		ingestLagGauge.Update(int64(t.head - t.ingested))
+	} else {
+		ingestLagGauge.Update(0)
+	}
+}
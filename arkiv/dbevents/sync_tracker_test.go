@@ -0,0 +1,59 @@
+package dbevents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncTracker(t *testing.T) {
+	tracker := NewSyncTracker(5)
+
+	batches := make(chan arkivevents.BatchOrError)
+	it := tracker.Track(func(yield func(arkivevents.BatchOrError) bool) {
+		for batch := range batches {
+			if !yield(batch) {
+				return
+			}
+		}
+	})
+
+	ingested := make(chan struct{})
+	go func() {
+		for range it {
+			ingested <- struct{}{}
+		}
+	}()
+
+	tracker.OnNewHead(8)
+	store, head := tracker.Status()
+	require.Equal(t, uint64(5), store)
+	require.Equal(t, uint64(8), head)
+
+	require.NoError(t, tracker.WaitForBlock(context.Background(), 4))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, tracker.WaitForBlock(ctx, 7), context.DeadlineExceeded)
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- tracker.WaitForBlock(context.Background(), 7)
+	}()
+
+	batches <- arkivevents.BatchOrError{Batch: events.BlockBatch{Blocks: []events.Block{{Number: 6}}}}
+	<-ingested
+	batches <- arkivevents.BatchOrError{Batch: events.BlockBatch{Blocks: []events.Block{{Number: 7}, {Number: 8}}}}
+	<-ingested
+
+	require.NoError(t, <-waited)
+
+	store, _ = tracker.Status()
+	require.Equal(t, uint64(8), store)
+
+	close(batches)
+}
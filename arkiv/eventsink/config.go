@@ -0,0 +1,84 @@
+package eventsink
+
+import (
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Config selects the sinks fed with the Arkiv event stream.
+type Config struct {
+	// File is the path of the append-only file sink, empty to disable it.
+	File string
+	// FileFormat is the encoding of File, FormatJSONL (default) or FormatRLP.
+	FileFormat string
+	// Webhook is the http(s):// or unix:// URL of the webhook sink, empty to disable it.
+	Webhook string
+	// Plugins are paths of Go plugins providing sinks, see PluginSymbol.
+	Plugins []string
+	// DataDir keeps the cursor of the webhook sink and the state of plugin sinks.
+	// If empty, the webhook cursor is not persisted.
+	DataDir string
+}
+
+// New creates the sinks enabled in cfg. If a sink fails to open, the sinks
+// opened before it are closed.
+func New(cfg Config) ([]Sink, error) {
+	opens := []func() (Sink, error){}
+
+	if cfg.File != "" {
+		format := cfg.FileFormat
+		if format == "" {
+			format = FormatJSONL
+		}
+		opens = append(opens, func() (Sink, error) {
+			return NewFileSink(cfg.File, format)
+		})
+	}
+
+	if cfg.Webhook != "" {
+		opens = append(opens, func() (Sink, error) {
+			return NewWebhookSink(cfg.Webhook, dataDirPath(cfg.DataDir, "webhook.cursor"))
+		})
+	}
+
+	for _, path := range cfg.Plugins {
+		dataDir := dataDirPath(cfg.DataDir, filepath.Base(path))
+		opens = append(opens, func() (Sink, error) {
+			return OpenPlugin(path, dataDir)
+		})
+	}
+
+	return openAll(opens)
+}
+
+// openAll opens the sinks in order. If one fails, the sinks opened before it
+// are closed.
+func openAll(opens []func() (Sink, error)) ([]Sink, error) {
+	sinks := []Sink{}
+	for _, open := range opens {
+		s, err := open()
+		if err != nil {
+			closeAll(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+// closeAll closes the sinks, logging the errors.
+func closeAll(sinks []Sink) {
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			log.Warn("Failed to close Arkiv event sink", "sink", s.Name(), "error", err)
+		}
+	}
+}
+
+func dataDirPath(dataDir string, name string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, name)
+}
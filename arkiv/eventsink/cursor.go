@@ -0,0 +1,84 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Position is the persisted state of a sink.
+type Position struct {
+	// Block is the number of the last block consumed by the sink.
+	Block uint64 `json:"block"`
+	// Offset is the size of the output of file based sinks after Block was written.
+	Offset int64 `json:"offset,omitempty"`
+}
+
+// Cursor persists the position of a sink in a small JSON file.
+// A cursor with an empty path is kept in memory only.
+type Cursor struct {
+	path     string
+	position Position
+}
+
+// NewCursor loads the cursor stored at path, starting from block 0 if the file
+// does not exist yet.
+func NewCursor(path string) (*Cursor, error) {
+	c := &Cursor{path: path}
+
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return c, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read cursor: %w", err)
+	}
+
+	err = json.Unmarshal(data, &c.position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cursor %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Position returns the last stored position.
+func (c *Cursor) Position() Position {
+	return c.position
+}
+
+// Store atomically replaces the stored position.
+func (c *Cursor) Store(p Position) error {
+	if c.path != "" {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to encode cursor: %w", err)
+		}
+
+		err = os.MkdirAll(filepath.Dir(c.path), 0o755)
+		if err != nil {
+			return fmt.Errorf("failed to create cursor directory: %w", err)
+		}
+
+		tmp := c.path + ".tmp"
+		err = os.WriteFile(tmp, data, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to write cursor: %w", err)
+		}
+
+		err = os.Rename(tmp, c.path)
+		if err != nil {
+			return fmt.Errorf("failed to replace cursor: %w", err)
+		}
+	}
+
+	c.position = p
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+)
+
+const (
+	// FormatJSONL writes one JSON encoded block per line.
+	FormatJSONL = "jsonl"
+	// FormatRLP writes a stream of RLP encoded blocks, see RLPBlock.
+	FormatRLP = "rlp"
+)
+
+// FileSink appends the event stream to a file.
+// Its cursor is stored next to the file and records the size of the file after
+// the last consumed block, so that a partially written batch is discarded when
+// the sink is reopened.
+type FileSink struct {
+	path   string
+	format string
+	file   *os.File
+	cursor *Cursor
+}
+
+// NewFileSink opens or creates the file at path, writing blocks in the given
+// format (FormatJSONL or FormatRLP).
+func NewFileSink(path string, format string) (*FileSink, error) {
+	if format != FormatJSONL && format != FormatRLP {
+		return nil, fmt.Errorf("unsupported file sink format %q", format)
+	}
+
+	cursor, err := NewCursor(path + ".cursor")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file sink: %w", err)
+	}
+
+	// drop anything that was written after the cursor was last stored
+	offset := cursor.Position().Offset
+	err = file.Truncate(offset)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate file sink: %w", err)
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek file sink: %w", err)
+	}
+
+	return &FileSink{
+		path:   path,
+		format: format,
+		file:   file,
+		cursor: cursor,
+	}, nil
+}
+
+func (s *FileSink) Name() string {
+	return fmt.Sprintf("file(%s)", s.path)
+}
+
+func (s *FileSink) LastBlock() (uint64, error) {
+	return s.cursor.Position().Block, nil
+}
+
+func (s *FileSink) Consume(ctx context.Context, batch events.BlockBatch) error {
+	if len(batch.Blocks) == 0 {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	for _, block := range batch.Blocks {
+		err := s.encode(buf, block)
+		if err != nil {
+			return fmt.Errorf("failed to encode block %d: %w", block.Number, err)
+		}
+	}
+
+	position := s.cursor.Position()
+
+	// On error the file is rewound, so that the retried batch doesn't follow
+	// a partial or unsynced write
+	n, err := s.file.Write(buf.Bytes())
+	if err != nil {
+		s.rewind(position.Offset)
+		return fmt.Errorf("failed to write to file sink: %w", err)
+	}
+
+	err = s.file.Sync()
+	if err != nil {
+		s.rewind(position.Offset)
+		return fmt.Errorf("failed to sync file sink: %w", err)
+	}
+
+	err = s.cursor.Store(Position{
+		Block:  batch.Blocks[len(batch.Blocks)-1].Number,
+		Offset: position.Offset + int64(n),
+	})
+	if err != nil {
+		s.rewind(position.Offset)
+		return err
+	}
+	return nil
+}
+
+// rewind discards everything written after offset.
+func (s *FileSink) rewind(offset int64) {
+	s.file.Truncate(offset)
+	s.file.Seek(offset, io.SeekStart)
+}
+
+func (s *FileSink) encode(w io.Writer, block events.Block) error {
+	switch s.format {
+	case FormatRLP:
+		return EncodeRLPBlock(w, block)
+	default:
+		return json.NewEncoder(w).Encode(block)
+	}
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,137 @@
+package eventsink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func testBatch(from, to uint64) events.BlockBatch {
+	batch := events.BlockBatch{}
+	for n := from; n <= to; n++ {
+		batch.Blocks = append(batch.Blocks, events.Block{
+			Number: n,
+			Operations: []events.Operation{
+				{
+					Create: &events.OPCreate{
+						Key:               common.BigToHash(common.Big1),
+						ContentType:       "text/plain",
+						BTL:               100,
+						Content:           []byte("hello"),
+						StringAttributes:  map[string]string{"b": "2", "a": "1"},
+						NumericAttributes: map[string]uint64{"n": 1},
+					},
+				},
+			},
+		})
+	}
+	return batch
+}
+
+func TestFileSinkResumesFromCursor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := NewFileSink(path, FormatJSONL)
+	require.NoError(t, err)
+
+	lastBlock, err := sink.LastBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), lastBlock)
+
+	require.NoError(t, sink.Consume(context.Background(), testBatch(1, 3)))
+	require.NoError(t, sink.Close())
+
+	// simulate a crash after writing a batch but before storing the cursor
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"number":4,"operations":[]}` + "\n" + `{"numb`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sink, err = NewFileSink(path, FormatJSONL)
+	require.NoError(t, err)
+
+	lastBlock, err = sink.LastBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), lastBlock)
+
+	require.NoError(t, sink.Consume(context.Background(), testBatch(4, 5)))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	numbers := []uint64{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		block := events.Block{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &block))
+		numbers = append(numbers, block.Number)
+	}
+	require.Equal(t, []uint64{1, 2, 3, 4, 5}, numbers)
+}
+
+func TestFileSinkRewindsFailedBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := NewFileSink(path, FormatJSONL)
+	require.NoError(t, err)
+	require.NoError(t, sink.Consume(context.Background(), testBatch(1, 2)))
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// a directory in place of the cursor fails storing it after the write
+	cursor := path + ".cursor"
+	require.NoError(t, os.Remove(cursor))
+	require.NoError(t, os.MkdirAll(filepath.Join(cursor, "dir"), 0o755))
+	require.Error(t, sink.Consume(context.Background(), testBatch(3, 3)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, written, data)
+
+	// the retried batch is written once
+	require.NoError(t, os.RemoveAll(cursor))
+	require.NoError(t, sink.Consume(context.Background(), testBatch(3, 3)))
+	require.NoError(t, sink.Close())
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, bytes.Count(data, []byte("\n")))
+
+	lastBlock, err := sink.LastBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), lastBlock)
+}
+
+func TestFileSinkRLP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.rlp")
+
+	sink, err := NewFileSink(path, FormatRLP)
+	require.NoError(t, err)
+	require.NoError(t, sink.Consume(context.Background(), testBatch(1, 2)))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	stream := rlp.NewStream(bytes.NewReader(data), 0)
+	for _, number := range []uint64{1, 2} {
+		block := RLPBlock{}
+		require.NoError(t, stream.Decode(&block))
+		require.Equal(t, number, block.Number)
+		require.Len(t, block.Operations, 1)
+		require.Equal(t, RLPOpCreate, block.Operations[0].Type)
+		require.Equal(t, []RLPStringAttribute{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}, block.Operations[0].StringAttributes)
+	}
+}
@@ -0,0 +1,42 @@
+package eventsink
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// PluginSymbol is the name of the constructor a sink plugin has to export.
+// It must be a PluginConstructor, for example:
+//
+//	func NewArkivSink(dataDir string) (eventsink.Sink, error)
+//
+// The plugin is expected to keep its cursor in dataDir.
+const PluginSymbol = "NewArkivSink"
+
+// PluginConstructor is the type of the constructor exported by sink plugins.
+type PluginConstructor = func(dataDir string) (Sink, error)
+
+// OpenPlugin loads the Go plugin at path and creates its sink.
+func OpenPlugin(path string, dataDir string) (Sink, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sink plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("sink plugin %s: %w", path, err)
+	}
+
+	constructor, ok := sym.(PluginConstructor)
+	if !ok {
+		return nil, fmt.Errorf("sink plugin %s: %s has type %T, expected %T", path, PluginSymbol, sym, PluginConstructor(nil))
+	}
+
+	sink, err := constructor(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sink of plugin %s: %w", path, err)
+	}
+
+	return sink, nil
+}
@@ -0,0 +1,129 @@
+package eventsink
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Operation types of RLPOperation.
+const (
+	RLPOpCreate uint8 = iota + 1
+	RLPOpUpdate
+	RLPOpDelete
+	RLPOpExpire
+	RLPOpExtendBTL
+	RLPOpChangeOwner
+)
+
+// RLPBlock is the RLP representation of events.Block.
+// RLP has no maps, so operations are flattened and attributes are sorted by key.
+type RLPBlock struct {
+	Number     uint64
+	Operations []RLPOperation
+}
+
+// RLPOperation is the RLP representation of events.Operation.
+// Only the fields relevant for Type are set.
+type RLPOperation struct {
+	TxIndex           uint64
+	OpIndex           uint64
+	Type              uint8
+	Key               common.Hash
+	Owner             common.Address
+	BTL               uint64
+	ContentType       string
+	Content           []byte
+	StringAttributes  []RLPStringAttribute
+	NumericAttributes []RLPNumericAttribute
+}
+
+type RLPStringAttribute struct {
+	Key   string
+	Value string
+}
+
+type RLPNumericAttribute struct {
+	Key   string
+	Value uint64
+}
+
+// EncodeRLPBlock writes the RLP encoding of block to w.
+func EncodeRLPBlock(w io.Writer, block events.Block) error {
+	return rlp.Encode(w, toRLPBlock(block))
+}
+
+func toRLPBlock(block events.Block) *RLPBlock {
+	b := &RLPBlock{
+		Number:     block.Number,
+		Operations: make([]RLPOperation, 0, len(block.Operations)),
+	}
+
+	for _, op := range block.Operations {
+		o := RLPOperation{
+			TxIndex: op.TxIndex,
+			OpIndex: op.OpIndex,
+		}
+
+		switch {
+		case op.Create != nil:
+			o.Type = RLPOpCreate
+			o.Key = op.Create.Key
+			o.Owner = op.Create.Owner
+			o.BTL = op.Create.BTL
+			o.ContentType = op.Create.ContentType
+			o.Content = op.Create.Content
+			o.StringAttributes = toRLPStringAttributes(op.Create.StringAttributes)
+			o.NumericAttributes = toRLPNumericAttributes(op.Create.NumericAttributes)
+		case op.Update != nil:
+			o.Type = RLPOpUpdate
+			o.Key = op.Update.Key
+			o.Owner = op.Update.Owner
+			o.BTL = op.Update.BTL
+			o.ContentType = op.Update.ContentType
+			o.Content = op.Update.Content
+			o.StringAttributes = toRLPStringAttributes(op.Update.StringAttributes)
+			o.NumericAttributes = toRLPNumericAttributes(op.Update.NumericAttributes)
+		case op.Delete != nil:
+			o.Type = RLPOpDelete
+			o.Key = common.Hash(*op.Delete)
+		case op.Expire != nil:
+			o.Type = RLPOpExpire
+			o.Key = common.Hash(*op.Expire)
+		case op.ExtendBTL != nil:
+			o.Type = RLPOpExtendBTL
+			o.Key = op.ExtendBTL.Key
+			o.BTL = op.ExtendBTL.BTL
+		case op.ChangeOwner != nil:
+			o.Type = RLPOpChangeOwner
+			o.Key = op.ChangeOwner.Key
+			o.Owner = op.ChangeOwner.Owner
+		}
+
+		b.Operations = append(b.Operations, o)
+	}
+
+	return b
+}
+
+func toRLPStringAttributes(attributes map[string]string) []RLPStringAttribute {
+	res := make([]RLPStringAttribute, 0, len(attributes))
+	for k, v := range attributes {
+		res = append(res, RLPStringAttribute{Key: k, Value: v})
+	}
+	slices.SortFunc(res, func(a, b RLPStringAttribute) int { return strings.Compare(a.Key, b.Key) })
+	return res
+}
+
+func toRLPNumericAttributes(attributes map[string]uint64) []RLPNumericAttribute {
+	res := make([]RLPNumericAttribute, 0, len(attributes))
+	for k, v := range attributes {
+		res = append(res, RLPNumericAttribute{Key: k, Value: v})
+	}
+	slices.SortFunc(res, func(a, b RLPNumericAttribute) int { return strings.Compare(a.Key, b.Key) })
+	return res
+}
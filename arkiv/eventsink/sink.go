@@ -0,0 +1,166 @@
+// Package eventsink fans out the Arkiv event stream to additional consumers.
+//
+// Every sink follows the chain with its own batch iterator, starting after the
+// last block it has consumed, so sinks can lag behind or be restarted
+// independently of each other and of the embedded SQLite store.
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Sink consumes batches of the Arkiv event stream.
+type Sink interface {
+	// Name identifies the sink in logs.
+	Name() string
+
+	// LastBlock returns the number of the last block the sink has consumed.
+	// The stream is resumed from the following block.
+	LastBlock() (uint64, error)
+
+	// Consume processes a batch of blocks and persists the sink's cursor.
+	// A failed batch is retried, so Consume must not advance the cursor on error.
+	Consume(ctx context.Context, batch events.BlockBatch) error
+
+	Close() error
+}
+
+// retryDelay is the time to wait before a failed batch is handed to a sink again.
+const retryDelay = 5 * time.Second
+
+type follower struct {
+	sink      Sink
+	onNewHead func(cc *params.ChainConfig, block *types.Block) error
+
+	// genesis is the block 0 of the genesis entities, if the sink hasn't
+	// consumed it yet
+	genesis *events.Block
+}
+
+// Runner feeds the Arkiv event stream to a set of sinks.
+type Runner struct {
+	followers []*follower
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Start starts following the chain for every sink, resuming each one from its
+// own cursor. OnNewHead of the returned runner must be called on every new head.
+// The runner owns the sinks: they are closed by Close, or right away if Start
+// fails.
+//
+// The genesis entities are not part of the chain. If genesis is not nil, it is
+// prepended to the first batch of every sink that hasn't consumed a block yet,
+// so that the sink's cursor only moves past it together with the first block.
+func Start(db ethdb.Database, sinks []Sink, genesis *events.Block) (*Runner, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &Runner{cancel: cancel}
+
+	for _, sink := range sinks {
+		lastBlock, err := sink.LastBlock()
+		if err != nil {
+			r.Close()
+			closeAll(sinks[len(r.followers):])
+			return nil, fmt.Errorf("failed to get last block of sink %s: %w", sink.Name(), err)
+		}
+
+		batchIterator, onNewHead := dbevents.NewChainBatchIterator(ctx, db, lastBlock)
+
+		f := &follower{
+			sink:      sink,
+			onNewHead: onNewHead,
+		}
+		if lastBlock == 0 {
+			f.genesis = genesis
+		}
+		r.followers = append(r.followers, f)
+
+		log.Info("Starting Arkiv event sink", "sink", sink.Name(), "lastBlock", lastBlock)
+
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			f.follow(ctx, batchIterator)
+		}()
+	}
+
+	return r, nil
+}
+
+// OnNewHead notifies all sinks of a new chain head.
+func (r *Runner) OnNewHead(cc *params.ChainConfig, block *types.Block) error {
+	for _, f := range r.followers {
+		err := f.onNewHead(cc, block)
+		if err != nil {
+			return fmt.Errorf("failed to notify sink %s: %w", f.sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Close stops following the chain, waits for the sinks to finish the batches
+// they are consuming and closes them. It returns the first error of closing the
+// sinks.
+func (r *Runner) Close() error {
+	r.cancel()
+	r.wg.Wait()
+
+	var firstErr error
+	for _, f := range r.followers {
+		err := f.sink.Close()
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close sink %s: %w", f.sink.Name(), err)
+		}
+	}
+	return firstErr
+}
+
+// follow feeds the batches to the sink until ctx is cancelled. The chain batch
+// iterator doesn't yield errors: blocks it fails to read are read again on the
+// next head.
+func (f *follower) follow(ctx context.Context, batches arkivevents.BatchIterator) {
+	for batch := range batches {
+		blocks := batch.Batch
+		if f.genesis != nil {
+			blocks.Blocks = append([]events.Block{*f.genesis}, blocks.Blocks...)
+		}
+		for !f.consume(ctx, blocks) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+		f.genesis = nil
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (f *follower) consume(ctx context.Context, batch events.BlockBatch) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	err := f.sink.Consume(ctx, batch)
+	if err != nil {
+		log.Warn("Arkiv event sink failed to consume batch, retrying", "sink", f.sink.Name(), "from", batch.Blocks[0].Number, "to", batch.Blocks[len(batch.Blocks)-1].Number, "error", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,90 @@
+package eventsink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/stretchr/testify/require"
+)
+
+type testSink struct {
+	name     string
+	batches  []events.BlockBatch
+	closed   bool
+	closeErr error
+}
+
+func (s *testSink) Name() string { return s.name }
+
+func (s *testSink) LastBlock() (uint64, error) { return 0, nil }
+
+func (s *testSink) Consume(ctx context.Context, batch events.BlockBatch) error {
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *testSink) Close() error {
+	s.closed = true
+	return s.closeErr
+}
+
+func TestOpenAllClosesOpenedSinks(t *testing.T) {
+	first, second := &testSink{name: "first"}, &testSink{name: "second"}
+	errOpen := errors.New("open failed")
+
+	sinks, err := openAll([]func() (Sink, error){
+		func() (Sink, error) { return first, nil },
+		func() (Sink, error) { return second, nil },
+		func() (Sink, error) { return nil, errOpen },
+	})
+	require.ErrorIs(t, err, errOpen)
+	require.Nil(t, sinks)
+	require.True(t, first.closed)
+	require.True(t, second.closed)
+}
+
+func TestRunnerCloseStopsFollowers(t *testing.T) {
+	sink := &testSink{name: "test"}
+	r, err := Start(rawdb.NewMemoryDatabase(), []Sink{sink}, nil)
+	require.NoError(t, err)
+
+	// the followers wait for a new head that never comes
+	closed := make(chan error)
+	go func() {
+		closed <- r.Close()
+	}()
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	require.True(t, sink.closed)
+}
+
+func TestFollowerPrependsGenesis(t *testing.T) {
+	genesis := &events.Block{Number: 0, Operations: []events.Operation{{Create: &events.OPCreate{BTL: 10}}}}
+	batches := arkivevents.BatchIterator(func(yield func(arkivevents.BatchOrError) bool) {
+		for n := uint64(1); n <= 2; n++ {
+			if !yield(arkivevents.BatchOrError{Batch: events.BlockBatch{Blocks: []events.Block{{Number: n}}}}) {
+				return
+			}
+		}
+	})
+
+	sink := &testSink{name: "test"}
+	f := &follower{sink: sink, genesis: genesis}
+	f.follow(context.Background(), batches)
+
+	require.Len(t, sink.batches, 2)
+	require.Len(t, sink.batches[0].Blocks, 2)
+	require.Equal(t, *genesis, sink.batches[0].Blocks[0])
+	require.Equal(t, uint64(1), sink.batches[0].Blocks[1].Number)
+	require.Len(t, sink.batches[1].Blocks, 1)
+	require.Equal(t, uint64(2), sink.batches[1].Blocks[0].Number)
+}
@@ -0,0 +1,112 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+)
+
+// webhookTimeout bounds a single delivery of a batch.
+const webhookTimeout = 30 * time.Second
+
+// WebhookSink posts every batch as JSON to an HTTP endpoint.
+// Besides http:// and https:// URLs, unix:///path/to/socket delivers the batches
+// over HTTP on a local unix socket.
+// A batch is considered consumed once the endpoint responds with a 2xx status.
+type WebhookSink struct {
+	url      string
+	endpoint string
+	client   *http.Client
+	cursor   *Cursor
+}
+
+// NewWebhookSink creates a sink posting to rawURL, keeping its cursor at cursorPath.
+func NewWebhookSink(rawURL string, cursorPath string) (*WebhookSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	endpoint := rawURL
+
+	switch u.Scheme {
+	case "http", "https":
+	case "unix":
+		socketPath := u.Path
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		endpoint = "http://unix/"
+	default:
+		return nil, fmt.Errorf("unsupported webhook url scheme %q", u.Scheme)
+	}
+
+	cursor, err := NewCursor(cursorPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookSink{
+		url:      rawURL,
+		endpoint: endpoint,
+		client:   client,
+		cursor:   cursor,
+	}, nil
+}
+
+func (s *WebhookSink) Name() string {
+	return fmt.Sprintf("webhook(%s)", s.url)
+}
+
+func (s *WebhookSink) LastBlock() (uint64, error) {
+	return s.cursor.Position().Block, nil
+}
+
+func (s *WebhookSink) Consume(ctx context.Context, batch events.BlockBatch) error {
+	if len(batch.Blocks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post batch: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+
+	return s.cursor.Store(Position{
+		Block: batch.Blocks[len(batch.Blocks)-1].Number,
+	})
+}
+
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,420 @@
+// Package history indexes the Arkiv event stream by entity, so that the
+// revisions of an entity can be reconstructed from the chain without scanning it.
+//
+// The index only records where the operations on an entity are, the revisions
+// themselves are decoded from the transactions and receipts of those blocks.
+package history
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// MaxRevisions is the maximum number of revisions returned by a single lookup.
+const MaxRevisions = 1000
+
+var (
+	cursorKey = []byte("cursor")
+
+	// entryPrefix + entity key + block number (uint64 big endian) + position of
+	// the operation in the block (uint32 big endian) -> nothing
+	entryPrefix = []byte("e")
+
+	// hashPrefix + block number (uint64 big endian) -> hash of the indexed block
+	hashPrefix = []byte("h")
+)
+
+// ErrTooManyRevisions is returned when a lookup matches more than MaxRevisions revisions.
+var ErrTooManyRevisions = fmt.Errorf("more than %d revisions, narrow the block range", MaxRevisions)
+
+// Revision is a single operation on an entity.
+// Owner, ContentType, Payload and the annotations are set for creates and
+// updates, Owner also for owner changes, and BTL for creates, updates and
+// extensions, where it is the number of blocks the entity is extended by.
+type Revision struct {
+	Type               string            `json:"type"`
+	Key                common.Hash       `json:"key"`
+	BlockNumber        uint64            `json:"blockNumber"`
+	TxHash             common.Hash       `json:"txHash"`
+	TxIndex            uint64            `json:"txIndex"`
+	OpIndex            uint64            `json:"opIndex"`
+	Sender             common.Address    `json:"sender"`
+	Owner              *common.Address   `json:"owner,omitempty"`
+	ContentType        string            `json:"contentType,omitempty"`
+	Payload            hexutil.Bytes     `json:"payload,omitempty"`
+	BTL                uint64            `json:"btl,omitempty"`
+	StringAnnotations  map[string]string `json:"stringAnnotations,omitempty"`
+	NumericAnnotations map[string]uint64 `json:"numericAnnotations,omitempty"`
+}
+
+// Index is an eventsink.Sink that records the operations of every entity.
+//
+// The hash of every indexed block is recorded as well, so that the blocks that
+// were reorged out are replaced by the ones of the new canonical chain, which
+// the event stream doesn't deliver again if they are not past its last block.
+type Index struct {
+	db      ethdb.KeyValueStore
+	chainDb ethdb.Database
+	config  *params.ChainConfig
+
+	mu sync.Mutex // serializes the writes of Consume and reorg
+}
+
+// NewIndex creates an index stored in db, for the chain stored in chainDb.
+func NewIndex(db ethdb.KeyValueStore, chainDb ethdb.Database, config *params.ChainConfig) *Index {
+	return &Index{
+		db:      db,
+		chainDb: chainDb,
+		config:  config,
+	}
+}
+
+func (i *Index) Name() string {
+	return "history"
+}
+
+func (i *Index) LastBlock() (uint64, error) {
+	has, err := i.db.Has(cursorKey)
+	if err != nil || !has {
+		return 0, err
+	}
+	data, err := i.db.Get(cursorKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("invalid history cursor of %d bytes", len(data))
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+func (i *Index) Consume(ctx context.Context, batch events.BlockBatch) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	b := i.db.NewBatch()
+
+	// The first batch starts with block 0 of the genesis entities
+	if first := batch.Blocks[0].Number; first > 0 {
+		if err := i.reorg(b, first-1); err != nil {
+			return err
+		}
+	}
+
+	for _, block := range batch.Blocks {
+		if err := putBlock(b, block.Number, rawdb.ReadCanonicalHash(i.chainDb, block.Number), block.Operations); err != nil {
+			return err
+		}
+	}
+
+	if err := b.Put(cursorKey, binary.BigEndian.AppendUint64(nil, batch.Blocks[len(batch.Blocks)-1].Number)); err != nil {
+		return err
+	}
+
+	return b.Write()
+}
+
+// reorg re-indexes the blocks up to the given one that are no longer part of
+// the canonical chain, from the last one back to the common ancestor.
+func (i *Index) reorg(b ethdb.Batch, number uint64) error {
+	for ; number > 0; number-- {
+		// blocks indexed before the hashes were recorded are kept as they are
+		indexed, err := i.db.Has(hashKey(number))
+		if err != nil || !indexed {
+			return err
+		}
+		stored, err := i.db.Get(hashKey(number))
+		if err != nil {
+			return err
+		}
+		hash := common.BytesToHash(stored)
+		canonical := rawdb.ReadCanonicalHash(i.chainDb, number)
+		if hash == canonical {
+			return nil
+		}
+
+		// drop the entries of the reorged out block
+		old, err := i.decodeBlock(hash, number)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			for position, op := range old.operations {
+				key, ok := operationKey(op.Operation)
+				if !ok {
+					continue
+				}
+				if err := b.Delete(entryKey(key, number, uint32(position))); err != nil {
+					return err
+				}
+			}
+		}
+		if canonical == (common.Hash{}) {
+			if err := b.Delete(hashKey(number)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := i.indexCanonical(b, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexCanonical indexes the canonical block of the given number, as decoded
+// from the chain.
+func (i *Index) indexCanonical(b ethdb.Batch, number uint64) error {
+	hash := rawdb.ReadCanonicalHash(i.chainDb, number)
+	decoded, err := i.decodeBlock(hash, number)
+	if err != nil || decoded == nil {
+		return err
+	}
+	operations := make([]events.Operation, 0, len(decoded.operations))
+	for _, op := range decoded.operations {
+		operations = append(operations, op.Operation)
+	}
+	return putBlock(b, number, hash, operations)
+}
+
+// putBlock records the operations of a block and its hash.
+func putBlock(b ethdb.Batch, number uint64, hash common.Hash, operations []events.Operation) error {
+	for position, op := range operations {
+		key, ok := operationKey(op)
+		if !ok {
+			continue
+		}
+		if err := b.Put(entryKey(key, number, uint32(position)), nil); err != nil {
+			return err
+		}
+	}
+	return b.Put(hashKey(number), hash.Bytes())
+}
+
+// Close does nothing, the databases are owned by the caller.
+func (i *Index) Close() error {
+	return nil
+}
+
+// Revisions returns the revisions of an entity between the given blocks,
+// inclusive, in the order they were executed. Blocks after the last indexed
+// block are not covered.
+func (i *Index) Revisions(key common.Hash, fromBlock, toBlock uint64) ([]Revision, error) {
+	// the chain may have been reorged since the last indexed block was consumed
+	if err := i.reorgIndexed(); err != nil {
+		return nil, err
+	}
+
+	prefix := append(append([]byte{}, entryPrefix...), key.Bytes()...)
+
+	it := i.db.NewIterator(prefix, binary.BigEndian.AppendUint64(nil, fromBlock))
+	defer it.Release()
+
+	revisions := []Revision{}
+
+	var (
+		decodedNumber uint64
+		decoded       *decodedBlock
+	)
+
+	for it.Next() {
+		entry := it.Key()[len(prefix):]
+		if len(entry) != 12 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(entry[:8])
+		position := binary.BigEndian.Uint32(entry[8:])
+		if number > toBlock {
+			break
+		}
+
+		if decoded == nil || decodedNumber != number {
+			var err error
+			decoded, err = i.decodeBlock(rawdb.ReadCanonicalHash(i.chainDb, number), number)
+			if err != nil {
+				return nil, err
+			}
+			decodedNumber = number
+		}
+
+		// entries of blocks that were reorged out since the index was
+		// checked no longer match
+		if decoded == nil || int(position) >= len(decoded.operations) {
+			continue
+		}
+		op := decoded.operations[position]
+		if opKey, _ := operationKey(op.Operation); opKey != key {
+			continue
+		}
+
+		if len(revisions) == MaxRevisions {
+			return nil, ErrTooManyRevisions
+		}
+		revisions = append(revisions, toRevision(number, decoded.txHashes[op.TxIndex], op))
+	}
+
+	return revisions, it.Error()
+}
+
+// reorgIndexed re-indexes the blocks up to the last indexed one that are no
+// longer part of the canonical chain.
+func (i *Index) reorgIndexed() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	last, err := i.LastBlock()
+	if err != nil || last == 0 {
+		return err
+	}
+	b := i.db.NewBatch()
+	if err := i.reorg(b, last); err != nil {
+		return err
+	}
+	return b.Write()
+}
+
+type decodedBlock struct {
+	operations []dbevents.SentOperation
+	txHashes   []common.Hash
+}
+
+// decodeBlock decodes the Arkiv operations of a block, it returns nil if the
+// block is not available.
+func (i *Index) decodeBlock(hash common.Hash, number uint64) (*decodedBlock, error) {
+	if hash == (common.Hash{}) {
+		return nil, nil
+	}
+	if number == 0 {
+		return decodeGenesis(i.chainDb, hash)
+	}
+	block := rawdb.ReadBlock(i.chainDb, hash, number)
+	if block == nil {
+		return nil, nil
+	}
+	receipts := rawdb.ReadReceipts(i.chainDb, hash, number, block.Time(), i.config)
+	if receipts == nil {
+		return nil, fmt.Errorf("receipts not found for block %d", number)
+	}
+
+	operations, err := dbevents.DecodeBlock(block, receipts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode block %d: %w", number, err)
+	}
+
+	txHashes := make([]common.Hash, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		txHashes = append(txHashes, tx.Hash())
+	}
+
+	return &decodedBlock{
+		operations: operations,
+		txHashes:   txHashes,
+	}, nil
+}
+
+// decodeGenesis returns the creates of the genesis entities, which have no
+// transaction nor sender.
+func decodeGenesis(chainDb ethdb.Database, hash common.Hash) (*decodedBlock, error) {
+	blob := rawdb.ReadArkivGenesisSpec(chainDb, hash)
+	if len(blob) == 0 {
+		return &decodedBlock{}, nil
+	}
+	spec := new(core.GenesisArkiv)
+	if err := json.Unmarshal(blob, spec); err != nil {
+		return nil, fmt.Errorf("invalid arkiv genesis: %w", err)
+	}
+
+	genesis := dbevents.GenesisBlock(spec)
+	decoded := &decodedBlock{
+		operations: make([]dbevents.SentOperation, 0, len(genesis.Operations)),
+		txHashes:   make([]common.Hash, len(genesis.Operations)>>16+1),
+	}
+	for _, op := range genesis.Operations {
+		decoded.operations = append(decoded.operations, dbevents.SentOperation{Operation: op})
+	}
+	return decoded, nil
+}
+
+func hashKey(number uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, hashPrefix...), number)
+}
+
+func entryKey(key common.Hash, number uint64, position uint32) []byte {
+	entry := make([]byte, 0, len(entryPrefix)+common.HashLength+12)
+	entry = append(entry, entryPrefix...)
+	entry = append(entry, key.Bytes()...)
+	entry = binary.BigEndian.AppendUint64(entry, number)
+	return binary.BigEndian.AppendUint32(entry, position)
+}
+
+func operationKey(op events.Operation) (common.Hash, bool) {
+	switch {
+	case op.Create != nil:
+		return op.Create.Key, true
+	case op.Update != nil:
+		return op.Update.Key, true
+	case op.Delete != nil:
+		return common.Hash(*op.Delete), true
+	case op.Expire != nil:
+		return common.Hash(*op.Expire), true
+	case op.ExtendBTL != nil:
+		return op.ExtendBTL.Key, true
+	case op.ChangeOwner != nil:
+		return op.ChangeOwner.Key, true
+	}
+	return common.Hash{}, false
+}
+
+func toRevision(number uint64, txHash common.Hash, op dbevents.SentOperation) Revision {
+	r := Revision{
+		BlockNumber: number,
+		TxHash:      txHash,
+		TxIndex:     op.TxIndex,
+		OpIndex:     op.OpIndex,
+		Sender:      op.Sender,
+	}
+	r.Key, _ = operationKey(op.Operation)
+
+	switch {
+	case op.Create != nil:
+		r.Type = "create"
+		r.Owner = &op.Create.Owner
+		r.ContentType = op.Create.ContentType
+		r.Payload = op.Create.Content
+		r.BTL = op.Create.BTL
+		r.StringAnnotations = op.Create.StringAttributes
+		r.NumericAnnotations = op.Create.NumericAttributes
+	case op.Update != nil:
+		r.Type = "update"
+		r.Owner = &op.Update.Owner
+		r.ContentType = op.Update.ContentType
+		r.Payload = op.Update.Content
+		r.BTL = op.Update.BTL
+		r.StringAnnotations = op.Update.StringAttributes
+		r.NumericAnnotations = op.Update.NumericAttributes
+	case op.Delete != nil:
+		r.Type = "delete"
+	case op.Expire != nil:
+		r.Type = "expire"
+	case op.ExtendBTL != nil:
+		r.Type = "extend"
+		r.BTL = op.ExtendBTL.BTL
+	case op.ChangeOwner != nil:
+		r.Type = "changeOwner"
+		r.Owner = &op.ChangeOwner.Owner
+	}
+
+	return r
+}
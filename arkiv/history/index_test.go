@@ -0,0 +1,250 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain struct {
+	t      *testing.T
+	db     ethdb.Database
+	signer types.Signer
+	nonce  uint64
+	blocks []events.Block
+}
+
+func (c *testChain) arkivTx(atx *storagetx.ArkivTransaction) *types.Transaction {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+	data, err := rlp.EncodeToBytes(atx)
+	require.NoError(c.t, err)
+
+	tx, err := types.SignNewTx(key, c.signer, &types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		Nonce:     c.nonce,
+		To:        &address.ArkivProcessorAddress,
+		Gas:       1000000,
+		GasFeeCap: big.NewInt(1),
+		Data:      compression.MustBrotliCompress(data),
+	})
+	require.NoError(c.t, err)
+	c.nonce++
+	return tx
+}
+
+// addBlock writes a canonical block and records its operations, as they are
+// fed to the index.
+func (c *testChain) addBlock(txs []*types.Transaction, receipts []*types.Receipt) {
+	c.blocks = append(c.blocks, c.writeBlock(uint64(len(c.blocks)+1), txs, receipts))
+}
+
+// writeBlock writes a canonical block, replacing the one of the same number,
+// and returns its operations.
+func (c *testChain) writeBlock(number uint64, txs []*types.Transaction, receipts []*types.Receipt) events.Block {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), BaseFee: big.NewInt(1), Extra: []byte{byte(c.nonce)}}
+	block := types.NewBlock(header, &types.Body{Transactions: txs}, receipts, trie.NewStackTrie(nil), types.DefaultBlockConfig)
+
+	rawdb.WriteBlock(c.db, block)
+	rawdb.WriteReceipts(c.db, block.Hash(), number, receipts)
+	rawdb.WriteCanonicalHash(c.db, block.Hash(), number)
+
+	operations, err := dbevents.DecodeBlock(block, receipts)
+	require.NoError(c.t, err)
+
+	bl := events.Block{Number: number}
+	for _, op := range operations {
+		bl.Operations = append(bl.Operations, op.Operation)
+	}
+	return bl
+}
+
+func receipt(logs ...*types.Log) *types.Receipt {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: logs}
+}
+
+func TestIndexRevisions(t *testing.T) {
+	chain := &testChain{
+		t:      t,
+		db:     rawdb.NewMemoryDatabase(),
+		signer: types.LatestSigner(params.TestChainConfig),
+	}
+	sender := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+	entity := common.HexToHash("0x01")
+	other := common.HexToHash("0x02")
+
+	createTx := chain.arkivTx(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			{BTL: 10, ContentType: "text/plain", Payload: []byte("first")},
+			{BTL: 10, ContentType: "text/plain", Payload: []byte("other")},
+		},
+	})
+	chain.addBlock([]*types.Transaction{createTx}, []*types.Receipt{receipt(
+		&types.Log{Address: address.ArkivProcessorAddress, Topics: []common.Hash{logs.ArkivEntityCreated, entity}},
+		&types.Log{Address: address.ArkivProcessorAddress, Topics: []common.Hash{logs.ArkivEntityCreated, other}},
+	)})
+
+	updateTx := chain.arkivTx(&storagetx.ArkivTransaction{
+		Update: []storagetx.ArkivUpdate{{
+			EntityKey:         entity,
+			BTL:               20,
+			ContentType:       "text/plain",
+			Payload:           []byte("second"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "version", Value: "2"}},
+		}},
+		Extend: []storagetx.ExtendBTL{{EntityKey: entity, NumberOfBlocks: 5}},
+	})
+	chain.addBlock([]*types.Transaction{updateTx}, []*types.Receipt{receipt()})
+
+	l1Block := common.HexToAddress("0x4200000000000000000000000000000000000015")
+	housekeepingTx := types.NewTx(&types.DepositTx{To: &l1Block})
+	chain.addBlock([]*types.Transaction{housekeepingTx}, []*types.Receipt{receipt(
+		&types.Log{Address: address.ArkivProcessorAddress, Topics: []common.Hash{logs.ArkivEntityExpired}, Data: entity.Bytes()},
+	)})
+
+	index := NewIndex(rawdb.NewMemoryDatabase(), chain.db, params.TestChainConfig)
+
+	last, err := index.LastBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), last)
+
+	require.NoError(t, index.Consume(context.Background(), events.BlockBatch{Blocks: chain.blocks}))
+	// a block that is not part of the canonical chain
+	require.NoError(t, index.Consume(context.Background(), events.BlockBatch{Blocks: []events.Block{{
+		Number:     4,
+		Operations: []events.Operation{{ExtendBTL: &events.OPExtendBTL{Key: entity, BTL: 1}}},
+	}}}))
+
+	last, err = index.LastBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), last)
+
+	revisions, err := index.Revisions(entity, 0, 4)
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+
+	require.Equal(t, "create", revisions[0].Type)
+	require.Equal(t, uint64(1), revisions[0].BlockNumber)
+	require.Equal(t, createTx.Hash(), revisions[0].TxHash)
+	require.Equal(t, sender, revisions[0].Sender)
+	require.Equal(t, []byte("first"), []byte(revisions[0].Payload))
+
+	require.Equal(t, "update", revisions[1].Type)
+	require.Equal(t, updateTx.Hash(), revisions[1].TxHash)
+	require.Equal(t, []byte("second"), []byte(revisions[1].Payload))
+	require.Equal(t, "2", revisions[1].StringAnnotations["version"])
+
+	require.Equal(t, "extend", revisions[2].Type)
+	require.Equal(t, uint64(5), revisions[2].BTL)
+
+	require.Equal(t, "expire", revisions[3].Type)
+	require.Equal(t, uint64(3), revisions[3].BlockNumber)
+	require.Equal(t, common.Address{}, revisions[3].Sender)
+
+	revisions, err = index.Revisions(entity, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+
+	revisions, err = index.Revisions(other, 0, 4)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, []byte("other"), []byte(revisions[0].Payload))
+}
+
+func TestIndexReorg(t *testing.T) {
+	chain := &testChain{
+		t:      t,
+		db:     rawdb.NewMemoryDatabase(),
+		signer: types.LatestSigner(params.TestChainConfig),
+	}
+	owner := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+	entity := common.HexToHash("0x01")
+	other := common.HexToHash("0x02")
+
+	spec := &core.GenesisArkiv{Entities: []core.GenesisEntity{{Owner: owner, BTL: 100, ContentType: "text/plain", Payload: []byte("genesis")}}}
+	genesisKey := spec.Entities[0].Key(0)
+	blob, err := json.Marshal(spec)
+	require.NoError(t, err)
+	genesis := chain.writeBlock(0, nil, nil)
+	genesisHash := rawdb.ReadCanonicalHash(chain.db, 0)
+	rawdb.WriteArkivGenesisSpec(chain.db, genesisHash, blob)
+	require.Empty(t, genesis.Operations)
+
+	chain.addBlock([]*types.Transaction{chain.arkivTx(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 10, ContentType: "text/plain", Payload: []byte("first")}},
+	})}, []*types.Receipt{receipt(
+		&types.Log{Address: address.ArkivProcessorAddress, Topics: []common.Hash{logs.ArkivEntityCreated, entity}},
+	)})
+	chain.addBlock([]*types.Transaction{chain.arkivTx(&storagetx.ArkivTransaction{
+		Delete: []common.Hash{entity},
+	})}, []*types.Receipt{receipt()})
+
+	// the first batch starts with the genesis entities, as the runner sends it
+	index := NewIndex(rawdb.NewMemoryDatabase(), chain.db, params.TestChainConfig)
+	first := append([]events.Block{*dbevents.GenesisBlock(spec)}, chain.blocks...)
+	require.NoError(t, index.Consume(context.Background(), events.BlockBatch{Blocks: first}))
+
+	revisions, err := index.Revisions(genesisKey, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, "create", revisions[0].Type)
+	require.Equal(t, uint64(0), revisions[0].BlockNumber)
+	require.Equal(t, []byte("genesis"), []byte(revisions[0].Payload))
+
+	revisions, err = index.Revisions(entity, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, "delete", revisions[1].Type)
+
+	// block 2 is replaced, which the event stream doesn't deliver again
+	chain.writeBlock(2, []*types.Transaction{chain.arkivTx(&storagetx.ArkivTransaction{
+		Extend: []storagetx.ExtendBTL{{EntityKey: other, NumberOfBlocks: 5}, {EntityKey: entity, NumberOfBlocks: 5}},
+	})}, []*types.Receipt{receipt()})
+
+	revisions, err = index.Revisions(entity, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, "create", revisions[0].Type)
+	require.Equal(t, "extend", revisions[1].Type)
+	require.Equal(t, uint64(2), revisions[1].BlockNumber)
+
+	// the reorged out entries are dropped, and the next blocks are indexed
+	chain.blocks = chain.blocks[:1]
+	chain.writeBlock(2, []*types.Transaction{chain.arkivTx(&storagetx.ArkivTransaction{
+		Update: []storagetx.ArkivUpdate{
+			{EntityKey: other, BTL: 20, ContentType: "text/plain", Payload: []byte("other")},
+			{EntityKey: entity, BTL: 20, ContentType: "text/plain", Payload: []byte("second")},
+		},
+	})}, []*types.Receipt{receipt()})
+	block := chain.writeBlock(3, []*types.Transaction{chain.arkivTx(&storagetx.ArkivTransaction{
+		Delete: []common.Hash{entity},
+	})}, []*types.Receipt{receipt()})
+	require.NoError(t, index.Consume(context.Background(), events.BlockBatch{Blocks: []events.Block{block}}))
+
+	revisions, err = index.Revisions(entity, 0, 3)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	require.Equal(t, "create", revisions[0].Type)
+	require.Equal(t, "update", revisions[1].Type)
+	require.Equal(t, []byte("second"), []byte(revisions[1].Payload))
+	require.Equal(t, "delete", revisions[2].Type)
+	require.Equal(t, uint64(3), revisions[2].BlockNumber)
+}
@@ -0,0 +1,236 @@
+package pending
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Match reports whether e satisfies the query q, following the semantics of the
+// store: a comparison only matches entities having the compared attribute.
+func Match(q *query.AST, e *Entity) bool {
+	if q.Expr == nil {
+		return true
+	}
+	for _, and := range q.Expr.Or.Terms {
+		if matchAnd(and, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnd(and query.ASTAnd, e *Entity) bool {
+	for _, term := range and.Terms {
+		if !matchTerm(term, e) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchTerm(t query.ASTTerm, e *Entity) bool {
+	switch {
+	case t.Assign != nil:
+		return compare(e, t.Assign.Var, t.Assign.Value, func(c int) bool { return (c == 0) != t.Assign.IsNot })
+	case t.Inclusion != nil:
+		return matchInclusion(t.Inclusion, e)
+	case t.LessThan != nil:
+		return compare(e, t.LessThan.Var, t.LessThan.Value, func(c int) bool { return c < 0 })
+	case t.LessOrEqualThan != nil:
+		return compare(e, t.LessOrEqualThan.Var, t.LessOrEqualThan.Value, func(c int) bool { return c <= 0 })
+	case t.GreaterThan != nil:
+		return compare(e, t.GreaterThan.Var, t.GreaterThan.Value, func(c int) bool { return c > 0 })
+	case t.GreaterOrEqualThan != nil:
+		return compare(e, t.GreaterOrEqualThan.Var, t.GreaterOrEqualThan.Value, func(c int) bool { return c >= 0 })
+	case t.Glob != nil:
+		v, ok := e.StringAttributes[t.Glob.Var]
+		return ok && glob(t.Glob.Value, v) != t.Glob.IsNot
+	default:
+		return false
+	}
+}
+
+// compare compares the attribute name of e with value and passes the result to ok.
+func compare(e *Entity, name string, value query.Value, ok func(int) bool) bool {
+	if value.String != nil {
+		v, has := e.StringAttributes[name]
+		return has && ok(strings.Compare(v, *value.String))
+	}
+	if value.Number != nil {
+		v, has := e.NumericAttributes[name]
+		return has && ok(cmpUint64(v, *value.Number))
+	}
+	return false
+}
+
+func cmpUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func matchInclusion(in *query.Inclusion, e *Entity) bool {
+	if len(in.Values.Strings) != 0 {
+		v, has := e.StringAttributes[in.Var]
+		return has && slices.Contains(in.Values.Strings, v) != in.IsNot
+	}
+	v, has := e.NumericAttributes[in.Var]
+	return has && slices.Contains(in.Values.Numbers, v) != in.IsNot
+}
+
+// glob matches s against pattern with the semantics of the SQLite GLOB operator:
+// '*' matches any sequence, '?' any single character and '[...]' a character
+// class, optionally negated with '^'. Matching is case sensitive.
+func glob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		p, n := utf8.DecodeRuneInString(pattern)
+		switch p {
+		case '*':
+			rest := pattern[n:]
+			for i := 0; i <= len(s); {
+				if glob(rest, s[i:]) {
+					return true
+				}
+				if i == len(s) {
+					break
+				}
+				_, m := utf8.DecodeRuneInString(s[i:])
+				i += m
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			_, m := utf8.DecodeRuneInString(s)
+			s = s[m:]
+			pattern = pattern[n:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			c, m := utf8.DecodeRuneInString(s)
+			matched, rest, ok := matchClass(pattern[n:], c)
+			if !ok || !matched {
+				return false
+			}
+			s = s[m:]
+			pattern = rest
+		default:
+			if len(s) == 0 {
+				return false
+			}
+			c, m := utf8.DecodeRuneInString(s)
+			if c != p {
+				return false
+			}
+			s = s[m:]
+			pattern = pattern[n:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchClass matches c against the character class at the start of pattern
+// (after the opening '['). It returns the remaining pattern after the closing ']'
+// and ok=false if the class is not terminated.
+func matchClass(pattern string, c rune) (matched bool, rest string, ok bool) {
+	negate := false
+	if strings.HasPrefix(pattern, "^") {
+		negate = true
+		pattern = pattern[1:]
+	}
+	first := true
+	for len(pattern) > 0 {
+		lo, n := utf8.DecodeRuneInString(pattern)
+		if lo == ']' && !first {
+			return matched != negate, pattern[n:], true
+		}
+		first = false
+		pattern = pattern[n:]
+		hi := lo
+		if strings.HasPrefix(pattern, "-") && len(pattern) > 1 && pattern[1] != ']' {
+			hi, n = utf8.DecodeRuneInString(pattern[1:])
+			pattern = pattern[1+n:]
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+	}
+	return false, "", false
+}
+
+// QueryResult is an entity in the response of a query, flagged as unconfirmed
+// if it reflects pending transactions.
+type QueryResult struct {
+	*sqlitestore.EntityData
+	Unconfirmed bool `json:"unconfirmed,omitempty"`
+}
+
+// EntityData renders e like the store renders its query results.
+func (e *Entity) EntityData(include sqlitestore.IncludeData) *sqlitestore.EntityData {
+	res := &sqlitestore.EntityData{}
+	if include.Key {
+		res.Key = pointerOf(e.Key)
+	}
+	if include.Payload {
+		res.Value = e.Payload
+	}
+	if include.ContentType {
+		res.ContentType = pointerOf(e.ContentType)
+	}
+
+	if include.Attributes || include.SyntheticAttributes {
+		keep := func(k string) bool {
+			synthetic := strings.HasPrefix(k, "$")
+			return (synthetic && include.SyntheticAttributes) || (!synthetic && include.Attributes)
+		}
+		res.StringAttributes = attributes(keep, e.StringAttributes)
+		res.NumericAttributes = attributes(keep, e.NumericAttributes)
+	}
+
+	if include.Expiration {
+		res.ExpiresAt = pointerOf(e.NumericAttributes["$expiration"])
+	}
+	if include.Owner {
+		res.Owner = pointerOf(common.HexToAddress(e.StringAttributes["$owner"]))
+	}
+	if include.CreatedAtBlock {
+		res.CreatedAtBlock = pointerOf(e.NumericAttributes["$createdAtBlock"])
+	}
+	if include.LastModifiedAtBlock {
+		res.LastModifiedAtBlock = pointerOf(e.NumericAttributes["$lastModifiedAtBlock"])
+	}
+	if include.TransactionIndexInBlock {
+		res.TransactionIndexInBlock = pointerOf(e.NumericAttributes["$txIndex"])
+	}
+	if include.OperationIndexInTransaction {
+		res.OperationIndexInTransaction = pointerOf(e.NumericAttributes["$opIndex"])
+	}
+	return res
+}
+
+func attributes[T any](keep func(string) bool, m map[string]T) []sqlitestore.Attribute[T] {
+	res := []sqlitestore.Attribute[T]{}
+	for k, v := range m {
+		if keep(k) {
+			res = append(res, sqlitestore.Attribute[T]{Key: k, Value: v})
+		}
+	}
+	slices.SortFunc(res, func(a, b sqlitestore.Attribute[T]) int { return strings.Compare(a.Key, b.Key) })
+	return res
+}
+
+func pointerOf[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,117 @@
+package pending
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+	"github.com/Arkiv-Network/arkiv-events/events"
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchLikeStore runs the same queries against the store and Match, which
+// has to select the same entities as the store evaluator.
+func TestMatchLikeStore(t *testing.T) {
+	store, err := sqlitestore.NewSQLiteStore(slog.New(slog.DiscardHandler), filepath.Join(t.TempDir(), "store.db"), 1)
+	require.NoError(t, err)
+	defer store.Close()
+
+	creates := []*events.OPCreate{
+		{Owner: alice, StringAttributes: map[string]string{"type": "note", "name": "apple"}, NumericAttributes: map[string]uint64{"rank": 1}},
+		{Owner: alice, StringAttributes: map[string]string{"type": "note", "name": "Banana"}, NumericAttributes: map[string]uint64{"rank": 5}},
+		{Owner: bob, StringAttributes: map[string]string{"type": "todo", "name": "cherry pie"}, NumericAttributes: map[string]uint64{"rank": 10, "done": 1}},
+		{Owner: bob, StringAttributes: map[string]string{"type": "todo"}, NumericAttributes: map[string]uint64{"done": 0}},
+		{Owner: bob, StringAttributes: map[string]string{"name": "[x]"}, NumericAttributes: map[string]uint64{}},
+	}
+	block := events.Block{Number: 1}
+	for i, create := range creates {
+		create.Key = common.BigToHash(big.NewInt(int64(i + 1)))
+		create.ContentType = "text/plain"
+		create.Content = []byte("content")
+		create.BTL = 100
+		block.Operations = append(block.Operations, events.Operation{OpIndex: uint64(i), Create: create})
+	}
+	batches := arkivevents.BatchIterator(func(yield func(arkivevents.BatchOrError) bool) {
+		yield(arkivevents.BatchOrError{Batch: events.BlockBatch{Blocks: []events.Block{block}}})
+	})
+	require.NoError(t, store.FollowEvents(context.Background(), batches))
+
+	entities := queryStore(t, store, "$all")
+	require.Len(t, entities, len(creates))
+
+	queries := []string{
+		`type = "note"`,
+		`type != "note"`,
+		`rank < 5`,
+		`rank <= 5`,
+		`rank > 5`,
+		`rank >= 5`,
+		`rank != 5`,
+		`done = 0`,
+		`name ~ "*an*"`,
+		`name !~ "*an*"`,
+		`name glob "[a-c]*"`,
+		`name ~ "?????"`,
+		`name ~ "[[]x]"`,
+		`type in ("note" "todo")`,
+		`type not in ("note")`,
+		`rank in (1 10)`,
+		`type = "note" && rank > 1`,
+		`type = "note" || done = 1`,
+		`!(type = "note" || rank > 5)`,
+		`(type = "todo" && done = 1) || name = "apple"`,
+		`$owner = "` + alice.Hex() + `"`,
+		`$owner = ` + bob.Hex(),
+		`$creator != ` + bob.Hex(),
+		`$key = ` + creates[2].Key.Hex(),
+		`$expiration = 101`,
+		`$all`,
+	}
+	for _, q := range queries {
+		want := []common.Hash{}
+		for _, e := range queryStore(t, store, q) {
+			want = append(want, e.Key)
+		}
+
+		ast, err := query.Parse(q)
+		require.NoError(t, err, q)
+		have := []common.Hash{}
+		for _, e := range entities {
+			if Match(ast, e) {
+				have = append(have, e.Key)
+			}
+		}
+
+		slices.SortFunc(want, func(a, b common.Hash) int { return a.Cmp(b) })
+		slices.SortFunc(have, func(a, b common.Hash) int { return a.Cmp(b) })
+		require.Equal(t, want, have, q)
+	}
+}
+
+func queryStore(t *testing.T, store *sqlitestore.SQLiteStore, q string) []*Entity {
+	res, err := store.QueryEntities(context.Background(), q, &sqlitestore.Options{
+		IncludeData: &sqlitestore.IncludeData{
+			Key:                 true,
+			Attributes:          true,
+			SyntheticAttributes: true,
+			ContentType:         true,
+			Owner:               true,
+		},
+	})
+	require.NoError(t, err, q)
+	entities := []*Entity{}
+	for _, data := range res.Data {
+		ed := &sqlitestore.EntityData{}
+		require.NoError(t, json.Unmarshal(data, ed))
+		entities = append(entities, FromEntityData(ed))
+	}
+	return entities
+}
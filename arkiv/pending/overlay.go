@@ -0,0 +1,249 @@
+// Package pending computes the state of Arkiv entities as it would be after the
+// transactions currently pending in the transaction pool are mined, so that
+// queries can be answered optimistically.
+package pending
+
+import (
+	"fmt"
+	"strings"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+)
+
+// Entity is an entity as it would be after the pending transactions are mined.
+// Attributes include the synthetic ones ($key, $owner, $creator, $expiration, ...)
+// the store would index.
+type Entity struct {
+	Key               common.Hash
+	Owner             common.Address
+	ContentType       string
+	Payload           []byte
+	StringAttributes  map[string]string
+	NumericAttributes map[string]uint64
+}
+
+func (e *Entity) copy() *Entity {
+	c := *e
+	c.StringAttributes = make(map[string]string, len(e.StringAttributes))
+	for k, v := range e.StringAttributes {
+		c.StringAttributes[k] = v
+	}
+	c.NumericAttributes = make(map[string]uint64, len(e.NumericAttributes))
+	for k, v := range e.NumericAttributes {
+		c.NumericAttributes[k] = v
+	}
+	return &c
+}
+
+// Lookup returns the confirmed entity with the given key, or nil if it does not exist.
+type Lookup func(key common.Hash) (*Entity, error)
+
+// Overlay accumulates the operations of pending transactions on top of the
+// confirmed entities.
+type Overlay struct {
+	block   uint64
+	lookup  Lookup
+	touched map[common.Hash]*Entity // nil for deleted entities
+	order   []common.Hash
+}
+
+// NewOverlay creates an overlay for transactions to be included in the block
+// following head. Entities not touched by pending transactions are resolved
+// with lookup.
+func NewOverlay(head uint64, lookup Lookup) *Overlay {
+	return &Overlay{
+		block:   head + 1,
+		lookup:  lookup,
+		touched: make(map[common.Hash]*Entity),
+	}
+}
+
+// Apply adds the operations of a pending Arkiv transaction to the overlay.
+// Like on chain, the transaction is applied atomically: if any of its operations
+// would fail, none of them is applied and an error is returned.
+func (o *Overlay) Apply(txHash common.Hash, sender common.Address, tx *storagetx.ArkivTransaction) error {
+	staged := make(map[common.Hash]*Entity)
+	order := []common.Hash{}
+
+	stage := func(key common.Hash, e *Entity) {
+		if _, seen := staged[key]; !seen {
+			order = append(order, key)
+		}
+		staged[key] = e
+	}
+
+	get := func(key common.Hash) (*Entity, error) {
+		if e, ok := staged[key]; ok {
+			return e, nil
+		}
+		if e, ok := o.touched[key]; ok {
+			return e, nil
+		}
+		return o.lookup(key)
+	}
+
+	owned := func(key common.Hash) (*Entity, error) {
+		e, err := get(key)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			return nil, fmt.Errorf("entity %s not found", key.Hex())
+		}
+		if e.Owner != sender {
+			return nil, fmt.Errorf("%s is not the owner of entity %s", sender.Hex(), key.Hex())
+		}
+		return e, nil
+	}
+
+	// Operations are applied in the same order as in storagetx.ArkivTransaction.Run
+	for opIx, create := range tx.Create {
+		key := storagetx.EntityKey(txHash, create.Payload, opIx)
+		e := &Entity{
+			Key:               key,
+			Owner:             sender,
+			ContentType:       create.ContentType,
+			Payload:           create.Payload,
+			StringAttributes:  make(map[string]string),
+			NumericAttributes: make(map[string]uint64),
+		}
+		setAnnotations(e, create.Annotations())
+		e.StringAttributes["$creator"] = strings.ToLower(sender.Hex())
+		e.NumericAttributes["$expiration"] = o.block + create.BTL
+		e.NumericAttributes["$createdAtBlock"] = o.block
+		e.NumericAttributes["$lastModifiedAtBlock"] = o.block
+		stage(key, e)
+	}
+
+	for _, key := range tx.Delete {
+		if _, err := owned(key); err != nil {
+			return err
+		}
+		stage(key, nil)
+	}
+
+	for _, update := range tx.Update {
+		old, err := owned(update.EntityKey)
+		if err != nil {
+			return err
+		}
+		e := &Entity{
+			Key:               update.EntityKey,
+			Owner:             old.Owner,
+			ContentType:       update.ContentType,
+			Payload:           update.Payload,
+			StringAttributes:  make(map[string]string),
+			NumericAttributes: make(map[string]uint64),
+		}
+		setAnnotations(e, update.Annotations())
+		// Keep the synthetic attributes describing the creation of the entity
+		for k, v := range old.StringAttributes {
+			if k == "$creator" {
+				e.StringAttributes[k] = v
+			}
+		}
+		for k, v := range old.NumericAttributes {
+			switch k {
+			case "$createdAtBlock", "$sequence", "$txIndex", "$opIndex":
+				e.NumericAttributes[k] = v
+			}
+		}
+		e.NumericAttributes["$expiration"] = o.block + update.BTL
+		e.NumericAttributes["$lastModifiedAtBlock"] = o.block
+		stage(update.EntityKey, e)
+	}
+
+	for _, extend := range tx.Extend {
+		old, err := get(extend.EntityKey)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return fmt.Errorf("entity %s not found", extend.EntityKey.Hex())
+		}
+		e := old.copy()
+		e.NumericAttributes["$expiration"] += extend.NumberOfBlocks
+		stage(extend.EntityKey, e)
+	}
+
+	for _, changeOwner := range tx.ChangeOwner {
+		old, err := owned(changeOwner.EntityKey)
+		if err != nil {
+			return err
+		}
+		e := old.copy()
+		e.Owner = changeOwner.NewOwner
+		stage(changeOwner.EntityKey, e)
+	}
+
+	for _, key := range order {
+		e := staged[key]
+		if e != nil {
+			e.StringAttributes["$key"] = strings.ToLower(key.Hex())
+			e.StringAttributes["$owner"] = strings.ToLower(e.Owner.Hex())
+		}
+		if _, seen := o.touched[key]; !seen {
+			o.order = append(o.order, key)
+		}
+		o.touched[key] = e
+	}
+
+	return nil
+}
+
+func setAnnotations(e *Entity, annotations storagetx.Annotations) {
+	e.StringAttributes, e.NumericAttributes = annotations.Attributes()
+}
+
+// Touched reports whether the entity with the given key is created, modified
+// or deleted by pending transactions.
+func (o *Overlay) Touched(key common.Hash) bool {
+	_, ok := o.touched[key]
+	return ok
+}
+
+// Keys returns the keys of the entities created, modified or deleted by pending
+// transactions, in the order they were first touched.
+func (o *Overlay) Keys() []common.Hash {
+	return o.order
+}
+
+// Entities returns the entities created or modified by pending transactions,
+// in the order they were first touched. Deleted entities are not included.
+func (o *Overlay) Entities() []*Entity {
+	entities := make([]*Entity, 0, len(o.order))
+	for _, key := range o.order {
+		if e := o.touched[key]; e != nil {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// FromEntityData converts an entity returned by the store, queried with all
+// attributes including the synthetic ones, into an Entity.
+func FromEntityData(d *sqlitestore.EntityData) *Entity {
+	e := &Entity{
+		StringAttributes:  make(map[string]string, len(d.StringAttributes)),
+		NumericAttributes: make(map[string]uint64, len(d.NumericAttributes)),
+		Payload:           d.Value,
+	}
+	if d.Key != nil {
+		e.Key = *d.Key
+	}
+	if d.Owner != nil {
+		e.Owner = *d.Owner
+	}
+	if d.ContentType != nil {
+		e.ContentType = *d.ContentType
+	}
+	for _, a := range d.StringAttributes {
+		e.StringAttributes[a.Key] = a.Value
+	}
+	for _, a := range d.NumericAttributes {
+		e.NumericAttributes[a.Key] = a.Value
+	}
+	return e
+}
@@ -0,0 +1,119 @@
+package pending
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	alice = common.HexToAddress("0x000000000000000000000000000000000000a11c")
+	bob   = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+)
+
+func confirmed(key common.Hash, owner common.Address) *Entity {
+	return &Entity{
+		Key:         key,
+		Owner:       owner,
+		ContentType: "text/plain",
+		Payload:     []byte("confirmed"),
+		StringAttributes: map[string]string{
+			"type":     "note",
+			"$key":     strings.ToLower(key.Hex()),
+			"$owner":   strings.ToLower(owner.Hex()),
+			"$creator": strings.ToLower(owner.Hex()),
+		},
+		NumericAttributes: map[string]uint64{
+			"$expiration":     50,
+			"$createdAtBlock": 1,
+		},
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	existing := common.HexToHash("0x01")
+	other := common.HexToHash("0x02")
+
+	store := map[common.Hash]*Entity{
+		existing: confirmed(existing, alice),
+		other:    confirmed(other, bob),
+	}
+	overlay := NewOverlay(10, func(key common.Hash) (*Entity, error) {
+		return store[key], nil
+	})
+
+	txHash := common.HexToHash("0xaa")
+	require.NoError(t, overlay.Apply(txHash, alice, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:               100,
+			ContentType:       "text/plain",
+			Payload:           []byte("new"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "note"}},
+		}},
+		Extend: []storagetx.ExtendBTL{{EntityKey: existing, NumberOfBlocks: 5}},
+	}))
+
+	// Not the owner, the whole transaction is skipped
+	require.Error(t, overlay.Apply(common.HexToHash("0xbb"), alice, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 1, ContentType: "text/plain"}},
+		Delete: []common.Hash{other},
+	}))
+
+	require.NoError(t, overlay.Apply(common.HexToHash("0xcc"), bob, &storagetx.ArkivTransaction{
+		Delete: []common.Hash{other},
+	}))
+
+	created := storagetx.EntityKey(txHash, []byte("new"), 0)
+	require.True(t, overlay.Touched(created))
+	require.True(t, overlay.Touched(existing))
+	require.True(t, overlay.Touched(other))
+
+	entities := overlay.Entities()
+	require.Len(t, entities, 2)
+
+	require.Equal(t, created, entities[0].Key)
+	require.Equal(t, uint64(111), entities[0].NumericAttributes["$expiration"])
+	require.Equal(t, strings.ToLower(alice.Hex()), entities[0].StringAttributes["$owner"])
+
+	require.Equal(t, existing, entities[1].Key)
+	require.Equal(t, uint64(55), entities[1].NumericAttributes["$expiration"])
+	require.Equal(t, uint64(50), store[existing].NumericAttributes["$expiration"])
+
+	q, err := query.Parse(`type = "note" && $expiration = 111`)
+	require.NoError(t, err)
+	require.True(t, Match(q, entities[0]))
+	require.False(t, Match(q, entities[1]))
+}
+
+func TestMatch(t *testing.T) {
+	e := confirmed(common.HexToHash("0x01"), alice)
+	e.StringAttributes["name"] = "hello world"
+	e.NumericAttributes["size"] = 7
+
+	for _, tt := range []struct {
+		query string
+		match bool
+	}{
+		{`$all`, true},
+		{`name = "hello world"`, true},
+		{`name != "hello world"`, false},
+		{`missing != "x"`, false},
+		{`name ~ "hello*"`, true},
+		{`name ~ "h?llo [a-w]orld"`, true},
+		{`name ~ "h?llo [^w]orld"`, false},
+		{`name !~ "*world"`, false},
+		{`size >= 7 && size < 8`, true},
+		{`size in (1 2 3) || name in ("hello world")`, true},
+		{`size not in (7)`, false},
+		{`!(size = 7)`, false},
+		{`$owner = ` + alice.Hex(), true},
+	} {
+		q, err := query.Parse(tt.query)
+		require.NoError(t, err, tt.query)
+		require.Equal(t, tt.match, Match(q, e), tt.query)
+	}
+}
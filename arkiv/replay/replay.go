@@ -0,0 +1,331 @@
+// Package replay re-executes the Arkiv operations of historical blocks on top
+// of their parent state, and compares the logs, the storage slots of the Arkiv
+// processor and the resulting state root with what the chain stored.
+//
+// Arkiv transactions and the housekeeping of deposit transactions are replayed
+// with storagetx.ExecuteArkivTransaction and housekeepingtx.ExecuteTransaction.
+// Calls of contracts to the processor are replayed from their marker logs, with
+// the call index and the value they carry.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/housekeepingtx"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Kinds of divergences.
+const (
+	DivergenceStatus = "status"
+	DivergenceLogs   = "logs"
+	DivergenceSlot   = "slot"
+	DivergenceRoot   = "root"
+)
+
+// Divergence describes where the replay of a block differs from the chain.
+type Divergence struct {
+	Block   uint64      `json:"block"`
+	TxIndex int         `json:"txIndex"` // -1 if the divergence is not tied to a transaction
+	TxHash  common.Hash `json:"txHash"`
+	Kind    string      `json:"kind"`
+	Detail  string      `json:"detail"`
+
+	// Operation is the decoded Arkiv operation of the transaction.
+	Operation string `json:"operation,omitempty"`
+}
+
+func (d *Divergence) String() string {
+	var b strings.Builder
+	if d.TxIndex < 0 {
+		fmt.Fprintf(&b, "block %d: %s diverges: %s", d.Block, d.Kind, d.Detail)
+	} else {
+		fmt.Fprintf(&b, "block %d tx %d (%s): %s diverges: %s", d.Block, d.TxIndex, d.TxHash.Hex(), d.Kind, d.Detail)
+	}
+	if d.Operation != "" {
+		fmt.Fprintf(&b, "\noperation: %s", d.Operation)
+	}
+	return b.String()
+}
+
+// Result is the outcome of the replay of a block.
+type Result struct {
+	Block       uint64      `json:"block"`
+	ArkivTxs    int         `json:"arkivTxs"`
+	Logs        int         `json:"logs"`
+	SlotChanges int         `json:"slotChanges"`
+	Divergence  *Divergence `json:"divergence,omitempty"`
+}
+
+// replayedTx is the outcome of the replay of the Arkiv operations of a transaction.
+type replayedTx struct {
+	index     int
+	tx        *types.Transaction
+	operation string
+	err       error
+	logs      []*types.Log
+}
+
+// Block replays the Arkiv operations of the block with the given number. The
+// state of its parent has to be available, so replaying old blocks needs an
+// archive node.
+func Block(chain *core.BlockChain, number uint64) (*Result, error) {
+	if number == 0 {
+		return nil, fmt.Errorf("the genesis block can't be replayed")
+	}
+	block := chain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	parent := chain.GetHeader(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block %d not found", number)
+	}
+	parentState, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d is not available: %w", number-1, err)
+	}
+	storedState, err := chain.StateAt(block.Root())
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d is not available: %w", number, err)
+	}
+	receipts := chain.GetReceiptsByHash(block.Hash())
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("receipts of block %d not found", number)
+	}
+
+	// Replay the Arkiv operations, recording which transaction wrote a slot of
+	// the processor last.
+	var (
+		arkivState = parentState.Copy()
+		current    int
+		writers    = make(map[common.Hash]int)
+	)
+	hooked := state.NewHookedState(arkivState, &tracing.Hooks{
+		OnStorageChange: func(addr common.Address, slot common.Hash, prev, new common.Hash) {
+			if addr == address.ArkivProcessorAddress {
+				writers[slot] = current
+			}
+		},
+	})
+
+	signer := types.MakeSigner(chain.Config(), block.Number(), block.Time())
+	rules := chain.Config().Rules(block.Number(), block.Difficulty().Sign() == 0, block.Time())
+	result := &Result{Block: number}
+
+	var replayed []replayedTx
+	for i, tx := range block.Transactions() {
+		current = i
+		r, ok, err := replayTx(hooked, rules, signer, number, i, tx, receipts[i])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			replayed = append(replayed, r)
+			result.ArkivTxs++
+			result.Logs += len(r.logs)
+		}
+	}
+	result.SlotChanges = len(writers)
+
+	// The first divergence of a transaction, either in its outcome or in the
+	// slots it wrote last, is reported.
+	for _, r := range replayed {
+		if d := compareTx(number, r, receipts[r.index]); d != nil {
+			result.Divergence = d
+			break
+		}
+	}
+	slots := slices.Collect(maps.Keys(writers))
+	slices.SortFunc(slots, func(a, b common.Hash) int {
+		if writers[a] != writers[b] {
+			return writers[a] - writers[b]
+		}
+		return a.Cmp(b)
+	})
+	for _, slot := range slots {
+		txIndex := writers[slot]
+		if result.Divergence != nil && result.Divergence.TxIndex <= txIndex {
+			break
+		}
+		replayedValue := arkivState.GetState(address.ArkivProcessorAddress, slot)
+		storedValue := storedState.GetState(address.ArkivProcessorAddress, slot)
+		if replayedValue != storedValue {
+			result.Divergence = &Divergence{
+				Block:     number,
+				TxIndex:   txIndex,
+				TxHash:    block.Transactions()[txIndex].Hash(),
+				Kind:      DivergenceSlot,
+				Detail:    fmt.Sprintf("slot %s is %s after replay, stored %s", slot.Hex(), replayedValue.Hex(), storedValue.Hex()),
+				Operation: operationOf(replayed, txIndex),
+			}
+			break
+		}
+	}
+	if result.Divergence != nil {
+		return result, nil
+	}
+
+	// Execute the whole block to compare the state root, which also covers the
+	// slots that changed in the chain but not in the replay.
+	fullState := parentState.Copy()
+	if _, err := chain.Processor().Process(block, fullState, vm.Config{}); err != nil {
+		result.Divergence = &Divergence{Block: number, TxIndex: -1, Kind: DivergenceRoot, Detail: fmt.Sprintf("block execution failed: %v", err)}
+		return result, nil
+	}
+	root := fullState.IntermediateRoot(chain.Config().IsEIP158(block.Number()))
+	if root != block.Root() {
+		detail := fmt.Sprintf("state root is %s after execution, stored %s", root.Hex(), block.Root().Hex())
+		replayedStorage := fullState.GetStorageRoot(address.ArkivProcessorAddress)
+		storedStorage := storedState.GetStorageRoot(address.ArkivProcessorAddress)
+		if replayedStorage != storedStorage {
+			detail += fmt.Sprintf(", processor storage root is %s, stored %s", replayedStorage.Hex(), storedStorage.Hex())
+		}
+		result.Divergence = &Divergence{Block: number, TxIndex: -1, Kind: DivergenceRoot, Detail: detail}
+	}
+	return result, nil
+}
+
+// replayTx replays the Arkiv operations of a transaction. It reports false if
+// the transaction has no Arkiv operations.
+func replayTx(db vm.StateDB, rules params.Rules, signer types.Signer, number uint64, index int, tx *types.Transaction, receipt *types.Receipt) (replayedTx, bool, error) {
+	r := replayedTx{index: index, tx: tx}
+
+	switch {
+	case tx.IsDepositTx():
+		r.operation = fmt.Sprintf("housekeeping of block %d", number)
+		r.logs, r.err = housekeepingtx.ExecuteTransaction(rules, number, tx.Hash(), db)
+		return r, true, nil
+
+	case tx.To() != nil && *tx.To() == address.ArkivProcessorAddress:
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return r, false, fmt.Errorf("invalid sender of tx %d: %w", index, err)
+		}
+		r.operation = decodeOperation(sender, tx.Data())
+		r.logs, r.err = storagetx.ExecuteArkivTransaction(tx.Data(), rules, number, tx.Hash(), index, sender, uint256.MustFromBig(tx.Value()), db)
+		return r, true, nil
+	}
+
+	// Contract calls to the processor, each marked by a log with the caller,
+	// the index and the value of the call and the call data. Calls that failed
+	// or were reverted have no log, but count in the index.
+	var operations []string
+	calls := 0
+	for _, l := range receipt.Logs {
+		if l.Address != address.ArkivProcessorAddress || len(l.Topics) < 4 || l.Topics[0] != arkivlogs.ArkivContractCall {
+			continue
+		}
+		caller := common.BytesToAddress(l.Topics[1].Bytes())
+		value := new(uint256.Int).SetBytes32(l.Topics[3][:])
+		calls++
+
+		operations = append(operations, decodeOperation(caller, l.Data))
+		logs, err := storagetx.ExecuteArkivTransaction(l.Data, rules, number, vm.ArkivCallHash(tx.Hash(), l.Topics[2]), 0, caller, value, db)
+		if err != nil {
+			r.err = err
+			break
+		}
+		r.logs = append(r.logs, logs...)
+	}
+	if calls == 0 {
+		return r, false, nil
+	}
+	r.operation = strings.Join(operations, "\n")
+	return r, true, nil
+}
+
+// compareTx compares the outcome of a replayed transaction with its receipt.
+func compareTx(number uint64, r replayedTx, receipt *types.Receipt) *Divergence {
+	d := &Divergence{Block: number, TxIndex: r.index, TxHash: r.tx.Hash(), Operation: r.operation}
+
+	failed := receipt.Status == types.ReceiptStatusFailed
+	switch {
+	case r.tx.IsDepositTx() && r.err != nil:
+		d.Kind, d.Detail = DivergenceStatus, fmt.Sprintf("housekeeping failed: %v", r.err)
+		return d
+	case r.err != nil && !failed:
+		d.Kind, d.Detail = DivergenceStatus, fmt.Sprintf("replay failed, stored receipt is successful: %v", r.err)
+		return d
+	case r.err == nil && failed:
+		d.Kind, d.Detail = DivergenceStatus, "replay succeeded, stored receipt is failed"
+		return d
+	case failed:
+		return nil
+	}
+
+	var stored []*types.Log
+	for _, l := range receipt.Logs {
+		if l.Address == address.ArkivProcessorAddress && (len(l.Topics) == 0 || l.Topics[0] != arkivlogs.ArkivContractCall) {
+			stored = append(stored, l)
+		}
+	}
+	if len(stored) != len(r.logs) {
+		d.Kind, d.Detail = DivergenceLogs, fmt.Sprintf("replay produced %d logs, stored %d", len(r.logs), len(stored))
+		return d
+	}
+	for i := range stored {
+		if !sameLog(stored[i], r.logs[i]) {
+			d.Kind, d.Detail = DivergenceLogs, fmt.Sprintf("log %d is %s after replay, stored %s", i, formatLog(r.logs[i]), formatLog(stored[i]))
+			return d
+		}
+	}
+	return nil
+}
+
+func sameLog(a, b *types.Log) bool {
+	if a.Address != b.Address || len(a.Topics) != len(b.Topics) || !bytes.Equal(a.Data, b.Data) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatLog(l *types.Log) string {
+	topics := make([]string, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = topic.Hex()
+	}
+	return fmt.Sprintf("{topics: [%s], data: %x}", strings.Join(topics, " "), l.Data)
+}
+
+// decodeOperation renders the Arkiv transaction in data as JSON.
+func decodeOperation(sender common.Address, data []byte) string {
+	atx, err := storagetx.UnpackArkivTransaction(data)
+	if err != nil {
+		return fmt.Sprintf("undecodable arkiv transaction from %s: %v", sender.Hex(), err)
+	}
+	encoded, err := json.Marshal(atx)
+	if err != nil {
+		return fmt.Sprintf("arkiv transaction from %s: %v", sender.Hex(), err)
+	}
+	return fmt.Sprintf("arkiv transaction from %s: %s", sender.Hex(), encoded)
+}
+
+func operationOf(replayed []replayedTx, index int) string {
+	for _, r := range replayed {
+		if r.index == index {
+			return r.operation
+		}
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package replay
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	other, _ := crypto.GenerateKey()
+
+	// The processor account has a nonce, as created by the housekeeping on a
+	// real chain, so that it isn't removed as an empty account.
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			address.ArkivProcessorAddress:           {Nonce: 1, Balance: new(big.Int)},
+			sender:                                  {Balance: big.NewInt(params.Ether)},
+			crypto.PubkeyToAddress(other.PublicKey): {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	signer := types.LatestSigner(gspec.Config)
+
+	nonces := map[common.Address]uint64{}
+	arkivTx := func(t *testing.T, key *ecdsa.PrivateKey, atx *storagetx.ArkivTransaction, baseFee *big.Int) *types.Transaction {
+		encoded, err := rlp.EncodeToBytes(atx)
+		require.NoError(t, err)
+		from := crypto.PubkeyToAddress(key.PublicKey)
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     nonces[from],
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: new(big.Int).Add(baseFee, big.NewInt(1)),
+			Gas:       1_000_000,
+			To:        &address.ArkivProcessorAddress,
+			Data:      compression.MustBrotliCompress(encoded),
+		})
+		require.NoError(t, err)
+		nonces[from]++
+		return tx
+	}
+
+	var created []common.Hash
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, func(i int, gen *core.BlockGen) {
+		switch i {
+		case 0:
+			tx := arkivTx(t, key, &storagetx.ArkivTransaction{
+				Create: []storagetx.ArkivCreate{
+					{BTL: 100, ContentType: "text/plain", Payload: []byte("first")},
+					{BTL: 100, ContentType: "text/plain", Payload: []byte("second")},
+				},
+			}, gen.BaseFee())
+			gen.AddTx(tx)
+			created = append(created, storagetx.EntityKey(tx.Hash(), []byte("first"), 0), storagetx.EntityKey(tx.Hash(), []byte("second"), 1))
+		case 1:
+			gen.AddTx(arkivTx(t, key, &storagetx.ArkivTransaction{
+				Update: []storagetx.ArkivUpdate{{EntityKey: created[0], BTL: 50, ContentType: "text/plain", Payload: []byte("updated")}},
+				Extend: []storagetx.ExtendBTL{{EntityKey: created[1], NumberOfBlocks: 10}},
+			}, gen.BaseFee()))
+		case 2:
+			gen.AddTx(arkivTx(t, key, &storagetx.ArkivTransaction{Delete: []common.Hash{created[0]}}, gen.BaseFee()))
+			// Not the owner, so the transaction fails
+			gen.AddTx(arkivTx(t, other, &storagetx.ArkivTransaction{Delete: []common.Hash{created[1]}}, gen.BaseFee()))
+		}
+	})
+
+	chainDb := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(chainDb, gspec, ethash.NewFaker(), core.DefaultConfig().WithArchive(true))
+	require.NoError(t, err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	for _, number := range []uint64{1, 3} {
+		result, err := Block(chain, number)
+		require.NoError(t, err)
+		require.Nil(t, result.Divergence, "block %d: %v", number, result.Divergence)
+	}
+
+	result, err := Block(chain, 3)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ArkivTxs)
+	require.Equal(t, 1, result.Logs)
+
+	// Tamper with the stored log of the update, which the replay has to spot
+	block := blocks[1]
+	receipts := rawdb.ReadRawReceipts(chainDb, block.Hash(), block.NumberU64())
+	receipts[0].Logs[0].Data[0] ^= 0xff
+	rawdb.WriteReceipts(chainDb, block.Hash(), block.NumberU64(), receipts)
+
+	result, err = Block(chain, 2)
+	require.NoError(t, err)
+	require.NotNil(t, result.Divergence)
+	require.Equal(t, DivergenceLogs, result.Divergence.Kind)
+	require.Equal(t, 0, result.Divergence.TxIndex)
+	require.Equal(t, block.Transactions()[0].Hash(), result.Divergence.TxHash)
+	require.Contains(t, result.Divergence.Operation, `"payload":"dXBkYXRlZA=="`)
+
+	_, err = Block(chain, 0)
+	require.Error(t, err)
+}
+
+func TestReplayContractCalls(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	// The caller takes the length of a first Arkiv transaction as a 32 byte
+	// word, followed by that transaction and a second one, and passes each of
+	// them to the processor, ignoring the outcome of the calls.
+	caller := common.Address{0xca}
+	code := []byte{
+		0x36, 0x60, 0x00, 0x60, 0x00, 0x37, // CALLDATACOPY(0, 0, CALLDATASIZE)
+		0x60, 0x00, 0x60, 0x00, // retSize, retOffset
+		0x60, 0x00, 0x51, // argsSize: MLOAD(0)
+		0x60, 0x20, // argsOffset: 32
+		0x60, 0x00, // value
+		0x73, // PUSH20
+	}
+	code = append(code, address.ArkivProcessorAddress.Bytes()...)
+	code = append(code,
+		0x5a, 0xf1, 0x50, // GAS, CALL, POP
+		0x60, 0x00, 0x60, 0x00, // retSize, retOffset
+		0x60, 0x00, 0x51, 0x60, 0x20, 0x01, 0x36, 0x03, // argsSize: CALLDATASIZE - (MLOAD(0) + 32)
+		0x60, 0x00, 0x51, 0x60, 0x20, 0x01, // argsOffset: MLOAD(0) + 32
+		0x60, 0x00, // value
+		0x73, // PUSH20
+	)
+	code = append(code, address.ArkivProcessorAddress.Bytes()...)
+	code = append(code, 0x5a, 0xf1, 0x50, 0x00) // GAS, CALL, POP, STOP
+
+	config := *params.MergedTestChainConfig
+	config.ArkivContractCallsTime = new(uint64)
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			address.ArkivProcessorAddress: {Nonce: 1, Balance: new(big.Int)},
+			sender:                        {Balance: big.NewInt(params.Ether)},
+			caller:                        {Code: code},
+		},
+	}
+	signer := types.LatestSigner(gspec.Config)
+	pack := func(atx *storagetx.ArkivTransaction) []byte {
+		encoded, err := rlp.EncodeToBytes(atx)
+		require.NoError(t, err)
+		return compression.MustBrotliCompress(encoded)
+	}
+
+	// The first call deletes an entity the caller doesn't own, so it fails
+	// without a marker log, and the second one creates an entity.
+	failing := pack(&storagetx.ArkivTransaction{Delete: []common.Hash{{0x01}}})
+	data := common.BigToHash(big.NewInt(int64(len(failing)))).Bytes()
+	data = append(data, failing...)
+	data = append(data, pack(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 100, ContentType: "text/plain", Payload: []byte("created")}},
+	})...)
+
+	engine := beacon.New(ethash.NewFaker())
+	_, blocks, receipts := core.GenerateChainWithGenesis(gspec, engine, 1, func(i int, gen *core.BlockGen) {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: new(big.Int).Add(gen.BaseFee(), big.NewInt(1)),
+			Gas:       1_000_000,
+			To:        &caller,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		gen.AddTx(tx)
+	})
+	logs := receipts[0][0].Logs
+	require.Len(t, logs, 2)
+	require.Equal(t, arkivlogs.ArkivContractCall, logs[0].Topics[0])
+	require.Equal(t, common.BigToHash(big.NewInt(1)), logs[0].Topics[2])
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, core.DefaultConfig().WithArchive(true))
+	require.NoError(t, err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	result, err := Block(chain, 1)
+	require.NoError(t, err)
+	require.Nil(t, result.Divergence, "%v", result.Divergence)
+	require.Equal(t, 1, result.ArkivTxs)
+	require.Equal(t, 1, result.Logs)
+}
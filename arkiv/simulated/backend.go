@@ -0,0 +1,274 @@
+// Package simulated provides an in-process Arkiv chain for unit tests, in the
+// spirit of ethclient/simulated.
+//
+// The chain runs in dev mode, so every block carries the housekeeping
+// transaction that expires entities, and its Arkiv store follows the chain like
+// on a real node. The store is kept in memory.
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// storeTimeout is how long Commit waits for the Arkiv store to ingest a block.
+const storeTimeout = 10 * time.Second
+
+// Backend is a simulated Arkiv chain.
+type Backend struct {
+	node   *node.Node
+	eth    *eth.Ethereum
+	beacon *catalyst.SimulatedBeacon
+	rpc    *rpc.Client
+	client *ethclient.Client
+}
+
+// NewBackend creates a simulated Arkiv chain with the given accounts. Options
+// can modify the node and Ethereum configurations before the chain is started.
+//
+// A simulated backend always uses chainID 1337.
+func NewBackend(alloc types.GenesisAlloc, options ...func(nodeConf *node.Config, ethConf *ethconfig.Config)) (*Backend, error) {
+	nodeConf := node.DefaultConfig
+	nodeConf.DataDir = ""
+	nodeConf.P2P = p2p.Config{NoDiscovery: true}
+
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = &core.Genesis{
+		Config:   params.AllDevChainProtocolChanges,
+		GasLimit: ethconfig.Defaults.Miner.GasCeil,
+		Alloc:    alloc,
+	}
+	ethConf.SyncMode = ethconfig.FullSync
+	ethConf.TxPool.NoLocals = true
+	ethConf.Miner.DevMode = true
+
+	for _, option := range options {
+		option(&nodeConf, &ethConf)
+	}
+
+	return newWithConfig(&nodeConf, &ethConf)
+}
+
+func newWithConfig(nodeConf *node.Config, ethConf *ethconfig.Config) (*Backend, error) {
+	stack, err := node.New(nodeConf)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := eth.New(stack, ethConf)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "eth",
+		Service:   filters.NewFilterAPI(filterSystem),
+	}})
+	if err := stack.Start(); err != nil {
+		stack.Close()
+		return nil, err
+	}
+	beacon, err := catalyst.NewSimulatedBeacon(0, common.Address{}, backend)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	rpcClient := stack.Attach()
+	return &Backend{
+		node:   stack,
+		eth:    backend,
+		beacon: beacon,
+		rpc:    rpcClient,
+		client: ethclient.NewClient(rpcClient),
+	}, nil
+}
+
+// Close shuts down the backend and releases its store.
+// The backend can't be used afterwards.
+func (b *Backend) Close() error {
+	var err error
+	if b.client != nil {
+		b.client.Close()
+		b.client, b.rpc = nil, nil
+	}
+	if b.beacon != nil {
+		err = b.beacon.Stop()
+		b.beacon = nil
+	}
+	if b.node != nil {
+		err = errors.Join(err, b.node.Close())
+		b.node = nil
+	}
+	return err
+}
+
+// Commit seals a block with the pending transactions and waits until the Arkiv
+// store has ingested it, so that it can be queried right away.
+func (b *Backend) Commit() (common.Hash, error) {
+	hash := b.beacon.Commit()
+	return hash, b.waitForStore()
+}
+
+// AdvanceBlocks seals n empty blocks, e.g. to let entities expire.
+func (b *Backend) AdvanceBlocks(n uint64) error {
+	for range n {
+		if _, err := b.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdjustTime seals an empty block with its timestamp moved forward by
+// adjustment and waits until the Arkiv store has ingested it.
+func (b *Backend) AdjustTime(adjustment time.Duration) error {
+	if err := b.beacon.AdjustTime(adjustment); err != nil {
+		return err
+	}
+	return b.waitForStore()
+}
+
+// Rollback removes all pending transactions.
+func (b *Backend) Rollback() {
+	b.beacon.Rollback()
+}
+
+// Client returns an Ethereum client of the chain.
+func (b *Backend) Client() *ethclient.Client {
+	return b.client
+}
+
+// RPC returns an in-memory RPC client of the node, which serves the arkiv_*
+// methods besides the standard ones.
+func (b *Backend) RPC() *rpc.Client {
+	return b.rpc
+}
+
+// ChainID returns the chain ID of the simulated chain.
+func (b *Backend) ChainID() uint64 {
+	return b.eth.BlockChain().Config().ChainID.Uint64()
+}
+
+// SendArkivTransaction signs atx with key and sends it to the transaction pool.
+// It is included in the next committed block.
+func (b *Backend) SendArkivTransaction(ctx context.Context, key *ecdsa.PrivateKey, atx *storagetx.ArkivTransaction) (*types.Transaction, error) {
+	return b.SendArkivTransactionWithValue(ctx, key, atx, nil)
+}
+
+// SendArkivTransactionWithValue is like SendArkivTransaction, and transfers value
+// wei to the processor, which pays for the renewal deposits of atx.
+func (b *Backend) SendArkivTransactionWithValue(ctx context.Context, key *ecdsa.PrivateKey, atx *storagetx.ArkivTransaction, value *big.Int) (*types.Transaction, error) {
+	tx, err := b.signArkivTransaction(ctx, key, atx, value)
+	if err != nil {
+		return nil, err
+	}
+	return tx, b.client.SendTransaction(ctx, tx)
+}
+
+// SendArkivTransactionConditional is like SendArkivTransaction, and sends atx
+// with eth_sendRawTransactionConditional, so that it is only included if cond
+// holds. The backend must be created with conditional transactions enabled.
+func (b *Backend) SendArkivTransactionConditional(ctx context.Context, key *ecdsa.PrivateKey, atx *storagetx.ArkivTransaction, cond *types.TransactionConditional) (*types.Transaction, error) {
+	tx, err := b.signArkivTransaction(ctx, key, atx, nil)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return tx, b.client.Client().CallContext(ctx, nil, "eth_sendRawTransactionConditional", hexutil.Bytes(raw), cond)
+}
+
+func (b *Backend) signArkivTransaction(ctx context.Context, key *ecdsa.PrivateKey, atx *storagetx.ArkivTransaction, value *big.Int) (*types.Transaction, error) {
+	encoded, err := rlp.EncodeToBytes(atx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arkiv transaction: %w", err)
+	}
+	data, err := compression.BrotliCompress(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	nonce, err := b.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := b.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := b.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := b.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &address.ArkivProcessorAddress,
+		Data:  data,
+		Value: value,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	config := b.eth.BlockChain().Config()
+	tx, err := types.SignNewTx(key, types.LatestSigner(config), &types.DynamicFeeTx{
+		ChainID:   config.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       gas,
+		To:        &address.ArkivProcessorAddress,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Query runs arkiv_query against the store of the chain.
+func (b *Backend) Query(ctx context.Context, query string, options *sqlitestore.Options) (*sqlitestore.QueryResponse, error) {
+	response := &sqlitestore.QueryResponse{}
+	err := b.rpc.CallContext(ctx, response, "arkiv_query", query, options)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (b *Backend) waitForStore() error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	head := b.eth.BlockChain().CurrentBlock().Number.Uint64()
+	return b.eth.ArkivSync().WaitForBlock(ctx, head)
+}
@@ -0,0 +1,816 @@
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/arkiv/bindings"
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBackend creates a simulated chain with a funded account, which is
+// closed at the end of the test.
+func newTestBackend(t *testing.T, options ...func(nodeConf *node.Config, ethConf *ethconfig.Config)) (*Backend, *ecdsa.PrivateKey) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	sim, err := NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.Ether)},
+	}, options...)
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	return sim, key
+}
+
+// withAccount adds an account to the genesis allocation.
+func withAccount(addr common.Address, account types.Account) func(*node.Config, *ethconfig.Config) {
+	return func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		ethConf.Genesis.Alloc[addr] = account
+	}
+}
+
+// withArkivConfig sets the Arkiv limits of the chain config.
+func withArkivConfig(arkiv *params.ArkivConfig) func(*node.Config, *ethconfig.Config) {
+	return func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		config := *ethConf.Genesis.Config
+		config.Arkiv = arkiv
+		ethConf.Genesis.Config = &config
+	}
+}
+
+func TestBackendExpiresEntities(t *testing.T) {
+	sim, key := newTestBackend(t)
+
+	ctx := context.Background()
+
+	tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:               2,
+			ContentType:       "text/plain",
+			Payload:           []byte("hello"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "note"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = sim.Commit()
+	require.NoError(t, err)
+
+	receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	// the store has ingested the block by the time Commit returns
+	res, err := sim.Query(ctx, `type = "note"`, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Data, 1)
+
+	require.NoError(t, sim.AdvanceBlocks(2))
+
+	res, err = sim.Query(ctx, `type = "note"`, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Data)
+}
+
+func TestBackendRenewsEntities(t *testing.T) {
+	sim, key := newTestBackend(t)
+
+	ctx := context.Background()
+
+	// created in block 1, expiring at block 3
+	tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:               2,
+			ContentType:       "text/plain",
+			Payload:           []byte("hello"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "note"}},
+		}},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	entityKey := storagetx.EntityKey(tx.Hash(), []byte("hello"), 0)
+
+	// The budget covers a single renewal by 3 blocks and a half
+	renewal := entity.EntityRenewal{Period: 3}
+	deposit := new(uint256.Int).Add(renewal.Fee(), new(uint256.Int).Div(renewal.Fee(), uint256.NewInt(2)))
+	renew := &storagetx.ArkivTransaction{
+		Renew: []storagetx.ArkivRenew{{EntityKey: entityKey, Period: 3, Deposit: deposit}},
+	}
+
+	// the deposit has to be paid by the value of the transaction
+	_, err = sim.SendArkivTransaction(ctx, key, renew)
+	require.ErrorContains(t, err, "renewal deposits exceed the transaction value")
+
+	tx, err = sim.SendArkivTransactionWithValue(ctx, key, renew, deposit.ToBig())
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Len(t, receipt.Logs, 1)
+	require.Equal(t, arkivlogs.ArkivEntityRenewalSet, receipt.Logs[0].Topics[0])
+
+	balance, err := sim.Client().BalanceAt(ctx, address.ArkivProcessorAddress, nil)
+	require.NoError(t, err)
+	require.Equal(t, deposit.ToBig(), balance)
+
+	// the housekeeping of block 3 renews the entity until block 6
+	require.NoError(t, sim.AdvanceBlocks(1))
+
+	logs, err := sim.Client().FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{address.ArkivProcessorAddress},
+		Topics:    [][]common.Hash{{arkivlogs.ArkivEntityRenewed}},
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, entityKey, logs[0].Topics[1])
+	require.Equal(t, uint64(3), logs[0].BlockNumber)
+
+	remaining := new(uint256.Int).Sub(deposit, renewal.Fee())
+	data := make([]byte, 128)
+	uint256.NewInt(3).PutUint256(data[:32])
+	uint256.NewInt(6).PutUint256(data[32:64])
+	renewal.Fee().PutUint256(data[64:96])
+	remaining.PutUint256(data[96:])
+	require.Equal(t, data, logs[0].Data)
+
+	// the fee is burned from the deposits held by the processor
+	balance, err = sim.Client().BalanceAt(ctx, address.ArkivProcessorAddress, nil)
+	require.NoError(t, err)
+	require.Equal(t, remaining.ToBig(), balance)
+
+	// the store follows the new expiration
+	res, err := sim.Query(ctx, `type = "note" && $expiration = 6`, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Data, 1)
+
+	// the rest of the budget doesn't cover another renewal
+	require.NoError(t, sim.AdvanceBlocks(2))
+	res, err = sim.Query(ctx, `type = "note"`, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Data, 1)
+
+	require.NoError(t, sim.AdvanceBlocks(1))
+	res, err = sim.Query(ctx, `type = "note"`, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Data)
+
+	// and the rest of the budget is burned with the entity
+	balance, err = sim.Client().BalanceAt(ctx, address.ArkivProcessorAddress, nil)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+}
+
+func TestBackendLimitsArkivOperationsPerBlock(t *testing.T) {
+	sim, key := newTestBackend(t, withArkivConfig(&params.ArkivConfig{MaxBlockOps: 2}))
+
+	ctx := context.Background()
+
+	var txs []*types.Transaction
+	for _, payload := range []string{"first", "second", "third"} {
+		tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+			Create: []storagetx.ArkivCreate{{BTL: 100, ContentType: "text/plain", Payload: []byte(payload)}},
+		})
+		require.NoError(t, err)
+		txs = append(txs, tx)
+	}
+
+	// the third transaction doesn't fit the first block and is left for the next one
+	require.NoError(t, sim.AdvanceBlocks(2))
+
+	for i, tx := range txs {
+		receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		require.Equal(t, uint64(i/2+1), receipt.BlockNumber.Uint64())
+	}
+}
+
+func TestBackendLimitsArkivContractCallsPerBlock(t *testing.T) {
+	// The forwarder copies its call data to memory and calls the processor with it.
+	forwarder := common.Address{0xf0}
+	code := append([]byte{
+		0x36, 0x60, 0x00, 0x60, 0x00, 0x37, // CALLDATACOPY(0, 0, CALLDATASIZE)
+		0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00, 0x60, 0x00, // retSize, retOffset, argsSize, argsOffset, value
+		0x73, // PUSH20
+	}, address.ArkivProcessorAddress.Bytes()...)
+	code = append(code, 0x5a, 0xf1, 0x00) // GAS, CALL, STOP
+
+	sim, key := newTestBackend(t,
+		withAccount(forwarder, types.Account{Code: code}),
+		withArkivConfig(&params.ArkivConfig{MaxBlockOps: 2}),
+	)
+
+	ctx := context.Background()
+
+	encoded, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			{BTL: 100, ContentType: "text/plain", Payload: []byte("first")},
+			{BTL: 100, ContentType: "text/plain", Payload: []byte("second")},
+		},
+	})
+	require.NoError(t, err)
+	data := compression.MustBrotliCompress(encoded)
+
+	config := sim.eth.BlockChain().Config()
+	var txs []*types.Transaction
+	for nonce := range uint64(2) {
+		tx, err := types.SignNewTx(key, types.LatestSigner(config), &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+			Gas:       1_000_000,
+			To:        &forwarder,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		require.NoError(t, sim.Client().SendTransaction(ctx, tx))
+		txs = append(txs, tx)
+	}
+
+	// the operations of a contract call are only known after execution, the
+	// second call doesn't fit the first block and is left for the next one
+	require.NoError(t, sim.AdvanceBlocks(2))
+
+	for i, tx := range txs {
+		receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		require.Equal(t, uint64(i+1), receipt.BlockNumber.Uint64())
+		require.Equal(t, arkivlogs.ArkivContractCall, receipt.Logs[0].Topics[0])
+	}
+}
+
+func TestBackendConditionalArkivTransactions(t *testing.T) {
+	key2, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	sim, key1 := newTestBackend(t,
+		withAccount(crypto.PubkeyToAddress(key2.PublicKey), types.Account{Balance: big.NewInt(params.Ether)}),
+		func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+			ethConf.RollupSequencerTxConditionalEnabled = true
+			ethConf.RollupSequencerTxConditionalCostRateLimit = 5000
+		},
+	)
+
+	ctx := context.Background()
+
+	create := func(payload string) *storagetx.ArkivTransaction {
+		return &storagetx.ArkivTransaction{
+			Create: []storagetx.ArkivCreate{{
+				BTL:               100,
+				ContentType:       "text/plain",
+				Payload:           []byte(payload),
+				StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "unique"}},
+			}},
+		}
+	}
+	zero := uint64(0)
+	absent := &types.TransactionConditional{
+		Arkiv: &types.ArkivConditional{
+			Queries: []types.ArkivQueryCondition{{Query: `type = "unique"`, MaxResults: &zero}},
+		},
+	}
+
+	// conditions are checked against the parent block as well, which genesis doesn't have
+	require.NoError(t, sim.AdvanceBlocks(1))
+
+	// both creations pass the check on submission, as there is no such entity yet
+	first, err := sim.SendArkivTransactionConditional(ctx, key1, create("first"), absent)
+	require.NoError(t, err)
+	second, err := sim.SendArkivTransactionConditional(ctx, key2, create("second"), absent)
+	require.NoError(t, err)
+
+	// only one of them is included, the other one is left for the next block,
+	// where the entity created by the first one fails its condition
+	require.NoError(t, sim.AdvanceBlocks(2))
+
+	var included []*types.Transaction
+	for _, tx := range []*types.Transaction{first, second} {
+		receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		require.Equal(t, uint64(2), receipt.BlockNumber.Uint64())
+		included = append(included, tx)
+	}
+	require.Len(t, included, 1)
+
+	res, err := sim.Query(ctx, `type = "unique"`, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Data, 1)
+
+	// the query condition is checked on submission as well
+	_, err = sim.SendArkivTransactionConditional(ctx, key1, create("third"), absent)
+	require.ErrorContains(t, err, "failed arkiv query check")
+
+	owner := crypto.PubkeyToAddress(key1.PublicKey)
+	entityKey := storagetx.EntityKey(included[0].Hash(), []byte("first"), 0)
+	if included[0] == second {
+		owner = crypto.PubkeyToAddress(key2.PublicKey)
+		entityKey = storagetx.EntityKey(included[0].Hash(), []byte("second"), 0)
+	}
+
+	// the entity conditions are checked against the state
+	other := common.Address{0x01}
+	_, err = sim.SendArkivTransactionConditional(ctx, key1, create("fourth"), &types.TransactionConditional{
+		Arkiv: &types.ArkivConditional{Entities: []types.ArkivEntityCondition{{Key: entityKey, Owner: &other}}},
+	})
+	require.ErrorContains(t, err, "owner constraint")
+
+	expiresAfter := uint64(50)
+	tx, err := sim.SendArkivTransactionConditional(ctx, key1, create("fifth"), &types.TransactionConditional{
+		Arkiv: &types.ArkivConditional{Entities: []types.ArkivEntityCondition{{Key: entityKey, Owner: &owner, ExpiresAfter: &expiresAfter}}},
+	})
+	require.NoError(t, err)
+
+	_, err = sim.Commit()
+	require.NoError(t, err)
+
+	receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+}
+
+func TestBackendConditionalArkivTransactionsAfterExpiration(t *testing.T) {
+	key2, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	sim, key := newTestBackend(t,
+		withAccount(crypto.PubkeyToAddress(key2.PublicKey), types.Account{Balance: big.NewInt(params.Ether)}),
+		func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+			ethConf.RollupSequencerTxConditionalEnabled = true
+			ethConf.RollupSequencerTxConditionalCostRateLimit = 5000
+		},
+	)
+
+	ctx := context.Background()
+
+	create := func(kind string, btl uint64) *storagetx.ArkivTransaction {
+		return &storagetx.ArkivTransaction{
+			Create: []storagetx.ArkivCreate{{
+				BTL:               btl,
+				ContentType:       "text/plain",
+				Payload:           []byte(kind),
+				StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: kind}},
+			}},
+		}
+	}
+
+	// two entities expiring in block 4
+	_, err = sim.SendArkivTransaction(ctx, key, create("unrelated", 3))
+	require.NoError(t, err)
+	_, err = sim.SendArkivTransaction(ctx, key, create("expiring", 3))
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	require.NoError(t, sim.AdvanceBlocks(2))
+
+	// the first transaction is checked after the housekeeping only, which deletes
+	// the entity it requires
+	one, zero := uint64(1), uint64(0)
+	present, err := sim.SendArkivTransactionConditional(ctx, key2, create("dependent", 100), &types.TransactionConditional{
+		Arkiv: &types.ArkivConditional{
+			Queries: []types.ArkivQueryCondition{{Query: `type = "expiring"`, MinResults: &one}},
+		},
+	})
+	require.NoError(t, err)
+	absent, err := sim.SendArkivTransactionConditional(ctx, key, create("unique", 100), &types.TransactionConditional{
+		Arkiv: &types.ArkivConditional{
+			Queries: []types.ArkivQueryCondition{{Query: `type = "unique"`, MaxResults: &zero}},
+		},
+	})
+	require.NoError(t, err)
+
+	// the housekeeping deletes the expired entities before the transactions, which
+	// are checked against the store without them rather than left for a later block
+	head, err := sim.Commit()
+	require.NoError(t, err)
+	block, err := sim.Client().BlockByHash(ctx, head)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), block.NumberU64())
+
+	receipt, err := sim.Client().TransactionReceipt(ctx, absent.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Equal(t, uint64(4), receipt.BlockNumber.Uint64())
+
+	_, err = sim.Client().TransactionReceipt(ctx, present.Hash())
+	require.ErrorIs(t, err, ethereum.NotFound)
+
+	res, err := sim.Query(ctx, `type = "expiring" || type = "unrelated"`, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Data)
+}
+
+func TestBackendGenesisEntities(t *testing.T) {
+	owner := common.Address{0xaa}
+	sim, key := newTestBackend(t, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		ethConf.Genesis.Arkiv = &core.GenesisArkiv{
+			Entities: []core.GenesisEntity{
+				{Owner: owner, BTL: 2, ContentType: "text/plain", Payload: []byte("short"), StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "reference"}}},
+				{Owner: owner, ExpiresAt: 100, ContentType: "text/plain", Payload: []byte("long"), StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "reference"}}},
+			},
+		}
+	})
+
+	ctx := context.Background()
+
+	// the store is seeded with the genesis entities before any block is mined
+	res, err := sim.Query(ctx, `type = "reference"`, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Data, 2)
+
+	// the genesis entities expire like any other
+	require.NoError(t, sim.AdvanceBlocks(2))
+
+	res, err = sim.Query(ctx, `type = "reference"`, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Data, 1)
+
+	// the genesis entities are written with the same attributes as the store
+	// writes for the creates it ingests
+	_, err = sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:               100,
+			ContentType:       "text/plain",
+			Payload:           []byte("created"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "reference"}},
+		}},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+
+	res, err = sim.Query(ctx, `type = "reference"`, &sqlitestore.Options{
+		IncludeData: &sqlitestore.IncludeData{SyntheticAttributes: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Data, 2)
+	var names [2][]string
+	for i, raw := range res.Data {
+		var ed sqlitestore.EntityData
+		require.NoError(t, json.Unmarshal(raw, &ed))
+		for _, a := range ed.StringAttributes {
+			names[i] = append(names[i], a.Key)
+		}
+		for _, a := range ed.NumericAttributes {
+			names[i] = append(names[i], a.Key)
+		}
+	}
+	require.NotEmpty(t, names[0])
+	require.ElementsMatch(t, names[0], names[1])
+}
+
+func TestBackendExpirationSchedule(t *testing.T) {
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	sim, key := newTestBackend(t, withAccount(crypto.PubkeyToAddress(otherKey.PublicKey), types.Account{Balance: big.NewInt(params.Ether)}))
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	ctx := context.Background()
+
+	// created in block 1, the entities of owner expire at blocks 6 and 11, the
+	// other entity at block 6
+	tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			{BTL: 5, ContentType: "text/plain", Payload: []byte("soon")},
+			{BTL: 10, ContentType: "text/plain", Payload: []byte("later")},
+		},
+	})
+	require.NoError(t, err)
+	_, err = sim.SendArkivTransaction(ctx, otherKey, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 5, ContentType: "text/plain", Payload: []byte("other")}},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	soon := storagetx.EntityKey(tx.Hash(), []byte("soon"), 0)
+	later := storagetx.EntityKey(tx.Hash(), []byte("later"), 1)
+
+	var schedule eth.ArkivExpirationSchedule
+	require.NoError(t, sim.RPC().CallContext(ctx, &schedule, "arkiv_getExpirationSchedule", 0, 20))
+	require.Equal(t, eth.ArkivExpirationSchedule{
+		HeadBlock: 1,
+		Blocks:    []eth.ArkivExpiringBlock{{Block: 6, Count: 2}, {Block: 11, Count: 1}},
+	}, schedule)
+
+	require.NoError(t, sim.RPC().CallContext(ctx, &schedule, "arkiv_getExpirationSchedule", 0, 20, owner, true))
+	require.Equal(t, eth.ArkivExpirationSchedule{
+		HeadBlock: 1,
+		Blocks: []eth.ArkivExpiringBlock{
+			{Block: 6, Count: 1, Keys: []common.Hash{soon}},
+			{Block: 11, Count: 1, Keys: []common.Hash{later}},
+		},
+	}, schedule)
+
+	err = sim.RPC().CallContext(ctx, &schedule, "arkiv_getExpirationSchedule", 20, 0)
+	require.ErrorContains(t, err, "is after toBlock")
+
+	// the entity expiring within the window is warned about right away, the
+	// other one once it comes within the window
+	warnings := make(chan eth.ArkivExpirationWarning, 4)
+	sub, err := sim.RPC().Subscribe(ctx, "arkiv", warnings, "expirationWarnings", owner, 5)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	require.Equal(t, eth.ArkivExpirationWarning{Key: soon, Owner: owner, ExpiresAtBlock: 6, HeadBlock: 1}, <-warnings)
+
+	require.NoError(t, sim.AdvanceBlocks(4))
+	require.Empty(t, warnings)
+
+	require.NoError(t, sim.AdvanceBlocks(1))
+	select {
+	case warning := <-warnings:
+		require.Equal(t, eth.ArkivExpirationWarning{Key: later, Owner: owner, ExpiresAtBlock: 11, HeadBlock: 6}, warning)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no warning for the entity entering the window")
+	}
+
+	// entities created within the window and updates shortening the expiration
+	// into a new one are warned about in the block that changes them
+	tx, err = sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 2, ContentType: "text/plain", Payload: []byte("new")}},
+		Update: []storagetx.ArkivUpdate{{EntityKey: later, BTL: 1, ContentType: "text/plain", Payload: []byte("shorter")}},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	created := storagetx.EntityKey(tx.Hash(), []byte("new"), 0)
+
+	var got []eth.ArkivExpirationWarning
+	for range 2 {
+		select {
+		case warning := <-warnings:
+			got = append(got, warning)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no warning for the changed entities")
+		}
+	}
+	require.ElementsMatch(t, []eth.ArkivExpirationWarning{
+		{Key: created, Owner: owner, ExpiresAtBlock: 9, HeadBlock: 7},
+		{Key: later, Owner: owner, ExpiresAtBlock: 8, HeadBlock: 7},
+	}, got)
+
+	require.NoError(t, sim.AdvanceBlocks(1))
+	require.Never(t, func() bool { return len(warnings) > 0 }, 200*time.Millisecond, 20*time.Millisecond)
+}
+
+func TestBackendTypedAnnotations(t *testing.T) {
+	sim, key := newTestBackend(t)
+
+	ctx := context.Background()
+
+	reading := func(name string, temp int64, price string, valid bool, sensor common.Address) storagetx.ArkivCreate {
+		return storagetx.ArkivCreate{
+			BTL:                100,
+			ContentType:        "text/plain",
+			Payload:            []byte(name),
+			StringAnnotations:  []storagetx.StringAnnotation{{Key: "name", Value: name}},
+			IntAnnotations:     []storagetx.IntAnnotation{storagetx.NewIntAnnotation("temp", temp)},
+			DecimalAnnotations: []storagetx.DecimalAnnotation{{Key: "price", Value: price}},
+			BoolAnnotations:    []storagetx.BoolAnnotation{{Key: "valid", Value: valid}},
+			AddressAnnotations: []storagetx.AddressAnnotation{{Key: "sensor", Value: sensor}},
+		}
+	}
+	sensor := common.HexToAddress("0x00000000000000000000000000000000000000Aa")
+	tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			reading("cold", -20, "-1.5", true, sensor),
+			reading("freezing", -1, "0.25", false, sensor),
+			reading("mild", 15, "10", true, common.Address{1}),
+		},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	receipt, err := sim.Client().TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	var (
+		temp     = bindings.IntField("temp")
+		price    = bindings.DecimalField("price")
+		valid    = bindings.BoolField("valid")
+		sensorOf = bindings.AddressField("sensor")
+	)
+	names := func(q bindings.Query) []string {
+		res, err := sim.Query(ctx, q.String(), &sqlitestore.Options{IncludeData: &sqlitestore.IncludeData{Payload: true}})
+		require.NoError(t, err)
+		names := []string{}
+		for _, data := range res.Data {
+			var e struct {
+				Value hexutil.Bytes `json:"value"`
+			}
+			require.NoError(t, json.Unmarshal(data, &e))
+			names = append(names, string(e.Value))
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	require.Equal(t, []string{"cold", "freezing"}, names(temp.Lt(0)))
+	require.Equal(t, []string{"freezing", "mild"}, names(temp.Ge(-1)))
+	require.Equal(t, []string{"cold"}, names(price.Lt("-0.5")))
+	require.Equal(t, []string{"freezing", "mild"}, names(price.Gt("0")))
+	require.Equal(t, []string{"mild"}, names(price.Eq("10.00")))
+	require.Equal(t, []string{"cold", "mild"}, names(valid.Eq(true)))
+	require.Equal(t, []string{"cold", "freezing"}, names(sensorOf.Eq(sensor)))
+	require.Equal(t, []string{"cold"}, names(bindings.And(valid.Eq(true), temp.Lt(0), sensorOf.Eq(sensor))))
+
+	// typed annotations share the keys of the attributes they are indexed as
+	_, err = sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:               100,
+			ContentType:       "text/plain",
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "temp", Value: "cold"}},
+			IntAnnotations:    []storagetx.IntAnnotation{storagetx.NewIntAnnotation("temp", -1)},
+		}},
+	})
+	require.ErrorContains(t, err, "int annotation key temp is duplicated")
+}
+
+func TestBackendPendingQueryPages(t *testing.T) {
+	sim, key := newTestBackend(t)
+
+	ctx := context.Background()
+
+	note := func(payload string) storagetx.ArkivCreate {
+		return storagetx.ArkivCreate{
+			BTL:               100,
+			ContentType:       "text/plain",
+			Payload:           []byte(payload),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "note"}},
+		}
+	}
+	tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{note("a"), note("b")},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	updated := storagetx.EntityKey(tx.Hash(), []byte("a"), 0)
+
+	// the pending transaction updates a confirmed note and creates two more
+	_, err = sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{note("c"), note("d")},
+		Update: []storagetx.ArkivUpdate{{
+			EntityKey:         updated,
+			BTL:               100,
+			ContentType:       "text/plain",
+			Payload:           []byte("a2"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "type", Value: "note"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	type result struct {
+		Key         common.Hash `json:"key"`
+		Unconfirmed bool        `json:"unconfirmed"`
+	}
+	var (
+		keys        []common.Hash
+		unconfirmed int
+		cursor      string
+	)
+	for {
+		var res struct {
+			Data       []result       `json:"data"`
+			Cursor     *string        `json:"cursor"`
+			TotalCount hexutil.Uint64 `json:"totalCount"`
+		}
+		options := map[string]any{"atBlock": "pending", "resultsPerPage": 2}
+		if cursor != "" {
+			options["cursor"] = cursor
+		}
+		require.NoError(t, sim.RPC().CallContext(ctx, &res, "arkiv_query", `type = "note"`, options))
+		require.Equal(t, hexutil.Uint64(4), res.TotalCount)
+		require.LessOrEqual(t, len(res.Data), 2)
+		for _, r := range res.Data {
+			keys = append(keys, r.Key)
+			if r.Unconfirmed {
+				unconfirmed++
+			}
+		}
+		if res.Cursor == nil {
+			break
+		}
+		cursor = *res.Cursor
+	}
+	require.Len(t, keys, 4)
+	require.Contains(t, keys, updated)
+	require.Equal(t, 3, unconfirmed)
+	slices.SortFunc(keys, func(a, b common.Hash) int { return a.Cmp(b) })
+	require.Len(t, slices.Compact(keys), 4)
+}
+
+func TestBackendSimulateTransaction(t *testing.T) {
+	sim, key := newTestBackend(t)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	ctx := context.Background()
+
+	// created in block 1, expiring at block 2
+	tx, err := sim.SendArkivTransaction(ctx, key, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 1, ContentType: "text/plain", Payload: []byte("hello")}},
+	})
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	entityKey := storagetx.EntityKey(tx.Hash(), []byte("hello"), 0)
+
+	simulate := func(atx *storagetx.ArkivTransaction, value *big.Int) *eth.ArkivSimulationResult {
+		encoded, err := rlp.EncodeToBytes(atx)
+		require.NoError(t, err)
+		args := eth.ArkivSimulationArgs{From: &sender, Data: compression.MustBrotliCompress(encoded), Value: (*hexutil.Big)(value)}
+		var res eth.ArkivSimulationResult
+		require.NoError(t, sim.RPC().CallContext(ctx, &res, "arkiv_simulateTransaction", args))
+		return &res
+	}
+
+	// the housekeeping of block 2 expires the entity before the transaction runs
+	res := simulate(&storagetx.ArkivTransaction{Delete: []common.Hash{entityKey}}, nil)
+	require.False(t, res.Success)
+	require.Len(t, res.Operations, 1)
+	require.NotEmpty(t, res.Operations[0].Error)
+
+	// the renewal deposits are paid by the value, which the sender must afford
+	renewal := entity.EntityRenewal{Period: 3}
+	create := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 10, ContentType: "text/plain", Payload: []byte("renewed")}},
+	}
+	tx, err = sim.SendArkivTransaction(ctx, key, create)
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	renew := &storagetx.ArkivTransaction{
+		Renew: []storagetx.ArkivRenew{{EntityKey: storagetx.EntityKey(tx.Hash(), []byte("renewed"), 0), Period: 3, Deposit: renewal.Fee()}},
+	}
+
+	res = simulate(renew, renewal.Fee().ToBig())
+	require.True(t, res.Success, res.Error)
+
+	res = simulate(renew, new(big.Int).Mul(big.NewInt(2), big.NewInt(params.Ether)))
+	require.False(t, res.Success)
+	require.Contains(t, res.Error, "insufficient funds for transfer")
+}
+
+func TestBackendSimulateTransactionNextBlockRules(t *testing.T) {
+	// renewals are activated between the head and the next block
+	forkTime := uint64(time.Now().Unix()) + 1500
+	sim, key := newTestBackend(t, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		config := *ethConf.Genesis.Config
+		config.ArkivRenewalTime = &forkTime
+		ethConf.Genesis.Config = &config
+	})
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	_, err := sim.Commit()
+	require.NoError(t, err)
+	require.NoError(t, sim.AdjustTime(1000*time.Second))
+
+	ctx := context.Background()
+	head, err := sim.Client().HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.Less(t, head.Time, forkTime)
+
+	encoded, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{
+		Renew: []storagetx.ArkivRenew{{EntityKey: common.HexToHash("0x01"), Period: 3}},
+	})
+	require.NoError(t, err)
+	args := eth.ArkivSimulationArgs{From: &sender, Data: compression.MustBrotliCompress(encoded)}
+	var res eth.ArkivSimulationResult
+	require.NoError(t, sim.RPC().CallContext(ctx, &res, "arkiv_simulateTransaction", args))
+	require.Len(t, res.Operations, 1)
+	require.Equal(t, "renew", res.Operations[0].Type)
+}
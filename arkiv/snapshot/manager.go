@@ -0,0 +1,370 @@
+// Package snapshot pages through the query results of the Arkiv store at a
+// pinned block.
+//
+// A snapshot is a read transaction of the store kept open across calls. It sees
+// the store as it was when the transaction started, no matter how many blocks
+// are ingested in the meantime, so its cursors stay valid and its pages neither
+// skip nor repeat entities.
+package snapshot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/Arkiv-Network/sqlite-bitmap-store/store"
+	"github.com/RoaringBitmap/roaring/v2/roaring64"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	ErrUnknownCursor    = errors.New("unknown or expired cursor, restart the query")
+	ErrQueryMismatch    = errors.New("cursor belongs to a different query")
+	ErrTooManySnapshots = errors.New("too many open snapshots, retry later")
+	ErrClosed           = errors.New("query snapshots are closed")
+	errInvalidCursor    = errors.New("invalid snapshot cursor")
+)
+
+// IsCursor reports whether cursor was returned for a snapshot, rather than by
+// the store itself.
+func IsCursor(cursor string) bool {
+	return strings.Contains(cursor, ":")
+}
+
+// Manager keeps the snapshots of the pinned queries in progress. Every snapshot
+// and stream holds a connection of the store's read pool, so their number is
+// limited, and a snapshot is closed once its last page is read, it has been idle
+// for too long or it has reached its maximum lifetime, which keeps a client
+// reading pages slowly from holding a connection forever.
+type Manager struct {
+	store       *sqlitestore.SQLiteStore
+	idleTimeout time.Duration
+	maxLifetime time.Duration
+	slots       chan struct{}
+
+	ctx    context.Context // cancelled by Close
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	snapshots map[string]*snapshot
+	closed    bool
+}
+
+// NewManager creates a manager allowing at most maxOpen snapshots and streams at
+// the same time, each snapshot open for at most maxLifetime.
+func NewManager(store *sqlitestore.SQLiteStore, maxOpen int, idleTimeout, maxLifetime time.Duration) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		store:       store,
+		idleTimeout: idleTimeout,
+		maxLifetime: maxLifetime,
+		slots:       make(chan struct{}, maxOpen),
+		ctx:         ctx,
+		cancel:      cancel,
+		snapshots:   map[string]*snapshot{},
+	}
+}
+
+// Close closes all snapshots, stops all streams and waits until their read
+// transactions are finished. Queries and streams started afterwards fail.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	m.cancel()
+	m.wg.Wait()
+}
+
+// track registers a goroutine holding a read transaction, unless the manager
+// is closed.
+func (m *Manager) track() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	m.wg.Add(1)
+	return nil
+}
+
+type snapshot struct {
+	id       string
+	query    string
+	block    uint64
+	results  *roaring64.Bitmap
+	requests chan pageRequest
+	done     chan struct{}
+}
+
+type pageRequest struct {
+	ctx     context.Context
+	before  *uint64
+	options *sqlitestore.Options
+	result  chan pageResult
+}
+
+type pageResult struct {
+	page *sqlitestore.QueryResponse
+	next *uint64
+	err  error
+}
+
+// Query returns a page of the results of req. Without a cursor in options, a
+// new snapshot is opened at the last block ingested by the store, otherwise the
+// page following the cursor is read from the cursor's snapshot.
+func (m *Manager) Query(ctx context.Context, req string, options *sqlitestore.Options) (*sqlitestore.QueryResponse, error) {
+	var (
+		s      *snapshot
+		before *uint64
+	)
+
+	if options.Cursor == "" {
+		var err error
+		s, err = m.open(req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		id, position, ok := strings.Cut(options.Cursor, ":")
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		next, err := hexutil.DecodeUint64(position)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidCursor, err)
+		}
+		before = &next
+
+		m.mu.Lock()
+		s = m.snapshots[id]
+		m.mu.Unlock()
+
+		if s == nil {
+			return nil, ErrUnknownCursor
+		}
+		if s.query != req {
+			return nil, ErrQueryMismatch
+		}
+	}
+
+	r := pageRequest{
+		ctx:     ctx,
+		before:  before,
+		options: options,
+		result:  make(chan pageResult, 1),
+	}
+
+	select {
+	case s.requests <- r:
+	case <-s.done:
+		return nil, ErrUnknownCursor
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	res := <-r.result
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	res.page.BlockNumber = hexutil.Uint64(s.block)
+	if res.next != nil {
+		cursor := s.id + ":" + hexutil.EncodeUint64(*res.next)
+		res.page.Cursor = &cursor
+	}
+
+	return res.page, nil
+}
+
+// Stream reads all the results of req at the last block ingested by the store
+// and passes them to send in the background, one page at a time, until the last
+// page is sent, the context is done or send fails. The returned channel receives
+// the error that stopped the stream, or nil.
+func (m *Manager) Stream(ctx context.Context, req string, options *sqlitestore.Options, send func(*sqlitestore.QueryResponse) error) (<-chan error, error) {
+	q, err := query.Parse(req)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing query: %w", err)
+	}
+
+	if err := m.track(); err != nil {
+		return nil, err
+	}
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		m.wg.Done()
+		return nil, ErrTooManySnapshots
+	}
+
+	// the stream is stopped on Close
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(m.ctx, cancel)
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer m.wg.Done()
+		defer func() { <-m.slots }()
+		defer cancel()
+		defer stop()
+
+		done <- m.store.ReadTransaction(ctx, func(queries *store.Queries) error {
+			block, err := queries.GetLastBlock(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get last block: %w", err)
+			}
+
+			results, err := q.Evaluate(ctx, queries)
+			if err != nil {
+				return fmt.Errorf("error evaluating query: %w", err)
+			}
+
+			var before *uint64
+			for {
+				page, next, err := readPage(ctx, queries, results, before, options)
+				if err != nil {
+					return err
+				}
+
+				page.BlockNumber = hexutil.Uint64(block)
+				if next != nil {
+					cursor := hexutil.EncodeUint64(*next)
+					page.Cursor = &cursor
+				}
+
+				if err := send(page); err != nil {
+					return err
+				}
+				if next == nil {
+					return nil
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				before = next
+			}
+		})
+	}()
+
+	return done, nil
+}
+
+// open starts a snapshot and evaluates req in it.
+func (m *Manager) open(req string) (*snapshot, error) {
+	q, err := query.Parse(req)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing query: %w", err)
+	}
+
+	if err := m.track(); err != nil {
+		return nil, err
+	}
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		m.wg.Done()
+		return nil, ErrTooManySnapshots
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		<-m.slots
+		m.wg.Done()
+		return nil, err
+	}
+
+	s := &snapshot{
+		id:       hex.EncodeToString(id),
+		query:    req,
+		requests: make(chan pageRequest),
+		done:     make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.snapshots[s.id] = s
+	m.mu.Unlock()
+
+	ready := make(chan error, 1)
+
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.mu.Lock()
+			delete(m.snapshots, s.id)
+			m.mu.Unlock()
+			close(s.done)
+			<-m.slots
+		}()
+
+		ctx := context.Background()
+		err := m.store.ReadTransaction(ctx, func(queries *store.Queries) error {
+			block, err := queries.GetLastBlock(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get last block: %w", err)
+			}
+
+			s.results, err = q.Evaluate(ctx, queries)
+			if err != nil {
+				return fmt.Errorf("error evaluating query: %w", err)
+			}
+			s.block = uint64(block)
+
+			ready <- nil
+			s.serve(m.ctx, queries, m.idleTimeout, m.maxLifetime)
+			return nil
+		})
+		if err != nil {
+			log.Debug("Arkiv query snapshot failed", "id", s.id, "error", err)
+		}
+
+		// no-op if the snapshot was already reported as ready
+		select {
+		case ready <- err:
+		default:
+		}
+	}()
+
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// serve answers page requests until the last page is read, no page has been
+// requested for idleTimeout, the snapshot has been open for maxLifetime or ctx
+// is done.
+func (s *snapshot) serve(ctx context.Context, queries *store.Queries, idleTimeout, maxLifetime time.Duration) {
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+	expired := time.NewTimer(maxLifetime)
+	defer expired.Stop()
+
+	for {
+		select {
+		case r := <-s.requests:
+			page, next, err := readPage(r.ctx, queries, s.results, r.before, r.options)
+			r.result <- pageResult{page: page, next: next, err: err}
+			if err == nil && next == nil {
+				return
+			}
+			idle.Reset(idleTimeout)
+		case <-idle.C:
+			return
+		case <-expired.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+	"github.com/Arkiv-Network/arkiv-events/events"
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func ingest(t *testing.T, store *sqlitestore.SQLiteStore, blocks ...events.Block) {
+	err := store.FollowEvents(context.Background(), func(yield func(arkivevents.BatchOrError) bool) {
+		yield(arkivevents.BatchOrError{Batch: events.BlockBatch{Blocks: blocks}})
+	})
+	require.NoError(t, err)
+}
+
+func create(txIndex uint64, key common.Hash) events.Operation {
+	return events.Operation{
+		TxIndex: txIndex,
+		Create: &events.OPCreate{
+			Key:               key,
+			ContentType:       "text/plain",
+			BTL:               100,
+			Content:           []byte("data"),
+			StringAttributes:  map[string]string{"type": "note"},
+			NumericAttributes: map[string]uint64{},
+		},
+	}
+}
+
+func keys(t *testing.T, page *sqlitestore.QueryResponse) []common.Hash {
+	keys := []common.Hash{}
+	for _, d := range page.Data {
+		var ed sqlitestore.EntityData
+		require.NoError(t, json.Unmarshal(d, &ed))
+		keys = append(keys, *ed.Key)
+	}
+	return keys
+}
+
+func TestSnapshotQuery(t *testing.T) {
+	store, err := sqlitestore.NewSQLiteStore(slog.Default(), filepath.Join(t.TempDir(), "arkiv.db"), 4)
+	require.NoError(t, err)
+
+	first := events.Block{Number: 1}
+	for i := range 5 {
+		first.Operations = append(first.Operations, create(uint64(i), common.BigToHash(big.NewInt(int64(i+1)))))
+	}
+	ingest(t, store, first)
+
+	m := NewManager(store, 1, time.Minute, time.Hour)
+	perPage := uint64(2)
+	options := &sqlitestore.Options{ResultsPerPage: &perPage}
+
+	page, err := m.Query(context.Background(), `type = "note"`, options)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), uint64(page.BlockNumber))
+	require.Equal(t, uint64(5), uint64(page.TotalCount))
+	require.NotNil(t, page.Cursor)
+	require.True(t, IsCursor(*page.Cursor))
+	seen := keys(t, page)
+
+	// the only slot is taken by the open snapshot
+	_, err = m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{})
+	require.ErrorIs(t, err, ErrTooManySnapshots)
+
+	// changes ingested after the snapshot was opened are not visible
+	deleted := events.OPDelete(common.BigToHash(big.NewInt(1)))
+	ingest(t, store, events.Block{
+		Number: 2,
+		Operations: []events.Operation{
+			create(0, common.HexToHash("0xff")),
+			{TxIndex: 1, Delete: &deleted},
+		},
+	})
+
+	_, err = m.Query(context.Background(), `type = "other"`, &sqlitestore.Options{Cursor: *page.Cursor})
+	require.ErrorIs(t, err, ErrQueryMismatch)
+
+	var cursor string
+	for page.Cursor != nil {
+		cursor = *page.Cursor
+		page, err = m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{ResultsPerPage: &perPage, Cursor: cursor})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), uint64(page.BlockNumber))
+		seen = append(seen, keys(t, page)...)
+	}
+	require.Len(t, seen, 5)
+	require.Contains(t, seen, common.BigToHash(big.NewInt(1)))
+	require.NotContains(t, seen, common.HexToHash("0xff"))
+
+	// the snapshot is closed after its last page
+	_, err = m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{Cursor: cursor})
+	require.ErrorIs(t, err, ErrUnknownCursor)
+
+	blocks := []uint64{}
+	streamed := []common.Hash{}
+	done, err := m.Stream(context.Background(), `type = "note"`, options, func(page *sqlitestore.QueryResponse) error {
+		blocks = append(blocks, uint64(page.BlockNumber))
+		for _, d := range page.Data {
+			var ed sqlitestore.EntityData
+			if err := json.Unmarshal(d, &ed); err != nil {
+				return err
+			}
+			streamed = append(streamed, *ed.Key)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+	require.Equal(t, []uint64{2, 2, 2}, blocks)
+	require.Len(t, streamed, 5)
+	require.NotContains(t, streamed, common.BigToHash(big.NewInt(1)))
+	require.Contains(t, streamed, common.HexToHash("0xff"))
+}
+
+func TestSnapshotLifetime(t *testing.T) {
+	store, err := sqlitestore.NewSQLiteStore(slog.Default(), filepath.Join(t.TempDir(), "arkiv.db"), 4)
+	require.NoError(t, err)
+
+	first := events.Block{Number: 1}
+	for i := range 5 {
+		first.Operations = append(first.Operations, create(uint64(i), common.BigToHash(big.NewInt(int64(i+1)))))
+	}
+	ingest(t, store, first)
+
+	perPage := uint64(1)
+	m := NewManager(store, 2, time.Minute, 200*time.Millisecond)
+
+	// reading pages doesn't keep a snapshot open beyond its lifetime
+	page, err := m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{ResultsPerPage: &perPage})
+	require.NoError(t, err)
+	cursor := *page.Cursor
+	require.Eventually(t, func() bool {
+		_, err := m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{ResultsPerPage: &perPage, Cursor: cursor})
+		return errors.Is(err, ErrUnknownCursor)
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// snapshots are closed on shutdown
+	page, err = m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{ResultsPerPage: &perPage})
+	require.NoError(t, err)
+	m.Close()
+
+	_, err = m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{ResultsPerPage: &perPage, Cursor: *page.Cursor})
+	require.ErrorIs(t, err, ErrUnknownCursor)
+	_, err = m.Query(context.Background(), `type = "note"`, &sqlitestore.Options{})
+	require.ErrorIs(t, err, ErrClosed)
+	_, err = m.Stream(context.Background(), `type = "note"`, &sqlitestore.Options{}, func(*sqlitestore.QueryResponse) error { return nil })
+	require.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,155 @@
+package snapshot
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/Arkiv-Network/sqlite-bitmap-store/store"
+	"github.com/RoaringBitmap/roaring/v2/roaring64"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// MaxPageBytes bounds the size of the entities of a single page, so that pages
+// stay well below the message size limits of RPC clients.
+const MaxPageBytes = 16 * 1024 * 1024
+
+// retrieveBatchSize is the number of payloads retrieved from the store at once.
+const retrieveBatchSize = 10
+
+// readPage reads the page of results that follows the ID before, in the same
+// descending ID order as the store. It returns the ID to continue from, or nil
+// if this is the last page.
+func readPage(ctx context.Context, queries *store.Queries, results *roaring64.Bitmap, before *uint64, options *sqlitestore.Options) (*sqlitestore.QueryResponse, *uint64, error) {
+	page := &sqlitestore.QueryResponse{
+		Data:       []json.RawMessage{},
+		TotalCount: hexutil.Uint64(results.GetCardinality()),
+	}
+
+	remaining := results
+	if before != nil {
+		mask := roaring64.New()
+		mask.AddRange(0, *before)
+		remaining = roaring64.And(results, mask)
+	}
+
+	it := remaining.ReverseIterator()
+	maxResults := options.GetResultsPerPage()
+	includeData := options.GetIncludeData()
+
+	var (
+		pageBytes int
+		lastID    uint64
+	)
+
+	for it.HasNext() && uint64(len(page.Data)) < maxResults && pageBytes < MaxPageBytes {
+		ids := []uint64{}
+		for len(ids) < retrieveBatchSize && uint64(len(page.Data)+len(ids)) < maxResults && it.HasNext() {
+			ids = append(ids, it.Next())
+		}
+
+		rows, err := queries.RetrievePayloads(ctx, ids)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving payloads: %w", err)
+		}
+		slices.SortFunc(rows, func(a, b store.RetrievePayloadsRow) int {
+			return cmp.Compare(b.ID, a.ID)
+		})
+
+		for _, row := range rows {
+			d, err := json.Marshal(toEntityData(row, includeData))
+			if err != nil {
+				return nil, nil, fmt.Errorf("error marshalling entity data: %w", err)
+			}
+			page.Data = append(page.Data, d)
+			pageBytes += len(d)
+		}
+
+		lastID = ids[len(ids)-1]
+	}
+
+	if !it.HasNext() {
+		return page, nil, nil
+	}
+	return page, &lastID, nil
+}
+
+// toEntityData converts a row of the store the same way the store does for its
+// own query results.
+func toEntityData(r store.RetrievePayloadsRow, includeData sqlitestore.IncludeData) *sqlitestore.EntityData {
+	res := &sqlitestore.EntityData{}
+	if includeData.Key {
+		key := common.BytesToHash(r.EntityKey)
+		res.Key = &key
+	}
+	if includeData.Payload {
+		res.Value = r.Payload
+	}
+	if includeData.ContentType {
+		res.ContentType = &r.ContentType
+	}
+
+	switch {
+	case includeData.Attributes && includeData.SyntheticAttributes:
+		res.StringAttributes = filterAttributes(func(string) bool { return true }, r.StringAttributes.Values)
+		res.NumericAttributes = filterAttributes(func(string) bool { return true }, r.NumericAttributes.Values)
+	case includeData.Attributes:
+		res.StringAttributes = filterAttributes(isUserAttribute, r.StringAttributes.Values)
+		res.NumericAttributes = filterAttributes(isUserAttribute, r.NumericAttributes.Values)
+	case includeData.SyntheticAttributes:
+		res.StringAttributes = filterAttributes(isSyntheticAttribute, r.StringAttributes.Values)
+		res.NumericAttributes = filterAttributes(isSyntheticAttribute, r.NumericAttributes.Values)
+	}
+
+	numeric := func(key string) *uint64 {
+		v := r.NumericAttributes.Values[key]
+		return &v
+	}
+	if includeData.Expiration {
+		res.ExpiresAt = numeric("$expiration")
+	}
+	if includeData.Owner {
+		owner := common.HexToAddress(r.StringAttributes.Values["$owner"])
+		res.Owner = &owner
+	}
+	if includeData.CreatedAtBlock {
+		res.CreatedAtBlock = numeric("$createdAtBlock")
+	}
+	if includeData.LastModifiedAtBlock {
+		res.LastModifiedAtBlock = numeric("$lastModifiedAtBlock")
+	}
+	if includeData.TransactionIndexInBlock {
+		res.TransactionIndexInBlock = numeric("$txIndex")
+	}
+	if includeData.OperationIndexInTransaction {
+		res.OperationIndexInTransaction = numeric("$opIndex")
+	}
+
+	return res
+}
+
+func isSyntheticAttribute(k string) bool {
+	return strings.HasPrefix(k, "$")
+}
+
+func isUserAttribute(k string) bool {
+	return !strings.HasPrefix(k, "$")
+}
+
+func filterAttributes[T any](predicate func(string) bool, m map[string]T) []sqlitestore.Attribute[T] {
+	res := []sqlitestore.Attribute[T]{}
+	for k, v := range m {
+		if predicate(k) {
+			res = append(res, sqlitestore.Attribute[T]{Key: k, Value: v})
+		}
+	}
+	slices.SortFunc(res, func(a, b sqlitestore.Attribute[T]) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return res
+}
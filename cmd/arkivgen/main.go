@@ -0,0 +1,100 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// arkivgen generates typed Go bindings for Arkiv entities out of a schema.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/arkiv/bindgen"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	schemaFlag = &cli.StringFlag{
+		Name:     "schema",
+		Usage:    "Path to the YAML or JSON schema of the entities, - for STDIN",
+		Required: true,
+	}
+	pkgFlag = &cli.StringFlag{
+		Name:  "pkg",
+		Usage: "Package name to generate the binding into (default = package of the schema)",
+	}
+	outFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "Output file for the generated binding (default = stdout)",
+	}
+)
+
+var app = flags.NewApp("Arkiv entity binding code generator")
+
+func init() {
+	app.Name = "arkivgen"
+	app.Flags = []cli.Flag{
+		schemaFlag,
+		pkgFlag,
+		outFlag,
+	}
+	app.Action = generate
+}
+
+func generate(c *cli.Context) error {
+	var (
+		input = c.String(schemaFlag.Name)
+		data  []byte
+		err   error
+	)
+	if input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		utils.Fatalf("Failed to read schema: %v", err)
+	}
+	schema, err := bindgen.ParseSchema(data)
+	if err != nil {
+		utils.Fatalf("Invalid schema: %v", err)
+	}
+	code, err := bindgen.Generate(schema, c.String(pkgFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to generate entity binding: %v", err)
+	}
+	// Either flush it out to a file or display on the standard output
+	if !c.IsSet(outFlag.Name) {
+		fmt.Print(code)
+		return nil
+	}
+	if err := os.WriteFile(c.String(outFlag.Name), []byte(code), 0600); err != nil {
+		utils.Fatalf("Failed to write entity binding: %v", err)
+	}
+	return nil
+}
+
+func main() {
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelInfo, true)))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
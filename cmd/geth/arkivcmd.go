@@ -0,0 +1,113 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/arkiv/replay"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	arkivReplayFromFlag = &cli.Uint64Flag{
+		Name:     "from",
+		Usage:    "First block to replay",
+		Value:    1,
+		Category: flags.MiscCategory,
+	}
+	arkivReplayToFlag = &cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "Last block to replay (default = head block)",
+		Category: flags.MiscCategory,
+	}
+
+	arkivCommand = &cli.Command{
+		Name:  "arkiv",
+		Usage: "A set of commands for the Arkiv storage layer",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "replay",
+				Usage:  "Re-execute the Arkiv operations of a block range and compare them with the chain",
+				Action: arkivReplay,
+				Flags:  slices.Concat([]cli.Flag{arkivReplayFromFlag, arkivReplayToFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth arkiv replay --from N --to M
+
+Re-executes the Arkiv transactions, the housekeeping and the Arkiv calls of
+contracts of every block in the range on top of the state of its parent, and
+compares the logs, the storage slots of the Arkiv processor and the state root
+with what is stored. It stops at the first divergence and reports it together
+with the decoded operation.
+
+The state of the parent of every block has to be available, so older blocks
+can only be replayed on an archive node.`,
+			},
+		},
+	}
+)
+
+func arkivReplay(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	from := ctx.Uint64(arkivReplayFromFlag.Name)
+	to := chain.CurrentBlock().Number.Uint64()
+	if ctx.IsSet(arkivReplayToFlag.Name) {
+		to = ctx.Uint64(arkivReplayToFlag.Name)
+	}
+	if from == 0 || from > to {
+		return fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		txs    int
+		slots  int
+	)
+	for number := from; number <= to; number++ {
+		result, err := replay.Block(chain, number)
+		if err != nil {
+			return fmt.Errorf("failed to replay block %d: %w", number, err)
+		}
+		if result.Divergence != nil {
+			fmt.Println(result.Divergence)
+			return errors.New("arkiv execution diverges")
+		}
+		txs += result.ArkivTxs
+		slots += result.SlotChanges
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Replaying Arkiv blocks", "block", number, "to", to, "arkivtxs", txs, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Replayed Arkiv blocks without divergence", "from", from, "to", to, "arkivtxs", txs, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
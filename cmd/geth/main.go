@@ -88,6 +88,9 @@ var (
 		utils.TxPoolLifetimeFlag,
 		utils.TxPoolMaxTxGasLimitFlag,
 		utils.TxPoolDisableNonGolemBaseTransactions,
+		utils.TxPoolArkivAccountBytesFlag,
+		utils.TxPoolArkivAccountOpsFlag,
+		utils.TxPoolArkivTxMaxSizeFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
@@ -102,6 +105,10 @@ var (
 		utils.LogHistoryFlag,
 		utils.ArkivHistoricBlocksFlag,
 		utils.ArkivDatabaseDisabledFlag,
+		utils.ArkivSinkFileFlag,
+		utils.ArkivSinkFileFormatFlag,
+		utils.ArkivSinkWebhookFlag,
+		utils.ArkivSinkPluginFlag,
 		utils.LogNoHistoryFlag,
 		utils.LogExportCheckpointsFlag,
 		utils.StateHistoryFlag,
@@ -271,6 +278,8 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See arkivcmd.go
+		arkivCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
@@ -0,0 +1,61 @@
+package useraccount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// When SIGNER_URL is set, for instance to http://localhost:8550 or to the IPC
+// socket of clef, transactions are signed through the external API of the
+// signer instead of with the wallet file. SIGNER_ACCOUNT selects the account
+// to use if the signer lists more than one.
+const (
+	signerURLEnv     = "SIGNER_URL"
+	signerAccountEnv = "SIGNER_ACCOUNT"
+)
+
+func loadExternal(url string) (*UserAccount, error) {
+	signer, err := external.NewExternalSigner(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+
+	// Listing the accounts may have to be approved in the signer
+	available := signer.Accounts()
+
+	if addr, ok := os.LookupEnv(signerAccountEnv); ok {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid %s: %s", signerAccountEnv, addr)
+		}
+		account := accounts.Account{Address: common.HexToAddress(addr)}
+		if !signer.Contains(account) {
+			return nil, fmt.Errorf("account %s is not available in the external signer", account.Address.Hex())
+		}
+		return &UserAccount{Address: account.Address, signer: signer}, nil
+	}
+
+	switch len(available) {
+	case 0:
+		return nil, errors.New("the external signer has no accounts available")
+	case 1:
+		return &UserAccount{Address: available[0].Address, signer: signer}, nil
+	default:
+		return nil, fmt.Errorf("the external signer has %d accounts available, select one with %s", len(available), signerAccountEnv)
+	}
+}
+
+// SignTx signs a transaction with the private key of the account or, if it was
+// loaded from an external signer, through the signer.
+func (a *UserAccount) SignTx(chainID *big.Int, tx types.TxData) (*types.Transaction, error) {
+	if a.signer != nil {
+		return a.signer.SignTx(accounts.Account{Address: a.Address}, types.NewTx(tx), chainID)
+	}
+	return types.SignNewTx(a.PrivateKey, types.LatestSignerForChainID(chainID), tx)
+}
@@ -11,6 +11,7 @@ import (
 	"syscall"
 
 	"github.com/adrg/xdg"
+	"github.com/ethereum/go-ethereum/accounts/external"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -20,9 +21,16 @@ import (
 type UserAccount struct {
 	Address    common.Address
 	PrivateKey *ecdsa.PrivateKey
+
+	// Set instead of the private key when signing through an external signer
+	signer *external.ExternalSigner
 }
 
 func Load() (*UserAccount, error) {
+	if url := os.Getenv(signerURLEnv); url != "" {
+		return loadExternal(url)
+	}
+
 	walletPath, err := xdg.ConfigFile(WalletPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config file path: %w", err)
@@ -217,13 +217,8 @@ func Create() *cli.Command {
 				GasFeeCap: gasFeeCap,
 			}
 
-			// Use the London signer since we're using a dynamic fee transaction
-			signer := types.LatestSignerForChainID(chainID)
-
-			// return nil, fmt.Errorf("signer: %#v", signer)
-
 			// Create and sign the transaction
-			signedTx, err := types.SignNewTx(userAccount.PrivateKey, signer, tx)
+			signedTx, err := userAccount.SignTx(chainID, tx)
 			if err != nil {
 				return fmt.Errorf("failed to sign transaction: %w", err)
 			}
@@ -127,11 +127,8 @@ func Update() *cli.Command {
 				GasFeeCap: big.NewInt(5e9), // 5 Gwei
 			}
 
-			// Use the London signer since we're using a dynamic fee transaction
-			signer := types.LatestSignerForChainID(chainID)
-
 			// Create and sign the transaction
-			signedTx, err := types.SignNewTx(userAccount.PrivateKey, signer, tx)
+			signedTx, err := userAccount.SignTx(chainID, tx)
 			if err != nil {
 				return fmt.Errorf("failed to sign transaction: %w", err)
 			}
@@ -10,6 +10,8 @@ import (
 	"github.com/ethereum/go-ethereum/cmd/golembase/entity"
 	"github.com/ethereum/go-ethereum/cmd/golembase/query"
 	"github.com/ethereum/go-ethereum/cmd/golembase/state"
+	"github.com/ethereum/go-ethereum/cmd/golembase/tx"
+	"github.com/ethereum/go-ethereum/cmd/golembase/watch"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,6 +29,8 @@ func main() {
 			cat.Cat(),
 			query.Query(),
 			state.State(),
+			tx.Tx(),
+			watch.Watch(),
 		},
 	}
 
@@ -0,0 +1,209 @@
+package submit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/holiman/uint256"
+	"gopkg.in/yaml.v3"
+)
+
+// OpsFile is the declarative list of the operations of an Arkiv transaction.
+// Payloads are given inline or read from files, relative to the operations
+// file, and annotations are given as maps.
+//
+//	create:
+//	  - btl: 1000
+//	    contentType: application/json
+//	    payloadFile: data/config.json
+//	    stringAnnotations: {kind: config}
+//	    numericAnnotations: {version: 2}
+//	update:
+//	  - key: 0x...
+//	    btl: 1000
+//	    contentType: text/plain
+//	    payload: new content
+//	delete: [0x...]
+//	extend:
+//	  - key: 0x...
+//	    blocks: 500
+//	changeOwner:
+//	  - key: 0x...
+//	    newOwner: 0x...
+//	renew:
+//	  - key: 0x...
+//	    period: 1000
+//	    deposit: 1000000000000000
+type OpsFile struct {
+	Create      []CreateOp      `json:"create" yaml:"create"`
+	Update      []UpdateOp      `json:"update" yaml:"update"`
+	Delete      []common.Hash   `json:"delete" yaml:"delete"`
+	Extend      []ExtendOp      `json:"extend" yaml:"extend"`
+	ChangeOwner []ChangeOwnerOp `json:"changeOwner" yaml:"changeOwner"`
+	Renew       []RenewOp       `json:"renew" yaml:"renew"`
+}
+
+type CreateOp struct {
+	BTL                uint64            `json:"btl" yaml:"btl"`
+	ContentType        string            `json:"contentType" yaml:"contentType"`
+	Payload            *string           `json:"payload" yaml:"payload"`
+	PayloadFile        string            `json:"payloadFile" yaml:"payloadFile"`
+	StringAnnotations  map[string]string `json:"stringAnnotations" yaml:"stringAnnotations"`
+	NumericAnnotations map[string]uint64 `json:"numericAnnotations" yaml:"numericAnnotations"`
+}
+
+type UpdateOp struct {
+	Key      common.Hash `json:"key" yaml:"key"`
+	CreateOp `yaml:",inline"`
+}
+
+type ExtendOp struct {
+	Key    common.Hash `json:"key" yaml:"key"`
+	Blocks uint64      `json:"blocks" yaml:"blocks"`
+}
+
+type ChangeOwnerOp struct {
+	Key      common.Hash    `json:"key" yaml:"key"`
+	NewOwner common.Address `json:"newOwner" yaml:"newOwner"`
+}
+
+// RenewOp sets the automatic renewal of an entity. The deposit is in wei, and
+// the deposits of all the renewals are sent as the value of the transaction.
+type RenewOp struct {
+	Key     common.Hash  `json:"key" yaml:"key"`
+	Period  uint64       `json:"period" yaml:"period"`
+	Deposit *uint256.Int `json:"deposit" yaml:"deposit"`
+}
+
+// LoadOpsFile reads an operations file, as JSON if its name ends with .json
+// and as YAML otherwise, and builds the transaction out of it.
+func LoadOpsFile(path string) (*storagetx.ArkivTransaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operations file: %w", err)
+	}
+
+	ops := &OpsFile{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(ops)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(ops)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operations file %s: %w", path, err)
+	}
+
+	return ops.Transaction(filepath.Dir(path))
+}
+
+// Transaction builds the Arkiv transaction of the operations. Payload files are
+// resolved relative to dir.
+func (ops *OpsFile) Transaction(dir string) (*storagetx.ArkivTransaction, error) {
+	tx := &storagetx.ArkivTransaction{
+		Delete: ops.Delete,
+	}
+	for i, op := range ops.Create {
+		payload, err := op.payload(dir)
+		if err != nil {
+			return nil, fmt.Errorf("create[%d]: %w", i, err)
+		}
+		tx.Create = append(tx.Create, storagetx.ArkivCreate{
+			BTL:                op.BTL,
+			ContentType:        op.ContentType,
+			Payload:            payload,
+			StringAnnotations:  stringAnnotations(op.StringAnnotations),
+			NumericAnnotations: numericAnnotations(op.NumericAnnotations),
+		})
+	}
+	for i, op := range ops.Update {
+		payload, err := op.payload(dir)
+		if err != nil {
+			return nil, fmt.Errorf("update[%d]: %w", i, err)
+		}
+		tx.Update = append(tx.Update, storagetx.ArkivUpdate{
+			EntityKey:          op.Key,
+			BTL:                op.BTL,
+			ContentType:        op.ContentType,
+			Payload:            payload,
+			StringAnnotations:  stringAnnotations(op.StringAnnotations),
+			NumericAnnotations: numericAnnotations(op.NumericAnnotations),
+		})
+	}
+	for _, op := range ops.Extend {
+		tx.Extend = append(tx.Extend, storagetx.ExtendBTL{EntityKey: op.Key, NumberOfBlocks: op.Blocks})
+	}
+	for _, op := range ops.ChangeOwner {
+		tx.ChangeOwner = append(tx.ChangeOwner, storagetx.ArkivChangeOwner{EntityKey: op.Key, NewOwner: op.NewOwner})
+	}
+	for _, op := range ops.Renew {
+		tx.Renew = append(tx.Renew, storagetx.ArkivRenew{EntityKey: op.Key, Period: op.Period, Deposit: op.Deposit})
+	}
+	return tx, nil
+}
+
+// deposits returns the sum of the renewal deposits of the transaction, which
+// the transaction has to send as its value.
+func deposits(tx *storagetx.ArkivTransaction) (*uint256.Int, error) {
+	total := new(uint256.Int)
+	for i, op := range tx.Renew {
+		if op.Deposit == nil {
+			continue
+		}
+		if _, overflow := total.AddOverflow(total, op.Deposit); overflow {
+			return nil, fmt.Errorf("renew[%d]: deposits overflow", i)
+		}
+	}
+	return total, nil
+}
+
+func (op *CreateOp) payload(dir string) ([]byte, error) {
+	switch {
+	case op.Payload != nil && op.PayloadFile != "":
+		return nil, errors.New("both payload and payloadFile are set")
+	case op.PayloadFile != "":
+		path := op.PayloadFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload file: %w", err)
+		}
+		return payload, nil
+	case op.Payload != nil:
+		return []byte(*op.Payload), nil
+	default:
+		return []byte{}, nil
+	}
+}
+
+// The annotations are sorted by key, so that a file always results in the same
+// transaction.
+func stringAnnotations(m map[string]string) []storagetx.StringAnnotation {
+	var annotations []storagetx.StringAnnotation
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		annotations = append(annotations, storagetx.StringAnnotation{Key: key, Value: m[key]})
+	}
+	return annotations
+}
+
+func numericAnnotations(m map[string]uint64) []storagetx.NumericAnnotation {
+	var annotations []storagetx.NumericAnnotation
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		annotations = append(annotations, storagetx.NumericAnnotation{Key: key, Value: m[key]})
+	}
+	return annotations
+}
@@ -0,0 +1,248 @@
+package submit
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/cmd/golembase/account/pkg/useraccount"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/urfave/cli/v2"
+)
+
+func Submit() *cli.Command {
+
+	cfg := struct {
+		nodeURL string
+		file    string
+		dryRun  bool
+	}{}
+	return &cli.Command{
+		Name:  "submit",
+		Usage: "Submit the operations of a YAML or JSON file as a single transaction",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "node-url",
+				Usage:       "The URL of the node to connect to",
+				Value:       "http://localhost:8545",
+				EnvVars:     []string{"NODE_URL"},
+				Destination: &cfg.nodeURL,
+			},
+			&cli.StringFlag{
+				Name:        "file",
+				Aliases:     []string{"f"},
+				Usage:       "The operations file, JSON if its name ends with .json and YAML otherwise",
+				Required:    true,
+				Destination: &cfg.file,
+			},
+			&cli.BoolFlag{
+				Name:        "dry-run",
+				Usage:       "Validate the operations and show the summary without submitting them",
+				Destination: &cfg.dryRun,
+			},
+		},
+		Action: func(c *cli.Context) error {
+
+			ctx, cancel := signal.NotifyContext(c.Context, os.Interrupt)
+			defer cancel()
+
+			storageTx, err := LoadOpsFile(cfg.file)
+			if err != nil {
+				return err
+			}
+			if err := storageTx.Validate(); err != nil {
+				return fmt.Errorf("invalid operations: %w", err)
+			}
+			value, err := deposits(storageTx)
+			if err != nil {
+				return fmt.Errorf("invalid operations: %w", err)
+			}
+
+			// Encode the storage transaction
+			txData, err := rlp.EncodeToBytes(storageTx)
+			if err != nil {
+				return fmt.Errorf("failed to encode storage tx: %w", err)
+			}
+
+			compressedTxData, err := compression.BrotliCompress(txData)
+			if err != nil {
+				return fmt.Errorf("failed to compress tx data: %w", err)
+			}
+
+			printSummary(storageTx, value, len(txData), len(compressedTxData))
+			if cfg.dryRun {
+				return nil
+			}
+
+			userAccount, err := useraccount.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load user account: %w", err)
+			}
+
+			// Connect to the geth node
+			client, err := ethclient.DialContext(ctx, cfg.nodeURL)
+			if err != nil {
+				return fmt.Errorf("failed to connect to node: %w", err)
+			}
+			defer client.Close()
+
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get chain ID: %w", err)
+			}
+
+			nonce, err := client.PendingNonceAt(ctx, userAccount.Address)
+			if err != nil {
+				return fmt.Errorf("failed to get nonce: %w", err)
+			}
+
+			gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
+				From:  userAccount.Address,
+				To:    &address.ArkivProcessorAddress,
+				Data:  compressedTxData,
+				Value: value.ToBig(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to estimate gas: %w", err)
+			}
+
+			gasTipCap, err := client.SuggestGasTipCap(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+			}
+
+			gasFeeCap, err := client.SuggestGasPrice(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to suggest gas fee cap: %w", err)
+			}
+
+			tx := &types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     nonce,
+				Gas:       gasLimit,
+				Data:      compressedTxData,
+				To:        &address.ArkivProcessorAddress,
+				Value:     value.ToBig(),
+				GasTipCap: gasTipCap,
+				GasFeeCap: gasFeeCap,
+			}
+
+			signedTx, err := userAccount.SignTx(chainID, tx)
+			if err != nil {
+				return fmt.Errorf("failed to sign transaction: %w", err)
+			}
+
+			err = client.SendTransaction(ctx, signedTx)
+			if err != nil {
+				return fmt.Errorf("failed to send tx: %w", err)
+			}
+			fmt.Println("Submitted transaction", signedTx.Hash().Hex())
+
+			receipt, err := bind.WaitMinedHash(ctx, client, signedTx.Hash())
+			if err != nil {
+				return fmt.Errorf("failed to wait for tx: %w", err)
+			}
+
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("tx %s failed in block %d", signedTx.Hash().Hex(), receipt.BlockNumber)
+			}
+
+			fmt.Println("Included in block", receipt.BlockNumber, "gas used", receipt.GasUsed)
+			printResults(receipt.Logs)
+
+			return nil
+		},
+	}
+}
+
+func printSummary(tx *storagetx.ArkivTransaction, value *uint256.Int, size, compressedSize int) {
+	payloadBytes := 0
+	for _, op := range tx.Create {
+		payloadBytes += len(op.Payload)
+	}
+	for _, op := range tx.Update {
+		payloadBytes += len(op.Payload)
+	}
+
+	fmt.Printf(
+		"Operations: %d create, %d update, %d delete, %d extend, %d change owner, %d renew\n",
+		len(tx.Create), len(tx.Update), len(tx.Delete), len(tx.Extend), len(tx.ChangeOwner), len(tx.Renew),
+	)
+	fmt.Printf("Payload: %d bytes, transaction data: %d bytes (%d compressed)\n", payloadBytes, size, compressedSize)
+
+	for i, op := range tx.Create {
+		fmt.Printf(
+			"  create[%d] btl=%d contentType=%s payload=%d bytes annotations=%d\n",
+			i, op.BTL, op.ContentType, len(op.Payload), len(op.StringAnnotations)+len(op.NumericAnnotations),
+		)
+	}
+	for i, op := range tx.Update {
+		fmt.Printf(
+			"  update[%d] key=%s btl=%d contentType=%s payload=%d bytes annotations=%d\n",
+			i, op.EntityKey.Hex(), op.BTL, op.ContentType, len(op.Payload), len(op.StringAnnotations)+len(op.NumericAnnotations),
+		)
+	}
+	for i, key := range tx.Delete {
+		fmt.Printf("  delete[%d] key=%s\n", i, key.Hex())
+	}
+	for i, op := range tx.Extend {
+		fmt.Printf("  extend[%d] key=%s blocks=%d\n", i, op.EntityKey.Hex(), op.NumberOfBlocks)
+	}
+	for i, op := range tx.ChangeOwner {
+		fmt.Printf("  changeOwner[%d] key=%s newOwner=%s\n", i, op.EntityKey.Hex(), op.NewOwner.Hex())
+	}
+	for i, op := range tx.Renew {
+		fmt.Printf("  renew[%d] key=%s period=%d deposit=%v wei\n", i, op.EntityKey.Hex(), op.Period, op.Deposit)
+	}
+	if !value.IsZero() {
+		fmt.Printf("Value: %s wei\n", value.Dec())
+	}
+}
+
+// printResults prints the keys and expirations of the entities from the logs of
+// the transaction, see golem-base/logs for their layout.
+func printResults(logs []*types.Log) {
+	word := func(l *types.Log, i int) uint64 {
+		if len(l.Data) < (i+1)*32 {
+			return 0
+		}
+		return new(uint256.Int).SetBytes(l.Data[i*32 : (i+1)*32]).Uint64()
+	}
+
+	for _, l := range logs {
+		if l.Address != address.ArkivProcessorAddress || len(l.Topics) < 2 {
+			continue
+		}
+		key := l.Topics[1].Hex()
+		switch l.Topics[0] {
+		case arkivlogs.ArkivEntityCreated:
+			fmt.Println("Entity created", "key", key, "expiresAt", word(l, 0))
+		case arkivlogs.ArkivEntityUpdated:
+			fmt.Println("Entity updated", "key", key, "expiresAt", word(l, 1))
+		case arkivlogs.ArkivEntityBTLExtended:
+			fmt.Println("Entity extended", "key", key, "expiresAt", word(l, 1))
+		case arkivlogs.ArkivEntityDeleted:
+			fmt.Println("Entity deleted", "key", key)
+		case arkivlogs.ArkivEntityOwnerChanged:
+			if len(l.Topics) < 4 {
+				continue
+			}
+			fmt.Println("Entity owner changed", "key", key, "newOwner", common.BytesToAddress(l.Topics[3].Bytes()).Hex())
+		case arkivlogs.ArkivEntityRenewalSet:
+			if len(l.Data) < 64 {
+				continue
+			}
+			fmt.Println("Entity renewal set", "key", key, "period", word(l, 0), "budget", new(uint256.Int).SetBytes(l.Data[32:64]).Dec())
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package tx
+
+import (
+	"github.com/ethereum/go-ethereum/cmd/golembase/tx/submit"
+	"github.com/urfave/cli/v2"
+)
+
+func Tx() *cli.Command {
+	return &cli.Command{
+		Name:  "tx",
+		Usage: "Submit transactions of several operations",
+		Subcommands: []*cli.Command{
+			submit.Submit(),
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/holiman/uint256"
+)
+
+// eventTypes maps the topics of the entity events to their names.
+var eventTypes = map[common.Hash]string{
+	arkivlogs.ArkivEntityCreated:      "created",
+	arkivlogs.ArkivEntityUpdated:      "updated",
+	arkivlogs.ArkivEntityDeleted:      "deleted",
+	arkivlogs.ArkivEntityExpired:      "expired",
+	arkivlogs.ArkivEntityBTLExtended:  "btlExtended",
+	arkivlogs.ArkivEntityOwnerChanged: "ownerChanged",
+	arkivlogs.ArkivEntityRenewed:      "renewed",
+}
+
+// Event is an entity event decoded from a log of the processor.
+type Event struct {
+	Type     string      `json:"type"`
+	Block    uint64      `json:"block"`
+	TxHash   common.Hash `json:"txHash"`
+	TxIndex  uint        `json:"txIndex"`
+	LogIndex uint        `json:"logIndex"`
+	Removed  bool        `json:"removed,omitempty"`
+
+	Key          common.Hash     `json:"key"`
+	Owner        common.Address  `json:"owner"`
+	NewOwner     *common.Address `json:"newOwner,omitempty"`
+	OldExpiresAt *uint64         `json:"oldExpiresAt,omitempty"`
+	ExpiresAt    *uint64         `json:"expiresAt,omitempty"`
+	Cost         *hexutil.Big    `json:"cost,omitempty"`
+
+	// Set only when the payloads are fetched
+	ContentType string        `json:"contentType,omitempty"`
+	Payload     hexutil.Bytes `json:"payload,omitempty"`
+}
+
+// decodeLog decodes an entity event. It returns nil for the other logs of the
+// processor.
+func decodeLog(l types.Log) (*Event, error) {
+	if len(l.Topics) == 0 {
+		return nil, nil
+	}
+	typ, ok := eventTypes[l.Topics[0]]
+	if !ok {
+		return nil, nil
+	}
+	if len(l.Topics) < 3 {
+		return nil, fmt.Errorf("%s event in tx %s has %d topics", typ, l.TxHash.Hex(), len(l.Topics))
+	}
+
+	ev := &Event{
+		Type:     typ,
+		Block:    l.BlockNumber,
+		TxHash:   l.TxHash,
+		TxIndex:  l.TxIndex,
+		LogIndex: l.Index,
+		Removed:  l.Removed,
+		Key:      l.Topics[1],
+		Owner:    common.BytesToAddress(l.Topics[2].Bytes()),
+	}
+
+	// The data words of the events, see golem-base/logs
+	words := make([]*uint256.Int, len(l.Data)/32)
+	for i := range words {
+		words[i] = new(uint256.Int).SetBytes(l.Data[i*32 : (i+1)*32])
+	}
+	word := func(i int) *uint64 {
+		v := words[i].Uint64()
+		return &v
+	}
+
+	switch l.Topics[0] {
+	case arkivlogs.ArkivEntityCreated:
+		if len(words) < 2 {
+			return nil, fmt.Errorf("created event in tx %s has %d data words", l.TxHash.Hex(), len(words))
+		}
+		ev.ExpiresAt = word(0)
+		ev.Cost = (*hexutil.Big)(words[1].ToBig())
+	case arkivlogs.ArkivEntityUpdated, arkivlogs.ArkivEntityBTLExtended, arkivlogs.ArkivEntityRenewed:
+		if len(words) < 3 {
+			return nil, fmt.Errorf("%s event in tx %s has %d data words", typ, l.TxHash.Hex(), len(words))
+		}
+		ev.OldExpiresAt = word(0)
+		ev.ExpiresAt = word(1)
+		ev.Cost = (*hexutil.Big)(words[2].ToBig())
+	case arkivlogs.ArkivEntityOwnerChanged:
+		if len(l.Topics) < 4 {
+			return nil, fmt.Errorf("ownerChanged event in tx %s has %d topics", l.TxHash.Hex(), len(l.Topics))
+		}
+		newOwner := common.BytesToAddress(l.Topics[3].Bytes())
+		ev.NewOwner = &newOwner
+	}
+	return ev, nil
+}
+
+// involves reports whether the owner, or the new owner, of the event is addr.
+func (ev *Event) involves(addr common.Address) bool {
+	return ev.Owner == addr || (ev.NewOwner != nil && *ev.NewOwner == addr)
+}
+
+// gone reports whether the entity does not exist anymore after the event.
+func (ev *Event) gone() bool {
+	return ev.Type == "deleted" || ev.Type == "expired"
+}
+
+// String formats the event as a single human-readable line.
+func (ev *Event) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "block %d tx %s %-12s key=%s owner=%s", ev.Block, ev.TxHash.TerminalString(), ev.Type, ev.Key.Hex(), ev.Owner.Hex())
+	if ev.NewOwner != nil {
+		fmt.Fprintf(&b, " newOwner=%s", ev.NewOwner.Hex())
+	}
+	if ev.OldExpiresAt != nil {
+		fmt.Fprintf(&b, " expiresAt=%d->%d", *ev.OldExpiresAt, *ev.ExpiresAt)
+	} else if ev.ExpiresAt != nil {
+		fmt.Fprintf(&b, " expiresAt=%d", *ev.ExpiresAt)
+	}
+	if ev.Cost != nil && ev.Cost.ToInt().Sign() > 0 {
+		fmt.Fprintf(&b, " cost=%s", ev.Cost.ToInt())
+	}
+	if ev.ContentType != "" {
+		fmt.Fprintf(&b, " contentType=%s", ev.ContentType)
+	}
+	if ev.Payload != nil {
+		if utf8.Valid(ev.Payload) {
+			fmt.Fprintf(&b, " payload=%q", string(ev.Payload))
+		} else {
+			fmt.Fprintf(&b, " payload=%s", ev.Payload)
+		}
+	}
+	if ev.Removed {
+		b.WriteString(" (removed by reorg)")
+	}
+	return b.String()
+}
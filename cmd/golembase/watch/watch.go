@@ -0,0 +1,281 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+func Watch() *cli.Command {
+	cfg := struct {
+		nodeURL string
+		owner   string
+		keys    cli.StringSlice
+		query   string
+		payload bool
+		json    bool
+	}{}
+	return &cli.Command{
+		Name:  "watch",
+		Usage: "stream entity events as they happen",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "node-url",
+				Usage:       "The websocket URL of the node to connect to",
+				Value:       "ws://localhost:8546",
+				EnvVars:     []string{"NODE_WS_URL"},
+				Destination: &cfg.nodeURL,
+			},
+			&cli.StringFlag{
+				Name:        "owner",
+				Usage:       "Only show events of entities owned by, or transferred to, this address",
+				Destination: &cfg.owner,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key",
+				Usage:       "Only show events of the entity with this key (can be repeated)",
+				Destination: &cfg.keys,
+			},
+			&cli.StringFlag{
+				Name:        "query",
+				Usage:       "Only show events of entities matching this query",
+				Destination: &cfg.query,
+			},
+			&cli.BoolFlag{
+				Name:        "payload",
+				Usage:       "Fetch the payload of created and updated entities",
+				Destination: &cfg.payload,
+			},
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "Print the events as JSON lines",
+				Destination: &cfg.json,
+			},
+		},
+		Action: func(c *cli.Context) error {
+
+			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt)
+			defer stop()
+
+			var owner *common.Address
+			if cfg.owner != "" {
+				if !common.IsHexAddress(cfg.owner) {
+					return fmt.Errorf("invalid owner address: %s", cfg.owner)
+				}
+				addr := common.HexToAddress(cfg.owner)
+				owner = &addr
+			}
+			var keys []common.Hash
+			for _, k := range cfg.keys.Value() {
+				key, err := parseKey(k)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+
+			rpcClient, err := rpc.DialContext(ctx, cfg.nodeURL)
+			if err != nil {
+				return fmt.Errorf("failed to connect to node: %w", err)
+			}
+			defer rpcClient.Close()
+
+			topics := [][]common.Hash{make([]common.Hash, 0, len(eventTypes))}
+			for topic := range eventTypes {
+				topics[0] = append(topics[0], topic)
+			}
+			if len(keys) > 0 {
+				topics = append(topics, keys)
+			}
+
+			logs := make(chan types.Log, 128)
+			sub, err := ethclient.NewClient(rpcClient).SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+				Addresses: []common.Address{address.ArkivProcessorAddress},
+				Topics:    topics,
+			}, logs)
+			if err != nil {
+				return fmt.Errorf("failed to subscribe to logs: %w", err)
+			}
+			defer sub.Unsubscribe()
+
+			// The entities matching the query are listed after subscribing, so
+			// that no event is missed in between
+			w := &watcher{
+				rpc:     rpcClient,
+				owner:   owner,
+				query:   cfg.query,
+				payload: cfg.payload,
+			}
+			if cfg.query != "" {
+				if w.matching, err = w.matchingKeys(ctx); err != nil {
+					return err
+				}
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case err := <-sub.Err():
+					return fmt.Errorf("subscription failed: %w", err)
+				case l := <-logs:
+					ev, err := decodeLog(l)
+					if err != nil {
+						return err
+					}
+					if ev == nil {
+						continue
+					}
+					show, err := w.filter(ctx, ev)
+					if err != nil {
+						return err
+					}
+					if !show {
+						continue
+					}
+					if cfg.json {
+						if err := enc.Encode(ev); err != nil {
+							return err
+						}
+					} else {
+						fmt.Println(ev)
+					}
+				}
+			}
+		},
+	}
+}
+
+func parseKey(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid entity key: %s", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// watcher filters the events and adds the payloads to them.
+type watcher struct {
+	rpc     *rpc.Client
+	owner   *common.Address
+	query   string
+	payload bool
+
+	// matching holds the keys of the entities that matched the query when
+	// their last event was seen, so that their deletion is shown as well.
+	matching map[common.Hash]bool
+}
+
+// filter reports whether the event is to be shown, and fetches its payload if
+// it is.
+func (w *watcher) filter(ctx context.Context, ev *Event) (bool, error) {
+	if w.owner != nil && !ev.involves(*w.owner) {
+		return false, nil
+	}
+	if w.query != "" {
+		matched := w.matching[ev.Key]
+		switch {
+		case ev.Removed:
+		case ev.gone():
+			delete(w.matching, ev.Key)
+		default:
+			// The query is evaluated against the store, once it has ingested
+			// the block of the event.
+			entity, err := w.entity(ctx, fmt.Sprintf("$key = %s && (%s)", ev.Key.Hex(), w.query), ev.Block)
+			if err != nil {
+				return false, err
+			}
+			if entity != nil {
+				w.matching[ev.Key] = true
+			} else {
+				delete(w.matching, ev.Key)
+			}
+			matched = matched || entity != nil
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if w.payload && !ev.Removed && (ev.Type == "created" || ev.Type == "updated") {
+		entity, err := w.entity(ctx, fmt.Sprintf("$key = %s", ev.Key.Hex()), ev.Block)
+		if err != nil {
+			return false, err
+		}
+		// The entity may be gone already by the time it is fetched
+		if entity != nil {
+			if entity.ContentType != nil {
+				ev.ContentType = *entity.ContentType
+			}
+			ev.Payload = entity.Value
+			if ev.Payload == nil {
+				ev.Payload = []byte{}
+			}
+		}
+	}
+	return true, nil
+}
+
+// entity runs a query that matches at most one entity, once the store has
+// ingested the given block.
+func (w *watcher) entity(ctx context.Context, query string, block uint64) (*sqlitestore.EntityData, error) {
+	var res struct {
+		Data []sqlitestore.EntityData `json:"data"`
+	}
+	options := map[string]any{
+		"minBlock":    block,
+		"waitTimeout": 60_000,
+		"includeData": sqlitestore.IncludeData{
+			Key:         true,
+			Payload:     w.payload,
+			ContentType: w.payload,
+		},
+	}
+	if err := w.rpc.CallContext(ctx, &res, "arkiv_query", query, options); err != nil {
+		return nil, fmt.Errorf("failed to query entity: %w", err)
+	}
+	if len(res.Data) == 0 {
+		return nil, nil
+	}
+	return &res.Data[0], nil
+}
+
+// matchingKeys returns the keys of all the entities that match the query.
+func (w *watcher) matchingKeys(ctx context.Context) (map[common.Hash]bool, error) {
+	keys := map[common.Hash]bool{}
+	cursor := ""
+	for {
+		var res struct {
+			Data   []sqlitestore.EntityData `json:"data"`
+			Cursor *string                  `json:"cursor"`
+		}
+		options := map[string]any{
+			"includeData": sqlitestore.IncludeData{Key: true},
+			"cursor":      cursor,
+		}
+		if err := w.rpc.CallContext(ctx, &res, "arkiv_query", w.query, options); err != nil {
+			return nil, fmt.Errorf("failed to run query: %w", err)
+		}
+		for _, entity := range res.Data {
+			if entity.Key != nil {
+				keys[*entity.Key] = true
+			}
+		}
+		if res.Cursor == nil || *res.Cursor == "" {
+			return keys, nil
+		}
+		cursor = *res.Cursor
+	}
+}
@@ -37,7 +37,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
-	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/arkiv/eventsink"
 	bparams "github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
@@ -496,6 +496,24 @@ var (
 		Value:    ethconfig.Defaults.TxPool.DisableNonGolemBaseTransactions,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolArkivAccountBytesFlag = &cli.Uint64Flag{
+		Name:     "txpool.arkiv.accountbytes",
+		Usage:    "Maximum decompressed Arkiv payload bytes in the pool per account",
+		Value:    ethconfig.Defaults.TxPool.ArkivAccountBytes,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolArkivAccountOpsFlag = &cli.Uint64Flag{
+		Name:     "txpool.arkiv.accountops",
+		Usage:    "Maximum number of Arkiv operations in the pool per account",
+		Value:    ethconfig.Defaults.TxPool.ArkivAccountOps,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolArkivTxMaxSizeFlag = &cli.Uint64Flag{
+		Name:     "txpool.arkiv.txmaxsize",
+		Usage:    "Maximum size of a single Arkiv transaction in the pool",
+		Value:    ethconfig.Defaults.TxPool.ArkivTxMaxSize,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -931,6 +949,27 @@ var (
 		Category: flags.MiscCategory,
 		Value:    false,
 	}
+	ArkivSinkFileFlag = &cli.PathFlag{
+		Name:     "arkiv.sink.file",
+		Usage:    "Path of an append-only file receiving the Arkiv event stream",
+		Category: flags.MiscCategory,
+	}
+	ArkivSinkFileFormatFlag = &cli.StringFlag{
+		Name:     "arkiv.sink.file.format",
+		Usage:    "Encoding of the Arkiv event stream file (jsonl or rlp)",
+		Category: flags.MiscCategory,
+		Value:    eventsink.FormatJSONL,
+	}
+	ArkivSinkWebhookFlag = &cli.StringFlag{
+		Name:     "arkiv.sink.webhook",
+		Usage:    "URL (http://, https:// or unix://) the Arkiv event stream batches are posted to",
+		Category: flags.MiscCategory,
+	}
+	ArkivSinkPluginFlag = &cli.StringSliceFlag{
+		Name:     "arkiv.sink.plugin",
+		Usage:    "Path of a Go plugin consuming the Arkiv event stream (can be repeated)",
+		Category: flags.MiscCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -1596,6 +1635,11 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 
 	cfg.ArkivDatabaseDisabled = ctx.Bool(ArkivDatabaseDisabledFlag.Name)
 
+	cfg.ArkivSinkFile = ctx.Path(ArkivSinkFileFlag.Name)
+	cfg.ArkivSinkFileFormat = ctx.String(ArkivSinkFileFormatFlag.Name)
+	cfg.ArkivSinkWebhook = ctx.String(ArkivSinkWebhookFlag.Name)
+	cfg.ArkivSinkPlugins = ctx.StringSlice(ArkivSinkPluginFlag.Name)
+
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("Option --log.backtrace flag is deprecated")
@@ -1707,6 +1751,15 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolDisableNonGolemBaseTransactions.Name) {
 		cfg.DisableNonGolemBaseTransactions = ctx.Bool(TxPoolDisableNonGolemBaseTransactions.Name)
 	}
+	if ctx.IsSet(TxPoolArkivAccountBytesFlag.Name) {
+		cfg.ArkivAccountBytes = ctx.Uint64(TxPoolArkivAccountBytesFlag.Name)
+	}
+	if ctx.IsSet(TxPoolArkivAccountOpsFlag.Name) {
+		cfg.ArkivAccountOps = ctx.Uint64(TxPoolArkivAccountOpsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolArkivTxMaxSizeFlag.Name) {
+		cfg.ArkivTxMaxSize = ctx.Uint64(TxPoolArkivTxMaxSizeFlag.Name)
+	}
 	if ctx.IsSet(MinerEffectiveGasLimitFlag.Name) {
 		// While technically this is a miner config parameter, we also want the txpool to enforce
 		// it to avoid accepting transactions that can never be included in a block.
@@ -2526,17 +2579,11 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	// 	Fatalf("failed to create SQLStore: %v", err)
 	// }
 
-	batchIterator, onNewHead := dbevents.NewChainBatchIterator(chainDb, 0)
-
-	go func() {
-		for b := range batchIterator {
-			log.Info("arkiv new batch", "from", b.Batch.Blocks[0].Number, "to", b.Batch.Blocks[len(b.Batch.Blocks)-1].Number)
-		}
-	}()
-
-	chain, err := core.NewBlockChainWithOnNewBlock(chainDb, gspec, engine, options, onNewHead)
+	// The Arkiv event stream is consumed by the node itself (see eth.New), the
+	// offline chain commands don't feed the store or the sinks.
+	chain, err := core.NewBlockChain(chainDb, gspec, engine, options)
 	if err != nil {
-		Fatalf("Can't create BlockChain with onNewBlock: %v", err)
+		Fatalf("Can't create BlockChain: %v", err)
 	}
 	return chain, chainDb
 
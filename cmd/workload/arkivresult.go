@@ -0,0 +1,282 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// arkivResult is the outcome of an Arkiv workload run. Result files of runs with
+// the same configuration can be compared across releases.
+type arkivResult struct {
+	ClientVersion string      `json:"clientVersion"`
+	ChainID       uint64      `json:"chainId"`
+	Run           string      `json:"run"`
+	StartBlock    uint64      `json:"startBlock"`
+	EndBlock      uint64      `json:"endBlock"`
+	Config        arkivConfig `json:"config"`
+
+	Writes  arkivWriteStats   `json:"writes"`
+	Queries []arkivQueryStats `json:"queries"`
+}
+
+type arkivWriteStats struct {
+	Transactions int                     `json:"transactions"`
+	Failed       int                     `json:"failed"`
+	Operations   int                     `json:"operations"`
+	PayloadBytes int                     `json:"payloadBytes"`
+	Seconds      float64                 `json:"seconds"`
+	TxPerSecond  float64                 `json:"txPerSecond"`
+	OpsPerSecond float64                 `json:"opsPerSecond"`
+	Latency      map[string]latencyStats `json:"inclusionLatency"`
+}
+
+type arkivQueryStats struct {
+	Name       string       `json:"name"`
+	Runs       int          `json:"runs"`
+	Failed     int          `json:"failed"`
+	AvgResults float64      `json:"avgResults"`
+	Latency    latencyStats `json:"latency"`
+}
+
+// latencyStats summarizes latency samples, in milliseconds.
+type latencyStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+func newLatencyStats(samples []time.Duration) latencyStats {
+	if len(samples) == 0 {
+		return latencyStats{}
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, s := range sorted {
+		total += s
+	}
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return latencyStats{
+		Count: len(sorted),
+		Mean:  ms(total / time.Duration(len(sorted))),
+		P50:   ms(sorted[(len(sorted)-1)*50/100]),
+		P99:   ms(sorted[(len(sorted)-1)*99/100]),
+		Max:   ms(sorted[len(sorted)-1]),
+	}
+}
+
+// arkivQueryPattern generates arkiv_query queries of one shape, with values
+// drawn from the attributes of the run.
+type arkivQueryPattern struct {
+	name  string
+	query func(w *arkivWorkload) string
+}
+
+var arkivQueryPatterns = []arkivQueryPattern{
+	{"run", func(w *arkivWorkload) string {
+		return fmt.Sprintf(`workload = %q`, w.runID)
+	}},
+	{"string-eq", func(w *arkivWorkload) string {
+		return fmt.Sprintf(`workload = %q && s0 = "v%d"`, w.runID, w.rng.Intn(w.cfg.Cardinality))
+	}},
+	{"numeric-range", func(w *arkivWorkload) string {
+		lo := w.rng.Intn(w.cfg.Cardinality)
+		return fmt.Sprintf(`workload = %q && n0 >= %d && n0 < %d`, w.runID, lo, lo+max(w.cfg.Cardinality/4, 1))
+	}},
+	{"conjunction", func(w *arkivWorkload) string {
+		return fmt.Sprintf(`workload = %q && s0 = "v%d" && n0 = %d`, w.runID, w.rng.Intn(w.cfg.Cardinality), w.rng.Intn(w.cfg.Cardinality))
+	}},
+	{"disjunction", func(w *arkivWorkload) string {
+		return fmt.Sprintf(`workload = %q && (s0 = "v%d" || n0 = %d)`, w.runID, w.rng.Intn(w.cfg.Cardinality), w.rng.Intn(w.cfg.Cardinality))
+	}},
+	{"glob", func(w *arkivWorkload) string {
+		return fmt.Sprintf(`workload = %q && s0 ~ "v%d*"`, w.runID, w.rng.Intn(10))
+	}},
+	{"owner", func(w *arkivWorkload) string {
+		return fmt.Sprintf(`$owner = %s`, w.from.Hex())
+	}},
+	{"key", func(w *arkivWorkload) string {
+		key := common.Hash{}
+		if len(w.live) > 0 {
+			key = w.live[w.rng.Intn(len(w.live))]
+		}
+		return fmt.Sprintf(`$key = %s`, key.Hex())
+	}},
+}
+
+// runQueries runs every query pattern, interleaving the patterns so that they
+// see the same conditions on the node.
+func (w *arkivWorkload) runQueries(ctx context.Context) ([]arkivQueryStats, error) {
+	type patternRuns struct {
+		samples []time.Duration
+		failed  int
+		results int
+	}
+	runs := make([]patternRuns, len(arkivQueryPatterns))
+
+	fmt.Println("Running", len(arkivQueryPatterns), "query patterns", w.cfg.QueryRuns, "times")
+	for range w.cfg.QueryRuns {
+		for i, pattern := range arkivQueryPatterns {
+			var response struct {
+				Data []json.RawMessage `json:"data"`
+			}
+			query := pattern.query(w)
+			start := time.Now()
+			err := w.cl.RPC.CallContext(ctx, &response, "arkiv_query", query, nil)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				fmt.Printf("Query %q failed: %v\n", query, err)
+				runs[i].failed++
+				continue
+			}
+			runs[i].samples = append(runs[i].samples, time.Since(start))
+			runs[i].results += len(response.Data)
+		}
+	}
+
+	stats := make([]arkivQueryStats, len(arkivQueryPatterns))
+	for i, pattern := range arkivQueryPatterns {
+		stats[i] = arkivQueryStats{
+			Name:    pattern.name,
+			Runs:    len(runs[i].samples),
+			Failed:  runs[i].failed,
+			Latency: newLatencyStats(runs[i].samples),
+		}
+		if len(runs[i].samples) > 0 {
+			stats[i].AvgResults = float64(runs[i].results) / float64(len(runs[i].samples))
+		}
+	}
+	return stats, nil
+}
+
+func (r *arkivResult) print() {
+	fmt.Println()
+	fmt.Printf("Arkiv workload finished; blocks: %d-%d  transactions: %d  failed: %d  operations: %d  payload bytes: %d\n",
+		r.StartBlock, r.EndBlock, r.Writes.Transactions, r.Writes.Failed, r.Writes.Operations, r.Writes.PayloadBytes)
+	fmt.Printf("Throughput: %.2f tx/s  %.2f ops/s\n", r.Writes.TxPerSecond, r.Writes.OpsPerSecond)
+
+	kinds := make([]string, 0, len(r.Writes.Latency))
+	for kind := range r.Writes.Latency {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		r.Writes.Latency[kind].print("inclusion " + kind)
+	}
+	for _, q := range r.Queries {
+		q.Latency.print("query " + q.Name)
+	}
+}
+
+func (st latencyStats) print(name string) {
+	if st.Count == 0 {
+		return
+	}
+	fmt.Printf("%-26s count: %5d  mean: %9.2fms  p50: %9.2fms  p99: %9.2fms  max: %9.2fms\n",
+		name, st.Count, st.Mean, st.P50, st.P99, st.Max)
+}
+
+// writeArkivResult serializes the result of a run to the result file.
+func writeArkivResult(resultFile string, r *arkivResult) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(resultFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write result file %s: %v", resultFile, err)
+	}
+	return nil
+}
+
+func readArkivResult(resultFile string) (*arkivResult, error) {
+	data, err := os.ReadFile(resultFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read result file %s: %v", resultFile, err)
+	}
+	r := new(arkivResult)
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("invalid result file %s: %v", resultFile, err)
+	}
+	return r, nil
+}
+
+// compareArkivResults prints the change of the throughput and the latencies of
+// r against the baseline, and returns the number of metrics that got worse by
+// more than tolerance.
+func compareArkivResults(baseline, r *arkivResult, tolerance float64) int {
+	fmt.Println()
+	fmt.Println("Comparison with", baseline.ClientVersion, "run", baseline.Run)
+	if !configEqual(baseline.Config, r.Config) {
+		fmt.Println("Warning: the baseline was run with a different configuration")
+	}
+
+	regressions := 0
+	compare := func(name string, old, new float64, higherIsBetter bool) {
+		if old == 0 {
+			return
+		}
+		change := (new - old) / old
+		worse := change > tolerance
+		if higherIsBetter {
+			worse = -change > tolerance
+		}
+		mark := ""
+		if worse {
+			mark = "  REGRESSION"
+			regressions++
+		}
+		fmt.Printf("%-32s %12.2f -> %12.2f  %+7.1f%%%s\n", name, old, new, change*100, mark)
+	}
+
+	compare("tx/s", baseline.Writes.TxPerSecond, r.Writes.TxPerSecond, true)
+	compare("ops/s", baseline.Writes.OpsPerSecond, r.Writes.OpsPerSecond, true)
+	if old, ok := baseline.Writes.Latency["all"]; ok {
+		compare("inclusion p50 (ms)", old.P50, r.Writes.Latency["all"].P50, false)
+		compare("inclusion p99 (ms)", old.P99, r.Writes.Latency["all"].P99, false)
+	}
+	for _, q := range r.Queries {
+		for _, old := range baseline.Queries {
+			if old.Name == q.Name {
+				compare("query "+q.Name+" p50 (ms)", old.Latency.P50, q.Latency.P50, false)
+				compare("query "+q.Name+" p99 (ms)", old.Latency.P99, q.Latency.P99, false)
+			}
+		}
+	}
+	return regressions
+}
+
+func configEqual(a, b arkivConfig) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
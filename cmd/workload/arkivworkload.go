@@ -0,0 +1,635 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	arkivCommand = &cli.Command{
+		Name:      "arkiv",
+		Usage:     "Runs the Arkiv write and query workload against an RPC endpoint",
+		ArgsUsage: "<RPC endpoint URL>",
+		Action:    arkivCmd,
+		Flags: []cli.Flag{
+			arkivKeyFlag,
+			arkivSeedFlag,
+			arkivTxsFlag,
+			arkivOpsPerTxFlag,
+			arkivMixFlag,
+			arkivInflightFlag,
+			arkivPayloadMinFlag,
+			arkivPayloadMaxFlag,
+			arkivAttributesFlag,
+			arkivCardinalityFlag,
+			arkivBTLFlag,
+			arkivQueryRunsFlag,
+			arkivResultFileFlag,
+			arkivBaselineFlag,
+			arkivToleranceFlag,
+		},
+	}
+	arkivKeyFlag = &cli.StringFlag{
+		Name:     "arkiv-key",
+		Usage:    "Hex private key of the funded account sending the transactions",
+		Required: true,
+		Category: flags.TestingCategory,
+	}
+	arkivSeedFlag = &cli.Int64Flag{
+		Name:     "arkiv-seed",
+		Usage:    "Seed of the generated operations, payloads and queries",
+		Value:    1,
+		Category: flags.TestingCategory,
+	}
+	arkivTxsFlag = &cli.IntFlag{
+		Name:     "arkiv-txs",
+		Usage:    "Number of transactions to send",
+		Value:    500,
+		Category: flags.TestingCategory,
+	}
+	arkivOpsPerTxFlag = &cli.IntFlag{
+		Name:     "arkiv-ops-per-tx",
+		Usage:    "Number of operations per transaction",
+		Value:    1,
+		Category: flags.TestingCategory,
+	}
+	arkivMixFlag = &cli.StringFlag{
+		Name:     "arkiv-mix",
+		Usage:    "Relative weights of the operations",
+		Value:    "create:50,update:25,extend:15,delete:10",
+		Category: flags.TestingCategory,
+	}
+	arkivInflightFlag = &cli.IntFlag{
+		Name:     "arkiv-inflight",
+		Usage:    "Maximum number of transactions waiting for inclusion",
+		Value:    16,
+		Category: flags.TestingCategory,
+	}
+	arkivPayloadMinFlag = &cli.IntFlag{
+		Name:     "arkiv-payload-min",
+		Usage:    "Minimum payload size in bytes",
+		Value:    64,
+		Category: flags.TestingCategory,
+	}
+	arkivPayloadMaxFlag = &cli.IntFlag{
+		Name:     "arkiv-payload-max",
+		Usage:    "Maximum payload size in bytes, sizes are log-uniformly distributed",
+		Value:    16 * 1024,
+		Category: flags.TestingCategory,
+	}
+	arkivAttributesFlag = &cli.IntFlag{
+		Name:     "arkiv-attributes",
+		Usage:    "Number of string and of numeric attributes per entity",
+		Value:    2,
+		Category: flags.TestingCategory,
+	}
+	arkivCardinalityFlag = &cli.IntFlag{
+		Name:     "arkiv-cardinality",
+		Usage:    "Number of distinct values per attribute",
+		Value:    16,
+		Category: flags.TestingCategory,
+	}
+	arkivBTLFlag = &cli.Uint64Flag{
+		Name:     "arkiv-btl",
+		Usage:    "BTL of the created and updated entities, in blocks",
+		Value:    1000,
+		Category: flags.TestingCategory,
+	}
+	arkivQueryRunsFlag = &cli.IntFlag{
+		Name:     "arkiv-query-runs",
+		Usage:    "Number of runs of every query pattern",
+		Value:    50,
+		Category: flags.TestingCategory,
+	}
+	arkivResultFileFlag = &cli.StringFlag{
+		Name:     "arkiv-results",
+		Usage:    "JSON file the results are written to",
+		Value:    "arkiv_results.json",
+		Category: flags.TestingCategory,
+	}
+	arkivBaselineFlag = &cli.StringFlag{
+		Name:     "arkiv-baseline",
+		Usage:    "JSON result file of an earlier run to compare the results with",
+		Category: flags.TestingCategory,
+	}
+	arkivToleranceFlag = &cli.Float64Flag{
+		Name:     "arkiv-tolerance",
+		Usage:    "Relative slowdown against the baseline that fails the run",
+		Value:    0.2,
+		Category: flags.TestingCategory,
+	}
+)
+
+// Operation kinds of the workload.
+const (
+	arkivCreate = "create"
+	arkivUpdate = "update"
+	arkivExtend = "extend"
+	arkivDelete = "delete"
+)
+
+var arkivOperations = []string{arkivCreate, arkivUpdate, arkivExtend, arkivDelete}
+
+// arkivConfig holds the parameters of an Arkiv workload run. It is written to
+// the result file, so that a run can be repeated with the same parameters.
+type arkivConfig struct {
+	Seed        int64          `json:"seed"`
+	Txs         int            `json:"txs"`
+	OpsPerTx    int            `json:"opsPerTx"`
+	Mix         map[string]int `json:"mix"`
+	Inflight    int            `json:"inflight"`
+	PayloadMin  int            `json:"payloadMin"`
+	PayloadMax  int            `json:"payloadMax"`
+	Attributes  int            `json:"attributes"`
+	Cardinality int            `json:"cardinality"`
+	BTL         uint64         `json:"btl"`
+	QueryRuns   int            `json:"queryRuns"`
+}
+
+func arkivCmd(ctx *cli.Context) error {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.String(arkivKeyFlag.Name), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", arkivKeyFlag.Name, err)
+	}
+	mix, err := parseArkivMix(ctx.String(arkivMixFlag.Name))
+	if err != nil {
+		return err
+	}
+	cfg := arkivConfig{
+		Seed:        ctx.Int64(arkivSeedFlag.Name),
+		Txs:         ctx.Int(arkivTxsFlag.Name),
+		OpsPerTx:    max(ctx.Int(arkivOpsPerTxFlag.Name), 1),
+		Mix:         mix,
+		Inflight:    max(ctx.Int(arkivInflightFlag.Name), 1),
+		PayloadMin:  ctx.Int(arkivPayloadMinFlag.Name),
+		PayloadMax:  ctx.Int(arkivPayloadMaxFlag.Name),
+		Attributes:  ctx.Int(arkivAttributesFlag.Name),
+		Cardinality: max(ctx.Int(arkivCardinalityFlag.Name), 1),
+		BTL:         ctx.Uint64(arkivBTLFlag.Name),
+		QueryRuns:   ctx.Int(arkivQueryRunsFlag.Name),
+	}
+	if cfg.PayloadMin < 0 || cfg.PayloadMax < cfg.PayloadMin {
+		return fmt.Errorf("invalid payload sizes %d..%d", cfg.PayloadMin, cfg.PayloadMax)
+	}
+
+	w, err := newArkivWorkload(ctx.Context, makeClient(ctx), key, cfg)
+	if err != nil {
+		return err
+	}
+	result, err := w.run(ctx.Context)
+	if err != nil {
+		return err
+	}
+	result.print()
+
+	if err := writeArkivResult(ctx.String(arkivResultFileFlag.Name), result); err != nil {
+		return err
+	}
+	if baselineFile := ctx.String(arkivBaselineFlag.Name); baselineFile != "" {
+		baseline, err := readArkivResult(baselineFile)
+		if err != nil {
+			return err
+		}
+		if regressions := compareArkivResults(baseline, result, ctx.Float64(arkivToleranceFlag.Name)); regressions > 0 {
+			return fmt.Errorf("%d regressions against %s", regressions, baselineFile)
+		}
+	}
+	return nil
+}
+
+// parseArkivMix parses operation weights like "create:50,update:25".
+func parseArkivMix(s string) (map[string]int, error) {
+	mix := make(map[string]int)
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid operation weight %q", part)
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight of %s: %q", name, weight)
+		}
+		switch name {
+		case arkivCreate, arkivUpdate, arkivExtend, arkivDelete:
+		default:
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+		mix[name] = w
+		total += w
+	}
+	if total == 0 {
+		return nil, errors.New("operation weights add up to 0")
+	}
+	return mix, nil
+}
+
+// arkivWorkload sends Arkiv transactions from a single account and keeps track
+// of the entities it has created.
+type arkivWorkload struct {
+	cl      *client
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+	cfg     arkivConfig
+	rng     *rand.Rand
+
+	// runID tags all entities of this run, so that the queries only match them.
+	runID string
+
+	live     []common.Hash // included entities not used by a pending transaction
+	inflight []pendingArkivTx
+	samples  map[string][]time.Duration
+	failed   int
+	included int
+	ops      int
+	bytes    int
+	first    time.Time
+	last     time.Time
+	endBlock uint64
+}
+
+type pendingArkivTx struct {
+	tx   *types.Transaction
+	kind string
+	keys []common.Hash // entities the transaction operates on
+	sent time.Time
+}
+
+func newArkivWorkload(ctx context.Context, cl *client, key *ecdsa.PrivateKey, cfg arkivConfig) (*arkivWorkload, error) {
+	chainID, err := cl.Eth.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chain ID: %v", err)
+	}
+	head, err := cl.Eth.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get head block: %v", err)
+	}
+	return &arkivWorkload{
+		cl:      cl,
+		key:     key,
+		from:    crypto.PubkeyToAddress(key.PublicKey),
+		chainID: chainID,
+		cfg:     cfg,
+		rng:     rand.New(rand.NewSource(cfg.Seed)),
+		runID:   fmt.Sprintf("%d-%d", cfg.Seed, head),
+		samples: make(map[string][]time.Duration),
+	}, nil
+}
+
+// run sends the transactions, waits for their inclusion and then runs the
+// query patterns against the entities of the run.
+func (w *arkivWorkload) run(ctx context.Context) (*arkivResult, error) {
+	result := &arkivResult{
+		Config: w.cfg,
+		Run:    w.runID,
+	}
+	if err := w.cl.RPC.CallContext(ctx, &result.ClientVersion, "web3_clientVersion"); err != nil {
+		return nil, fmt.Errorf("could not get client version: %v", err)
+	}
+	result.ChainID = w.chainID.Uint64()
+
+	nonce, err := w.cl.Eth.PendingNonceAt(ctx, w.from)
+	if err != nil {
+		return nil, fmt.Errorf("could not get nonce: %v", err)
+	}
+	head, err := w.cl.Eth.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.StartBlock = head
+
+	fmt.Println("Arkiv workload run", w.runID, "sending", w.cfg.Txs, "transactions from", w.from)
+	for i := 0; i < w.cfg.Txs; i++ {
+		if err := w.waitInflight(ctx, w.cfg.Inflight-1); err != nil {
+			return nil, err
+		}
+		ptx, err := w.nextTransaction(ctx, nonce)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		if err := w.cl.Eth.SendTransaction(ctx, ptx.tx); err != nil {
+			return nil, fmt.Errorf("could not send transaction %d: %v", i, err)
+		}
+		nonce++
+
+		if w.first.IsZero() {
+			w.first = ptx.sent
+		}
+		w.inflight = append(w.inflight, ptx)
+
+		if (i+1)%50 == 0 {
+			fmt.Println(" sent:", i+1, "included:", w.included, "failed:", w.failed)
+		}
+	}
+	if err := w.waitInflight(ctx, 0); err != nil {
+		return nil, err
+	}
+
+	result.EndBlock = w.endBlock
+	result.Writes = w.writeStats()
+
+	if err := w.waitForStore(ctx, w.endBlock); err != nil {
+		return nil, err
+	}
+	result.Queries, err = w.runQueries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// waitInflight polls the receipts of the pending transactions until at most n
+// of them are left.
+func (w *arkivWorkload) waitInflight(ctx context.Context, n int) error {
+	for {
+		if err := w.pollReceipts(ctx); err != nil {
+			return err
+		}
+		if len(w.inflight) <= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (w *arkivWorkload) pollReceipts(ctx context.Context) error {
+	var remaining []pendingArkivTx
+	for _, ptx := range w.inflight {
+		receipt, err := w.cl.Eth.TransactionReceipt(ctx, ptx.tx.Hash())
+		// The node answers receipt requests with an error while it indexes
+		// the transactions of new blocks, so that is treated as not found.
+		if errors.Is(err, ethereum.NotFound) || (err != nil && err.Error() == "transaction indexing is in progress") {
+			remaining = append(remaining, ptx)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get receipt of %s: %v", ptx.tx.Hash(), err)
+		}
+		w.include(ptx, receipt, time.Now())
+	}
+	w.inflight = remaining
+	return nil
+}
+
+// include records the inclusion of a transaction and returns the entities it
+// operated on, or created, to the pool of live entities.
+func (w *arkivWorkload) include(ptx pendingArkivTx, receipt *types.Receipt, now time.Time) {
+
+	w.last = now
+	w.endBlock = max(w.endBlock, receipt.BlockNumber.Uint64())
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		w.failed++
+		if ptx.kind != arkivCreate {
+			w.live = append(w.live, ptx.keys...)
+		}
+		return
+	}
+	w.included++
+	w.ops += w.cfg.OpsPerTx
+	w.samples[ptx.kind] = append(w.samples[ptx.kind], now.Sub(ptx.sent))
+
+	switch ptx.kind {
+	case arkivCreate:
+		for _, l := range receipt.Logs {
+			if len(l.Topics) > 1 && l.Topics[0] == arkivlogs.ArkivEntityCreated {
+				w.live = append(w.live, l.Topics[1])
+			}
+		}
+	case arkivUpdate, arkivExtend:
+		w.live = append(w.live, ptx.keys...)
+	}
+}
+
+// nextTransaction builds and signs the next transaction of the workload. The
+// kind of the operations is drawn from the mix, falling back to creates while
+// there are not enough included entities.
+func (w *arkivWorkload) nextTransaction(ctx context.Context, nonce uint64) (pendingArkivTx, error) {
+	kind := w.pickOperation()
+
+	var keys []common.Hash
+	if kind != arkivCreate {
+		if len(w.live) < w.cfg.OpsPerTx {
+			kind = arkivCreate
+		} else {
+			for range w.cfg.OpsPerTx {
+				i := w.rng.Intn(len(w.live))
+				keys = append(keys, w.live[i])
+				w.live[i] = w.live[len(w.live)-1]
+				w.live = w.live[:len(w.live)-1]
+			}
+		}
+	}
+
+	atx := &storagetx.ArkivTransaction{}
+	switch kind {
+	case arkivCreate:
+		for range w.cfg.OpsPerTx {
+			payload := w.payload()
+			atx.Create = append(atx.Create, storagetx.ArkivCreate{
+				BTL:                w.cfg.BTL,
+				ContentType:        "application/octet-stream",
+				Payload:            payload,
+				StringAnnotations:  w.stringAttributes(),
+				NumericAnnotations: w.numericAttributes(),
+			})
+		}
+	case arkivUpdate:
+		for _, key := range keys {
+			atx.Update = append(atx.Update, storagetx.ArkivUpdate{
+				EntityKey:          key,
+				BTL:                w.cfg.BTL,
+				ContentType:        "application/octet-stream",
+				Payload:            w.payload(),
+				StringAnnotations:  w.stringAttributes(),
+				NumericAnnotations: w.numericAttributes(),
+			})
+		}
+	case arkivExtend:
+		for _, key := range keys {
+			atx.Extend = append(atx.Extend, storagetx.ExtendBTL{EntityKey: key, NumberOfBlocks: w.cfg.BTL})
+		}
+	case arkivDelete:
+		atx.Delete = keys
+	}
+
+	tx, err := w.signTransaction(ctx, atx, nonce)
+	if err != nil {
+		return pendingArkivTx{}, err
+	}
+	return pendingArkivTx{tx: tx, kind: kind, keys: keys, sent: time.Now()}, nil
+}
+
+func (w *arkivWorkload) pickOperation() string {
+	total := 0
+	for _, weight := range w.cfg.Mix {
+		total += weight
+	}
+	n := w.rng.Intn(total)
+	for _, kind := range arkivOperations {
+		if n < w.cfg.Mix[kind] {
+			return kind
+		}
+		n -= w.cfg.Mix[kind]
+	}
+	return arkivCreate
+}
+
+// payload returns random bytes of a log-uniformly distributed size, so that
+// small payloads are common and large ones are rare.
+func (w *arkivWorkload) payload() []byte {
+	lo, hi := math.Log(float64(w.cfg.PayloadMin+1)), math.Log(float64(w.cfg.PayloadMax+1))
+	size := int(math.Exp(lo+w.rng.Float64()*(hi-lo))) - 1
+	payload := make([]byte, max(size, w.cfg.PayloadMin))
+	w.rng.Read(payload)
+
+	w.bytes += len(payload)
+	return payload
+}
+
+func (w *arkivWorkload) stringAttributes() []storagetx.StringAnnotation {
+	attributes := []storagetx.StringAnnotation{{Key: "workload", Value: w.runID}}
+	for i := range w.cfg.Attributes {
+		attributes = append(attributes, storagetx.StringAnnotation{
+			Key:   fmt.Sprintf("s%d", i),
+			Value: fmt.Sprintf("v%d", w.rng.Intn(w.cfg.Cardinality)),
+		})
+	}
+	return attributes
+}
+
+func (w *arkivWorkload) numericAttributes() []storagetx.NumericAnnotation {
+	var attributes []storagetx.NumericAnnotation
+	for i := range w.cfg.Attributes {
+		attributes = append(attributes, storagetx.NumericAnnotation{
+			Key:   fmt.Sprintf("n%d", i),
+			Value: uint64(w.rng.Intn(w.cfg.Cardinality)),
+		})
+	}
+	return attributes
+}
+
+func (w *arkivWorkload) signTransaction(ctx context.Context, atx *storagetx.ArkivTransaction, nonce uint64) (*types.Transaction, error) {
+	encoded, err := rlp.EncodeToBytes(atx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := compression.BrotliCompress(encoded)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := w.cl.Eth.EstimateGas(ctx, ethereum.CallMsg{
+		From: w.from,
+		To:   &address.ArkivProcessorAddress,
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not estimate gas: %v", err)
+	}
+	tip, err := w.cl.Eth.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := w.cl.Eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+
+	return types.SignNewTx(w.key, types.LatestSignerForChainID(w.chainID), &types.DynamicFeeTx{
+		ChainID:   w.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &address.ArkivProcessorAddress,
+		Data:      data,
+	})
+}
+
+func (w *arkivWorkload) writeStats() arkivWriteStats {
+
+	stats := arkivWriteStats{
+		Transactions: w.included,
+		Failed:       w.failed,
+		Operations:   w.ops,
+		PayloadBytes: w.bytes,
+		Latency:      make(map[string]latencyStats),
+	}
+	if elapsed := w.last.Sub(w.first); elapsed > 0 {
+		stats.Seconds = elapsed.Seconds()
+		stats.TxPerSecond = float64(w.included) / elapsed.Seconds()
+		stats.OpsPerSecond = float64(w.ops) / elapsed.Seconds()
+	}
+	var all []time.Duration
+	for kind, samples := range w.samples {
+		stats.Latency[kind] = newLatencyStats(samples)
+		all = append(all, samples...)
+	}
+	stats.Latency["all"] = newLatencyStats(all)
+	return stats
+}
+
+// waitForStore waits until the Arkiv store of the node has ingested the given
+// block, so that the queries see all entities of the run.
+func (w *arkivWorkload) waitForStore(ctx context.Context, block uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	for {
+		var status struct {
+			StoreBlock uint64 `json:"storeBlock"`
+		}
+		if err := w.cl.RPC.CallContext(ctx, &status, "arkiv_syncStatus"); err != nil {
+			return fmt.Errorf("could not get the sync status of the Arkiv store: %v", err)
+		}
+		if status.StoreBlock >= block {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Arkiv store did not reach block %d: %v", block, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
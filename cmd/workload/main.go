@@ -49,6 +49,7 @@ func init() {
 		filterGenerateCommand,
 		traceGenerateCommand,
 		filterPerfCommand,
+		arkivCommand,
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -163,6 +164,16 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	if rbloom != header.Bloom {
 		return fmt.Errorf("invalid bloom (remote: %x  local: %x)", header.Bloom, rbloom)
 	}
+	// Validate the load of the executed Arkiv transactions against the Arkiv block limits.
+	if limits := v.config.ArkivBlockLimits(header.Time); limits != nil {
+		var usage storagetx.BlockUsage
+		for i, tx := range block.Transactions() {
+			usage = usage.Add(storagetx.ReceiptUsage(tx, res.Receipts[i]))
+		}
+		if err := usage.CheckLimits(limits); err != nil {
+			return fmt.Errorf("invalid arkiv usage: %w", err)
+		}
+	}
 	// In stateless mode, return early because the receipt and state root are not
 	// provided through the witness, rather the cross validator needs to return it.
 	if stateless {
@@ -0,0 +1,130 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArkivBlockLimits(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	create := func(payload []byte) storagetx.ArkivCreate {
+		return storagetx.ArkivCreate{BTL: 100, ContentType: "text/plain", Payload: payload}
+	}
+
+	tests := []struct {
+		name     string
+		limits   params.ArkivConfig
+		fork     uint64
+		first    *storagetx.ArkivTransaction
+		second   *storagetx.ArkivTransaction
+		exceeded bool
+	}{
+		{
+			name:     "operations",
+			limits:   params.ArkivConfig{MaxBlockOps: 2},
+			first:    &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create([]byte("a")), create([]byte("b"))}},
+			second:   &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create([]byte("a")), create([]byte("b")), create([]byte("c"))}},
+			exceeded: true,
+		},
+		{
+			name:     "payload bytes",
+			limits:   params.ArkivConfig{MaxBlockPayloadBytes: 1024},
+			first:    &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create(bytes.Repeat([]byte("a"), 512))}},
+			second:   &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create(bytes.Repeat([]byte("a"), 1024))}},
+			exceeded: true,
+		},
+		{
+			name:   "before the fork",
+			limits: params.ArkivConfig{MaxBlockOps: 2},
+			fork:   math.MaxUint64,
+			first:  &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create([]byte("a")), create([]byte("b"))}},
+			second: &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create([]byte("a")), create([]byte("b")), create([]byte("c"))}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *params.TestChainConfig
+			config.Arkiv = &tt.limits
+			config.ArkivBlockLimitsTime = &tt.fork
+
+			// The processor account has a nonce, as created by the housekeeping on a
+			// real chain, so that it isn't removed as an empty account.
+			gspec := &Genesis{
+				Config: &config,
+				Alloc: types.GenesisAlloc{
+					address.ArkivProcessorAddress: {Nonce: 1, Balance: new(big.Int)},
+					sender:                        {Balance: big.NewInt(params.Ether)},
+				},
+			}
+			signer := types.LatestSigner(gspec.Config)
+
+			// The chain generator doesn't check the Arkiv block limits, so the
+			// second block carries more than the limits allow.
+			_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, gen *BlockGen) {
+				atx := tt.first
+				if i == 1 {
+					atx = tt.second
+				}
+				encoded, err := rlp.EncodeToBytes(atx)
+				require.NoError(t, err)
+				tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+					ChainID:   gspec.Config.ChainID,
+					Nonce:     gen.TxNonce(sender),
+					GasTipCap: big.NewInt(1),
+					GasFeeCap: new(big.Int).Add(gen.BaseFee(), big.NewInt(1)),
+					Gas:       1_000_000,
+					To:        &address.ArkivProcessorAddress,
+					Data:      compression.MustBrotliCompress(encoded),
+				})
+				require.NoError(t, err)
+				gen.AddTx(tx)
+			})
+
+			chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, ethash.NewFaker(), nil)
+			require.NoError(t, err)
+			defer chain.Stop()
+
+			n, err := chain.InsertChain(blocks)
+			if !tt.exceeded {
+				require.NoError(t, err)
+				require.Equal(t, uint64(2), chain.CurrentBlock().Number.Uint64())
+				return
+			}
+			require.ErrorIs(t, err, storagetx.ErrBlockLimitExceeded)
+			require.Equal(t, 1, n)
+			require.Equal(t, uint64(1), chain.CurrentBlock().Number.Uint64())
+		})
+	}
+}
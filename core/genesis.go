@@ -79,6 +79,10 @@ type Genesis struct {
 	// Chains with history pruning, or extraordinarily large genesis allocation (e.g. after a regenesis event)
 	// may utilize this to get started, and then state-sync the latest state, while still verifying the header chain.
 	StateHash *common.Hash `json:"stateHash,omitempty"`
+
+	// Arkiv lists the entities of the Arkiv storage layer that exist from the
+	// genesis block on.
+	Arkiv *GenesisArkiv `json:"arkiv,omitempty"`
 }
 
 // copy copies the genesis.
@@ -109,6 +113,12 @@ func ReadGenesis(db ethdb.Database) (*Genesis, error) {
 			return nil, fmt.Errorf("could not unmarshal genesis state json: %s", err)
 		}
 	}
+	if blob := rawdb.ReadArkivGenesisSpec(db, stored); len(blob) != 0 {
+		genesis.Arkiv = new(GenesisArkiv)
+		if err := json.Unmarshal(blob, genesis.Arkiv); err != nil {
+			return nil, fmt.Errorf("could not unmarshal arkiv genesis json: %s", err)
+		}
+	}
 	genesis.Config = rawdb.ReadChainConfig(db, stored)
 	if genesis.Config == nil {
 		return nil, errors.New("genesis config missing from db")
@@ -129,7 +139,7 @@ func ReadGenesis(db ethdb.Database) (*Genesis, error) {
 	genesis.ExcessBlobGas = genesisHeader.ExcessBlobGas
 	genesis.BlobGasUsed = genesisHeader.BlobGasUsed
 	// A nil or empty alloc, with a non-matching state-root in the block header, intents to override the state-root.
-	if genesis.Alloc == nil || (len(genesis.Alloc) == 0 && genesisHeader.Root != types.EmptyRootHash) {
+	if genesis.Arkiv == nil && (genesis.Alloc == nil || (len(genesis.Alloc) == 0 && genesisHeader.Root != types.EmptyRootHash)) {
 		h := genesisHeader.Root // the genesis block is encoded as RLP in the DB and will contain the state-root
 		genesis.StateHash = &h
 		genesis.Alloc = nil
@@ -236,7 +246,13 @@ func getGenesisState(db ethdb.Database, blockhash common.Hash) (alloc types.Gene
 		if err := alloc.UnmarshalJSON(blob); err != nil {
 			return nil, err
 		}
-
+		if blob := rawdb.ReadArkivGenesisSpec(db, blockhash); len(blob) != 0 {
+			genesis := &Genesis{Alloc: alloc, Arkiv: new(GenesisArkiv)}
+			if err := json.Unmarshal(blob, genesis.Arkiv); err != nil {
+				return nil, err
+			}
+			return genesis.arkivAlloc()
+		}
 		return alloc, nil
 	}
 
@@ -591,9 +607,8 @@ func (g *Genesis) IsVerkle() bool {
 // ToBlock returns the genesis block according to genesis specification.
 func (g *Genesis) ToBlock() *types.Block {
 	var stateRoot, storageRootMessagePasser common.Hash
-	var err error
 	if g.StateHash != nil {
-		if len(g.Alloc) > 0 {
+		if len(g.Alloc) > 0 || g.Arkiv.hasEntities() {
 			panic(fmt.Errorf("cannot both have genesis hash %s "+
 				"and non-empty state-allocation", *g.StateHash))
 		}
@@ -603,8 +618,14 @@ func (g *Genesis) ToBlock() *types.Block {
 			panic(fmt.Errorf("stateHash usage disallowed in chain with isthmus active at genesis"))
 		}
 		stateRoot = *g.StateHash
-	} else if stateRoot, storageRootMessagePasser, err = hashAlloc(&g.Alloc, g.IsVerkle(), g.Config.IsOptimismIsthmus(g.Timestamp)); err != nil {
-		panic(err)
+	} else {
+		alloc, err := g.arkivAlloc()
+		if err != nil {
+			panic(err)
+		}
+		if stateRoot, storageRootMessagePasser, err = hashAlloc(&alloc, g.IsVerkle(), g.Config.IsOptimismIsthmus(g.Timestamp)); err != nil {
+			panic(err)
+		}
 	}
 	return g.toBlockWithRoot(stateRoot, storageRootMessagePasser)
 }
@@ -696,9 +717,12 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *triedb.Database) (*types.Blo
 	if config.Clique != nil && len(g.ExtraData) < 32+crypto.SignatureLength {
 		return nil, errors.New("can't start clique chain without signers")
 	}
+	alloc, err := g.arkivAlloc()
+	if err != nil {
+		return nil, err
+	}
 	var stateRoot, storageRootMessagePasser common.Hash
-	var err error
-	if len(g.Alloc) == 0 {
+	if len(alloc) == 0 {
 		if g.StateHash == nil {
 			stateRoot = types.EmptyRootHash // default to the hash of the empty state. Some unit-tests rely on this.
 		} else {
@@ -706,7 +730,7 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *triedb.Database) (*types.Blo
 		}
 	} else {
 		// flush the data to disk and compute the state root
-		stateRoot, storageRootMessagePasser, err = flushAlloc(&g.Alloc, triedb, g.Config.IsIsthmus(g.Timestamp))
+		stateRoot, storageRootMessagePasser, err = flushAlloc(&alloc, triedb, g.Config.IsIsthmus(g.Timestamp))
 		if err != nil {
 			return nil, err
 		}
@@ -720,6 +744,13 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *triedb.Database) (*types.Blo
 	}
 	batch := db.NewBatch()
 	rawdb.WriteGenesisStateSpec(batch, block.Hash(), blob)
+	if g.Arkiv.hasEntities() {
+		arkivBlob, err := json.Marshal(g.Arkiv)
+		if err != nil {
+			return nil, err
+		}
+		rawdb.WriteArkivGenesisSpec(batch, block.Hash(), arkivBlob)
+	}
 	rawdb.WriteBlock(batch, block)
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), nil)
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
@@ -0,0 +1,166 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+)
+
+//go:generate go run github.com/fjl/gencodec -type GenesisEntity -field-override genesisEntityMarshaling -out gen_genesis_entity.go
+
+// GenesisArkiv is the Arkiv section of the genesis specification.
+type GenesisArkiv struct {
+	Entities []GenesisEntity `json:"entities"`
+}
+
+// GenesisEntity is an entity that exists from the genesis block on. It expires
+// either BTL blocks after the genesis block, or at block ExpiresAt.
+type GenesisEntity struct {
+	Owner              common.Address                `json:"owner"       gencodec:"required"`
+	BTL                uint64                        `json:"btl,omitempty"`
+	ExpiresAt          uint64                        `json:"expiresAt,omitempty"`
+	ContentType        string                        `json:"contentType" gencodec:"required"`
+	Payload            []byte                        `json:"payload"`
+	StringAnnotations  []storagetx.StringAnnotation  `json:"stringAnnotations,omitempty"`
+	NumericAnnotations []storagetx.NumericAnnotation `json:"numericAnnotations,omitempty"`
+}
+
+// field type overrides for gencodec
+type genesisEntityMarshaling struct {
+	BTL       math.HexOrDecimal64
+	ExpiresAt math.HexOrDecimal64
+	Payload   hexutil.Bytes
+}
+
+// Key returns the key of the entity at index i of the genesis entities. It is
+// derived like the key of an entity created by the i-th create operation of a
+// transaction with the zero hash.
+func (e *GenesisEntity) Key(i int) common.Hash {
+	return storagetx.EntityKey(common.Hash{}, e.Payload, i)
+}
+
+// ExpiresAtBlock returns the block at which the entity expires, given the number
+// of the genesis block.
+func (e *GenesisEntity) ExpiresAtBlock(number uint64) uint64 {
+	if e.ExpiresAt != 0 {
+		return e.ExpiresAt
+	}
+	return number + e.BTL
+}
+
+func (ga *GenesisArkiv) hasEntities() bool {
+	return ga != nil && len(ga.Entities) > 0
+}
+
+// validate checks the genesis entities against the rules that apply to the
+// create operations of Arkiv transactions. The entities are only supported in a
+// genesis at block 0, which is the block their events are reported at.
+func (ga *GenesisArkiv) validate(number uint64) error {
+	if number != 0 {
+		return fmt.Errorf("arkiv genesis entities require genesis block 0, have %d", number)
+	}
+	tx := &storagetx.ArkivTransaction{}
+	for i, e := range ga.Entities {
+		switch {
+		case e.BTL != 0 && e.ExpiresAt != 0:
+			return fmt.Errorf("arkiv genesis entity %d has both btl and expiresAt", i)
+		case e.BTL == 0 && e.ExpiresAt == 0:
+			return fmt.Errorf("arkiv genesis entity %d has neither btl nor expiresAt", i)
+		case e.ExpiresAt != 0 && e.ExpiresAt <= number:
+			return fmt.Errorf("arkiv genesis entity %d expires at block %d, not after genesis block %d", i, e.ExpiresAt, number)
+		}
+		tx.Create = append(tx.Create, storagetx.ArkivCreate{
+			BTL:                e.ExpiresAtBlock(number) - number,
+			ContentType:        e.ContentType,
+			Payload:            e.Payload,
+			StringAnnotations:  e.StringAnnotations,
+			NumericAnnotations: e.NumericAnnotations,
+		})
+	}
+	if err := tx.Validate(); err != nil {
+		return fmt.Errorf("invalid arkiv genesis entities: %w", err)
+	}
+	return nil
+}
+
+// arkivAlloc returns the genesis allocation, with the metadata and expiration
+// keysets of the genesis entities added to the storage of the Arkiv processor.
+func (g *Genesis) arkivAlloc() (types.GenesisAlloc, error) {
+	if !g.Arkiv.hasEntities() {
+		return g.Alloc, nil
+	}
+	if err := g.Arkiv.validate(g.Number); err != nil {
+		return nil, err
+	}
+	alloc := make(types.GenesisAlloc, len(g.Alloc)+1)
+	maps.Copy(alloc, g.Alloc)
+
+	processor := alloc[address.ArkivProcessorAddress]
+	if len(processor.Code) > 0 {
+		return nil, errors.New("arkiv genesis entities require the processor account to have no code")
+	}
+	storage := make(genesisStorage, len(processor.Storage))
+	maps.Copy(storage, processor.Storage)
+
+	counter := storageaccounting.NewSlotUsageCounter(storage)
+	for i, e := range g.Arkiv.Entities {
+		emd := entity.EntityMetaData{
+			Owner:          e.Owner,
+			ExpiresAtBlock: e.ExpiresAtBlock(g.Number),
+		}
+		if err := entity.Store(counter, e.Key(i), e.Owner, emd, e.Payload); err != nil {
+			return nil, fmt.Errorf("failed to store arkiv genesis entity %d: %w", i, err)
+		}
+	}
+	counter.UpdateUsedSlotsForGolemBase()
+
+	// The processor account is created with a nonce, like the housekeeping
+	// does, so that it isn't removed as an empty account.
+	processor.Storage = storage
+	processor.Nonce = max(processor.Nonce, 1)
+	alloc[address.ArkivProcessorAddress] = processor
+	return alloc, nil
+}
+
+// genesisStorage gives the Arkiv storage helpers access to the storage of the
+// processor account in the genesis allocation.
+type genesisStorage map[common.Hash]common.Hash
+
+func (s genesisStorage) GetState(_ common.Address, key common.Hash) common.Hash {
+	return s[key]
+}
+
+func (s genesisStorage) SetState(_ common.Address, key common.Hash, value common.Hash) common.Hash {
+	prev := s[key]
+	if value == (common.Hash{}) {
+		delete(s, key)
+	} else {
+		s[key] = value
+	}
+	return prev
+}
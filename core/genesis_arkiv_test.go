@@ -0,0 +1,138 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity/entityexpiration"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/stretchr/testify/require"
+)
+
+const arkivGenesisJSON = `{
+	"config": {"chainId": 1337},
+	"gasLimit": "0x1c9c380",
+	"difficulty": "0x1",
+	"alloc": {
+		"0000000000000000000000000000000000000001": {"balance": "0x1"}
+	},
+	"arkiv": {
+		"entities": [
+			{
+				"owner": "0x00000000000000000000000000000000000000aa",
+				"btl": 10,
+				"contentType": "text/plain",
+				"payload": "0x6669727374",
+				"stringAnnotations": [{"key": "kind", "value": "reference"}],
+				"numericAnnotations": [{"key": "version", "value": 1}]
+			},
+			{
+				"owner": "0x00000000000000000000000000000000000000bb",
+				"expiresAt": "0x14",
+				"contentType": "application/json",
+				"payload": "0x7b7d"
+			},
+			{
+				"owner": "0x00000000000000000000000000000000000000bb",
+				"expiresAt": 20,
+				"contentType": "application/json",
+				"payload": "0x5b5d"
+			}
+		]
+	}
+}`
+
+func TestArkivGenesis(t *testing.T) {
+	var genesis Genesis
+	require.NoError(t, json.Unmarshal([]byte(arkivGenesisJSON), &genesis))
+	genesis.Config = params.TestChainConfig
+
+	db := rawdb.NewMemoryDatabase()
+	tdb := triedb.NewDatabase(db, triedb.HashDefaults)
+	block, err := genesis.Commit(db, tdb)
+	require.NoError(t, err)
+	require.Equal(t, genesis.ToBlock().Hash(), block.Hash())
+
+	statedb, err := state.New(block.Root(), state.NewDatabase(tdb, nil))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), statedb.GetNonce(address.ArkivProcessorAddress))
+
+	entities := genesis.Arkiv.Entities
+	for i, e := range entities {
+		emd, err := entity.GetEntityMetaData(statedb, e.Key(i))
+		require.NoError(t, err)
+		require.Equal(t, e.Owner, emd.Owner)
+		require.Equal(t, e.ExpiresAtBlock(0), emd.ExpiresAtBlock)
+	}
+	require.Equal(t, []common.Hash{entities[0].Key(0)}, slices.Collect(entityexpiration.IteratorOfEntitiesToExpireAtBlock(statedb, 10)))
+	require.Equal(t, []common.Hash{entities[1].Key(1), entities[2].Key(2)}, slices.Collect(entityexpiration.IteratorOfEntitiesToExpireAtBlock(statedb, 20)))
+
+	// A metadata slot per entity, and a length slot plus two slots per entity
+	// for each expiration keyset
+	require.Equal(t, uint64(3+(1+2)+(1+4)), storageaccounting.GetNumberOfUsedSlots(statedb).Uint64())
+
+	// The stored genesis has to reproduce the same block
+	stored, err := ReadGenesis(db)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Arkiv, stored.Arkiv)
+	require.Equal(t, block.Hash(), stored.ToBlock().Hash())
+
+	// The allocation of the stored genesis includes the entities
+	alloc, err := getGenesisState(db, block.Hash())
+	require.NoError(t, err)
+	require.Len(t, alloc[address.ArkivProcessorAddress].Storage, 3+(1+2)+(1+4)+1)
+}
+
+func TestArkivGenesisInvalid(t *testing.T) {
+	tests := map[string]GenesisEntity{
+		"both btl and expiresAt": {Owner: common.Address{1}, BTL: 10, ExpiresAt: 10, ContentType: "text/plain"},
+		"no expiration":          {Owner: common.Address{1}, ContentType: "text/plain"},
+		"no content type":        {Owner: common.Address{1}, BTL: 10},
+		"invalid annotation":     {Owner: common.Address{1}, BTL: 10, ContentType: "text/plain", StringAnnotations: []storagetx.StringAnnotation{{Key: "$owner", Value: "x"}}},
+	}
+	for name, e := range tests {
+		t.Run(name, func(t *testing.T) {
+			genesis := &Genesis{
+				Config: params.TestChainConfig,
+				Arkiv:  &GenesisArkiv{Entities: []GenesisEntity{e}},
+			}
+			db := rawdb.NewMemoryDatabase()
+			_, err := genesis.Commit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+			require.Error(t, err)
+		})
+	}
+	t.Run("non-zero genesis number", func(t *testing.T) {
+		genesis := &Genesis{
+			Config: params.TestChainConfig,
+			Number: 10,
+			Arkiv:  &GenesisArkiv{Entities: []GenesisEntity{{Owner: common.Address{1}, BTL: 10, ContentType: "text/plain"}}},
+		}
+		_, err := genesis.arkivAlloc()
+		require.ErrorContains(t, err, "require genesis block 0")
+	})
+}
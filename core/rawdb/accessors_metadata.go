@@ -98,6 +98,21 @@ func WriteGenesisStateSpec(db ethdb.KeyValueWriter, blockhash common.Hash, data
 	}
 }
 
+// ReadArkivGenesisSpec retrieves the specification of the Arkiv entities of the
+// genesis based on the given genesis (block-)hash.
+func ReadArkivGenesisSpec(db ethdb.KeyValueReader, blockhash common.Hash) []byte {
+	data, _ := db.Get(arkivGenesisSpecKey(blockhash))
+	return data
+}
+
+// WriteArkivGenesisSpec writes the specification of the Arkiv entities of the
+// genesis into the disk.
+func WriteArkivGenesisSpec(db ethdb.KeyValueWriter, blockhash common.Hash, data []byte) {
+	if err := db.Put(arkivGenesisSpecKey(blockhash), data); err != nil {
+		log.Crit("Failed to store arkiv genesis", "err", err)
+	}
+}
+
 // crashList is a list of unclean-shutdown-markers, for rlp-encoding to the
 // database
 type crashList struct {
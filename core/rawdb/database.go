@@ -497,6 +497,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				metadata.add(size)
 			case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
 				metadata.add(size)
+			case bytes.HasPrefix(key, arkivGenesisPrefix) && len(key) == (len(arkivGenesisPrefix)+common.HashLength):
+				metadata.add(size)
 			case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
 				beaconHeaders.add(size)
 			case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
@@ -139,6 +139,8 @@ var (
 	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
 	genesisPrefix  = []byte("ethereum-genesis-") // genesis state prefix for the db
 
+	arkivGenesisPrefix = []byte("arkiv-genesis-") // arkivGenesisPrefix + hash -> genesis Arkiv entities
+
 	CliqueSnapshotPrefix = []byte("clique-")
 
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
@@ -266,6 +268,11 @@ func genesisStateSpecKey(hash common.Hash) []byte {
 	return append(genesisPrefix, hash.Bytes()...)
 }
 
+// arkivGenesisSpecKey = arkivGenesisPrefix + hash
+func arkivGenesisSpecKey(hash common.Hash) []byte {
+	return append(arkivGenesisPrefix, hash.Bytes()...)
+}
+
 // stateIDKey = stateIDPrefix + root (32 bytes)
 func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/golem-base/address"
 	"github.com/ethereum/go-ethereum/golem-base/storagetx"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 )
 
 // StateProcessor is a basic Processor, which takes care of transitioning
@@ -152,12 +153,20 @@ func ApplyTransactionWithEVM(msg *Message, gp *GasPool, statedb *state.StateDB,
 
 		if tx.To() != nil && *tx.To() == address.ArkivProcessorAddress {
 
+			// The value pays for the renewal deposits, so it is transferred to
+			// the processor as in the state transition.
+			value := uint256.MustFromBig(msg.Value)
+			evm.Context.Transfer(statedb, msg.From, address.ArkivProcessorAddress, value)
+
+			rules := evm.ChainConfig().Rules(blockNumber, evm.Context.Random != nil, blockTime)
 			logs, err := storagetx.ExecuteArkivTransaction(
 				tx.Data(),
+				rules,
 				blockNumber.Uint64(),
 				blockHash,
 				txIx,
 				msg.From,
+				value,
 				statedb,
 			)
 
@@ -230,13 +230,21 @@ func TransactionToMessage(tx *types.Transaction, s types.Signer, baseFee *big.In
 // indicates a core error meaning that the message would always fail for that particular
 // state and would never be accepted within a block.
 func ApplyMessage(evm *vm.EVM, msg *Message, gp *GasPool) (*ExecutionResult, error) {
-	evm.SetTxContext(NewEVMTxContext(msg))
-	return newStateTransition(evm, msg, gp, 0).execute()
+	return ApplyMessageWithIndex(evm, msg, gp, 0)
 }
 
 func ApplyMessageWithIndex(evm *vm.EVM, msg *Message, gp *GasPool, txIndex int) (*ExecutionResult, error) {
 	evm.SetTxContext(NewEVMTxContext(msg))
-	return newStateTransition(evm, msg, gp, txIndex).execute()
+	result, err := newStateTransition(evm, msg, gp, txIndex).execute()
+	if err != nil {
+		return nil, err
+	}
+	if msg.PostValidation != nil {
+		if err := msg.PostValidation(evm, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 // stateTransition represents a state transition.
@@ -632,7 +640,7 @@ func (st *stateTransition) innerExecute() (*ExecutionResult, error) {
 			var logs []*types.Log
 			// run the arkiv transaction
 			// We set the tx index to 0, since it doesn't matter because this execution won't modify the account state
-			logs, vmerr = storagetx.ExecuteArkivTransaction(st.msg.Data, st.msg.BlockNumber, st.msg.TransactionHash, st.txIndex, msg.From, st.evm.StateDB)
+			logs, vmerr = storagetx.ExecuteArkivTransaction(st.msg.Data, rules, st.msg.BlockNumber, st.msg.TransactionHash, st.txIndex, msg.From, value, st.evm.StateDB)
 			if err != nil {
 				return nil, fmt.Errorf("failed to execute arkiv transaction: %w", err)
 			}
@@ -645,7 +653,7 @@ func (st *stateTransition) innerExecute() (*ExecutionResult, error) {
 			}
 		case msg.IsDepositTx:
 
-			logs, err := housekeepingtx.ExecuteTransaction(st.msg.BlockNumber, st.msg.TransactionHash, st.evm.StateDB)
+			logs, err := housekeepingtx.ExecuteTransaction(rules, st.msg.BlockNumber, st.msg.TransactionHash, st.evm.StateDB)
 			if err != nil {
 				return nil, fmt.Errorf("failed to execute housekeeping transaction: %w", err)
 			}
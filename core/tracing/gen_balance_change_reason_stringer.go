@@ -25,6 +25,7 @@ func _() {
 	_ = x[BalanceDecreaseSelfdestructBurn-14]
 	_ = x[BalanceChangeRevert-15]
 	_ = x[BalanceMint-200]
+	_ = x[BalanceDecreaseArkivBurn-201]
 }
 
 const _BalanceChangeReason_name = "UnspecifiedBalanceIncreaseRewardMineUncleBalanceIncreaseRewardMineBlockBalanceIncreaseWithdrawalBalanceIncreaseGenesisBalanceBalanceIncreaseRewardTransactionFeeBalanceDecreaseGasBuyBalanceIncreaseGasReturnBalanceIncreaseDaoContractBalanceDecreaseDaoAccountTransferTouchAccountBalanceIncreaseSelfdestructBalanceDecreaseSelfdestructBalanceDecreaseSelfdestructBurnRevert"
@@ -36,6 +37,10 @@ func (i BalanceChangeReason) String() string {
 	if i == BalanceMint {
 		return "BalanceMint"
 	}
+	// Arkiv addition
+	if i == BalanceDecreaseArkivBurn {
+		return "BalanceDecreaseArkivBurn"
+	}
 
 	if i >= BalanceChangeReason(len(_BalanceChangeReason_index)-1) {
 		return "BalanceChangeReason(" + strconv.FormatInt(int64(i), 10) + ")"
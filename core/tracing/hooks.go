@@ -273,6 +273,10 @@ const (
 
 	// BalanceMint is an OP-Stack addition for an event that is emitted when the balance changes due to a mint operation.
 	BalanceMint BalanceChangeReason = 200
+
+	// BalanceDecreaseArkivBurn is an Arkiv addition for the renewal fees and the
+	// forfeited renewal budgets of entities, burned from the processor balance.
+	BalanceDecreaseArkivBurn BalanceChangeReason = 201
 )
 
 // GasChangeReason is used to indicate the reason for a gas change, useful
@@ -0,0 +1,145 @@
+package legacypool
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrArkivAccountBytesLimit is returned if accepting an Arkiv transaction would
+	// exceed the decompressed payload bytes allowed in the pool per account.
+	ErrArkivAccountBytesLimit = errors.New("arkiv payload bytes limit per account exceeded")
+
+	// ErrArkivAccountOpsLimit is returned if accepting an Arkiv transaction would
+	// exceed the number of Arkiv operations allowed in the pool per account.
+	ErrArkivAccountOpsLimit = errors.New("arkiv operations limit per account exceeded")
+)
+
+// arkivTxInfo is the decoded and validated form of an Arkiv transaction,
+// cached by transaction hash so the payload is decompressed only once.
+type arkivTxInfo struct {
+	tx    *storagetx.ArkivTransaction
+	bytes uint64 // Size of the decompressed payload
+	ops   uint64 // Number of operations in the transaction
+	gas   uint64 // Gas used by the transaction, which doesn't depend on its gas limit
+}
+
+// isArkivTx returns whether tx is addressed to the Arkiv processor.
+func isArkivTx(tx *types.Transaction) bool {
+	to := tx.To()
+	return to != nil && *to == address.ArkivProcessorAddress
+}
+
+// decodeArkivTx decompresses, decodes and validates the payload of an Arkiv transaction.
+func decodeArkivTx(tx *types.Transaction, rules params.Rules) (*arkivTxInfo, error) {
+	if len(tx.Data()) == 0 {
+		return nil, fmt.Errorf("arkiv transaction data is empty")
+	}
+
+	atx, size, err := storagetx.UnpackArkivTransactionForRules(tx.Data(), rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack arkiv transaction: %w", err)
+	}
+
+	if err := atx.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate arkiv transaction: %w", err)
+	}
+
+	// Arkiv transactions are charged the intrinsic gas only, and the floor data
+	// gas since Prague
+	gas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), false, true, rules.IsIstanbul, rules.IsShanghai)
+	if err != nil {
+		return nil, err
+	}
+	if rules.IsPrague {
+		floorDataGas, err := core.FloorDataGas(tx.Data())
+		if err != nil {
+			return nil, err
+		}
+		gas = max(gas, floorDataGas)
+	}
+
+	return &arkivTxInfo{
+		tx:    atx,
+		bytes: uint64(size),
+		ops:   uint64(atx.OperationCount()),
+		gas:   gas,
+	}, nil
+}
+
+// arkivInfo returns the decoded payload of an Arkiv transaction, decoding and
+// caching it if it is not cached yet.
+func (pool *LegacyPool) arkivInfo(tx *types.Transaction) (*arkivTxInfo, error) {
+	hash := tx.Hash()
+	if info, ok := pool.arkivTxs.Get(hash); ok {
+		return info, nil
+	}
+	head := pool.currentHead.Load()
+	info, err := decodeArkivTx(tx, pool.chainconfig.Rules(head.Number, head.Difficulty.Sign() == 0, head.Time))
+	if err != nil {
+		return nil, err
+	}
+	pool.arkivTxs.Add(hash, info)
+	return info, nil
+}
+
+// arkivUsage returns the decoded payload of tx if it is a valid Arkiv
+// transaction, or nil otherwise.
+func (pool *LegacyPool) arkivUsage(tx *types.Transaction) *arkivTxInfo {
+	if !isArkivTx(tx) {
+		return nil
+	}
+	info, err := pool.arkivInfo(tx)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// validateArkivLimits ensures that the Arkiv transactions of the sender of tx
+// already in the pool, together with tx, stay within the per-account payload
+// bytes and operation limits. A transaction replaced by tx is not counted.
+//
+// The caller must hold pool.mu.
+func (pool *LegacyPool) validateArkivLimits(tx *types.Transaction) error {
+	if !isArkivTx(tx) {
+		return nil
+	}
+	info, err := pool.arkivInfo(tx)
+	if err != nil {
+		return err
+	}
+	from, _ := types.Sender(pool.signer, tx) // validated
+
+	bytes, ops := info.bytes, info.ops
+	for _, list := range []*list{pool.pending[from], pool.queue[from]} {
+		if list == nil {
+			continue
+		}
+		for _, ptx := range list.txs.items {
+			if ptx.Nonce() == tx.Nonce() || !isArkivTx(ptx) {
+				continue
+			}
+			pinfo, err := pool.arkivInfo(ptx)
+			if err != nil {
+				continue
+			}
+			bytes += pinfo.bytes
+			ops += pinfo.ops
+		}
+	}
+
+	if bytes > pool.config.ArkivAccountBytes {
+		return fmt.Errorf("%w: %d > %d", ErrArkivAccountBytesLimit, bytes, pool.config.ArkivAccountBytes)
+	}
+	if ops > pool.config.ArkivAccountOps {
+		return fmt.Errorf("%w: %d > %d", ErrArkivAccountOpsLimit, ops, pool.config.ArkivAccountOps)
+	}
+	return nil
+}
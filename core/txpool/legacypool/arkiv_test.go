@@ -0,0 +1,130 @@
+package legacypool
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func arkivTransaction(t *testing.T, nonce uint64, gasprice *big.Int, key *ecdsa.PrivateKey, atx *storagetx.ArkivTransaction) *types.Transaction {
+	data, err := rlp.EncodeToBytes(atx)
+	require.NoError(t, err)
+
+	tx, err := types.SignTx(types.NewTransaction(nonce, address.ArkivProcessorAddress, common.Big0, 1000000, gasprice, compression.MustBrotliCompress(data)), types.HomesteadSigner{}, key)
+	require.NoError(t, err)
+	return tx
+}
+
+func arkivCreates(n int, payloadSize int) *storagetx.ArkivTransaction {
+	atx := &storagetx.ArkivTransaction{}
+	for range n {
+		atx.Create = append(atx.Create, storagetx.ArkivCreate{
+			BTL:         100,
+			ContentType: "application/octet-stream",
+			Payload:     make([]byte, payloadSize),
+		})
+	}
+	return atx
+}
+
+func TestArkivTransactionValidation(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	invalid := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 0, ContentType: "text/plain"}},
+	}
+	require.ErrorContains(t, pool.ValidateTxBasics(arkivTransaction(t, 0, big.NewInt(1), key, invalid)), "create BTL is 0")
+
+	valid := arkivTransaction(t, 0, big.NewInt(1), key, arkivCreates(2, 10))
+	require.NoError(t, pool.ValidateTxBasics(valid))
+
+	info, ok := pool.arkivTxs.Get(valid.Hash())
+	require.True(t, ok)
+	require.Equal(t, uint64(2), info.ops)
+	require.Greater(t, info.gas, params.TxGas)
+	require.Less(t, info.gas, valid.Gas())
+}
+
+func TestArkivTransactionSize(t *testing.T) {
+	t.Parallel()
+
+	config := testTxPoolConfig
+	config.ArkivTxMaxSize = 256 * 1024
+
+	pool, key := setupPoolWithTxPoolConfig(params.TestChainConfig, config)
+	defer pool.Close()
+
+	// random payloads don't compress
+	sized := func(to common.Address, size int, gasprice *big.Int) *types.Transaction {
+		payload := make([]byte, size)
+		_, err := rand.Read(payload)
+		require.NoError(t, err)
+		data := payload
+		if to == address.ArkivProcessorAddress {
+			atx := &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{{BTL: 100, ContentType: "application/octet-stream", Payload: payload}}}
+			encoded, err := rlp.EncodeToBytes(atx)
+			require.NoError(t, err)
+			data = compression.MustBrotliCompress(encoded)
+		}
+		tx, err := types.SignTx(types.NewTransaction(0, to, common.Big0, 9000000, gasprice, data), types.HomesteadSigner{}, key)
+		require.NoError(t, err)
+		return tx
+	}
+
+	// Arkiv transactions are limited by ArkivTxMaxSize instead of txMaxSize
+	require.Greater(t, sized(address.ArkivProcessorAddress, 150*1024, big.NewInt(1)).Size(), uint64(txMaxSize))
+	require.NoError(t, pool.ValidateTxBasics(sized(address.ArkivProcessorAddress, 150*1024, big.NewInt(1))))
+	require.ErrorIs(t, pool.ValidateTxBasics(sized(common.Address{1}, 150*1024, big.NewInt(1))), txpool.ErrOversizedData)
+	require.ErrorIs(t, pool.ValidateTxBasics(sized(address.ArkivProcessorAddress, 300*1024, big.NewInt(1))), txpool.ErrOversizedData)
+
+	// and have to pay the minimum tip
+	pool.SetGasTip(big.NewInt(2))
+	require.ErrorIs(t, pool.ValidateTxBasics(sized(address.ArkivProcessorAddress, 1024, big.NewInt(1))), txpool.ErrTxGasPriceTooLow)
+	require.NoError(t, pool.ValidateTxBasics(sized(address.ArkivProcessorAddress, 1024, big.NewInt(2))))
+}
+
+func TestArkivAccountLimits(t *testing.T) {
+	t.Parallel()
+
+	config := testTxPoolConfig
+	config.ArkivAccountOps = 5
+	config.ArkivAccountBytes = 4096
+
+	pool, key := setupPoolWithTxPoolConfig(params.TestChainConfig, config)
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000000))
+
+	require.NoError(t, pool.addRemoteSync(arkivTransaction(t, 0, big.NewInt(1), key, arkivCreates(3, 10))))
+	require.ErrorIs(t, pool.addRemoteSync(arkivTransaction(t, 1, big.NewInt(1), key, arkivCreates(3, 10))), ErrArkivAccountOpsLimit)
+	require.NoError(t, pool.addRemoteSync(arkivTransaction(t, 1, big.NewInt(1), key, arkivCreates(2, 10))))
+
+	// A replacement is accounted for without the transaction it replaces
+	require.ErrorIs(t, pool.addRemoteSync(arkivTransaction(t, 1, big.NewInt(2), key, arkivCreates(1, 5000))), ErrArkivAccountBytesLimit)
+	require.NoError(t, pool.addRemoteSync(arkivTransaction(t, 1, big.NewInt(2), key, arkivCreates(1, 10))))
+
+	pending, queued := pool.Stats()
+	require.Equal(t, 2, pending)
+	require.Equal(t, 0, queued)
+
+	// The pending transactions carry their decoded payloads
+	lazies := pool.Pending(txpool.PendingFilter{})[crypto.PubkeyToAddress(key.PublicKey)]
+	require.Len(t, lazies, 2)
+	require.Len(t, lazies[0].ArkivTx.Create, 3)
+	require.Len(t, lazies[1].ArkivTx.Create, 1)
+}
@@ -20,7 +20,6 @@ package legacypool
 import (
 	"context"
 	"errors"
-	"fmt"
 	"maps"
 	"math"
 	"math/big"
@@ -30,8 +29,8 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/arkiv/compression"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
@@ -40,7 +39,6 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/golem-base/address"
-	"github.com/ethereum/go-ethereum/golem-base/storagetx"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -172,6 +170,10 @@ type Config struct {
 	FilterInterval time.Duration
 
 	DisableNonGolemBaseTransactions bool // Disallow non-Golembase transactions such as transfers to non-Golembase accounts and contract creations
+
+	ArkivAccountBytes uint64 // Maximum decompressed Arkiv payload bytes in the pool per account
+	ArkivAccountOps   uint64 // Maximum number of Arkiv operations in the pool per account
+	ArkivTxMaxSize    uint64 // Maximum size of a single Arkiv transaction, which replaces txMaxSize for them
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -191,6 +193,10 @@ var DefaultConfig = Config{
 
 	Lifetime:       3 * time.Hour,
 	FilterInterval: 12 * time.Second,
+
+	ArkivAccountBytes: 16 * 1024 * 1024,
+	ArkivAccountOps:   1024,
+	ArkivTxMaxSize:    128 * txSlotSize, // 4MB
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -229,6 +235,18 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool filter interval", "provided", conf.FilterInterval, "updated", DefaultConfig.FilterInterval)
 		conf.FilterInterval = DefaultConfig.FilterInterval
 	}
+	if conf.ArkivAccountBytes < 1 {
+		log.Warn("Sanitizing invalid txpool arkiv account bytes", "provided", conf.ArkivAccountBytes, "updated", DefaultConfig.ArkivAccountBytes)
+		conf.ArkivAccountBytes = DefaultConfig.ArkivAccountBytes
+	}
+	if conf.ArkivAccountOps < 1 {
+		log.Warn("Sanitizing invalid txpool arkiv account ops", "provided", conf.ArkivAccountOps, "updated", DefaultConfig.ArkivAccountOps)
+		conf.ArkivAccountOps = DefaultConfig.ArkivAccountOps
+	}
+	if conf.ArkivTxMaxSize < txMaxSize {
+		log.Warn("Sanitizing invalid txpool arkiv transaction max size", "provided", conf.ArkivTxMaxSize, "updated", DefaultConfig.ArkivTxMaxSize)
+		conf.ArkivTxMaxSize = DefaultConfig.ArkivTxMaxSize
+	}
 	return conf
 }
 
@@ -290,6 +308,8 @@ type LegacyPool struct {
 	filterCancel   context.CancelFunc     // Cancel function for the filter context
 
 	disableNonGolembaseTransactions bool
+
+	arkivTxs *lru.Cache[common.Hash, *arkivTxInfo] // Decoded payloads of Arkiv transactions
 }
 
 type txpoolResetRequest struct {
@@ -319,6 +339,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		reorgShutdownCh:                 make(chan struct{}),
 		initDoneCh:                      make(chan struct{}),
 		disableNonGolembaseTransactions: config.DisableNonGolemBaseTransactions,
+		arkivTxs:                        lru.NewCache[common.Hash, *arkivTxInfo](int(config.GlobalSlots + config.GlobalQueue)),
 	}
 	pool.priced = newPricedList(pool.all)
 
@@ -642,6 +663,12 @@ func (pool *LegacyPool) Pending(filter txpool.PendingFilter) map[common.Address]
 					BlobGas:   txs[i].BlobGas(),
 					DABytes:   daBytes,
 				}
+				if info := pool.arkivUsage(txs[i]); info != nil {
+					lazies[i].ArkivBytes = info.bytes
+					lazies[i].ArkivOps = info.ops
+					lazies[i].ArkivGas = info.gas
+					lazies[i].ArkivTx = info.tx
+				}
 			}
 			pending[addr] = lazies
 		}
@@ -669,31 +696,6 @@ func (pool *LegacyPool) ValidateTxBasics(tx *types.Transaction) error {
 
 	}
 
-	switch {
-	case to != nil && *to == address.ArkivProcessorAddress:
-		if len(tx.Data()) == 0 {
-			return fmt.Errorf("arkiv transaction data is empty")
-		}
-
-		_, err := compression.BrotliDecompress(tx.Data())
-		if err != nil {
-			return fmt.Errorf("failed to decompress arkiv transaction data: %w", err)
-		}
-
-		tx, err := storagetx.UnpackArkivTransaction(tx.Data())
-		if err != nil {
-			return fmt.Errorf("failed to unpack arkiv transaction: %w", err)
-		}
-
-		tx.Validate()
-		if err != nil {
-			return fmt.Errorf("failed to validate arkiv transaction: %w", err)
-		}
-
-		return nil
-
-	}
-
 	opts := &txpool.ValidationOptions{
 		Config: pool.chainconfig,
 		Accept: 0 |
@@ -706,7 +708,21 @@ func (pool *LegacyPool) ValidateTxBasics(tx *types.Transaction) error {
 		EffectiveGasCeil: pool.config.EffectiveGasCeil,
 		MaxTxGasLimit:    pool.config.MaxTxGasLimit,
 	}
-	return txpool.ValidateTransaction(tx, pool.currentHead.Load(), pool.signer, opts)
+	if isArkivTx(tx) {
+		// The payloads are compressed already, and can be larger than those
+		// of regular transactions
+		opts.MaxSize = pool.config.ArkivTxMaxSize
+	}
+	if err := txpool.ValidateTransaction(tx, pool.currentHead.Load(), pool.signer, opts); err != nil {
+		return err
+	}
+	if isArkivTx(tx) {
+		// Decode the payload once, later lookups are served from the cache
+		if _, err := pool.arkivInfo(tx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // validateTx checks whether a transaction is valid according to the consensus
@@ -739,6 +755,9 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction) error {
 	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
 		return err
 	}
+	if err := pool.validateArkivLimits(tx); err != nil {
+		return err
+	}
 	return pool.validateAuth(tx)
 }
 
@@ -1252,6 +1271,7 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 	}
 	// Remove it from the list of known transactions
 	pool.all.Remove(hash)
+	pool.arkivTxs.Remove(hash)
 	if outofbound {
 		pool.priced.Removed(1)
 	}
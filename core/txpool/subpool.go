@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
 	"github.com/holiman/uint256"
 )
 
@@ -43,6 +44,12 @@ type LazyTransaction struct {
 	BlobGas uint64 // Amount of blob gas required by the transaction
 
 	DABytes *big.Int // Amount of data availability bytes this transaction may require if this is a rollup
+
+	ArkivBytes uint64 // Decompressed Arkiv payload bytes if this is an Arkiv transaction, 0 otherwise
+	ArkivOps   uint64 // Number of Arkiv operations if this is an Arkiv transaction, 0 otherwise
+	ArkivGas   uint64 // Gas used by the transaction if this is an Arkiv transaction, 0 otherwise
+
+	ArkivTx *storagetx.ArkivTransaction // Decoded Arkiv payload if this is an Arkiv transaction, nil otherwise. Must not be modified.
 }
 
 // Resolve retrieves the full transaction belonging to a lazy handle if it is still
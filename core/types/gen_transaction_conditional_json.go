@@ -19,6 +19,7 @@ func (t TransactionConditional) MarshalJSON() ([]byte, error) {
 		BlockNumberMax *math.HexOrDecimal256 `json:"blockNumberMax,omitempty"`
 		TimestampMin   *math.HexOrDecimal64  `json:"timestampMin,omitempty"`
 		TimestampMax   *math.HexOrDecimal64  `json:"timestampMax,omitempty"`
+		Arkiv          *ArkivConditional     `json:"arkiv,omitempty"`
 	}
 	var enc TransactionConditional
 	enc.KnownAccounts = t.KnownAccounts
@@ -26,6 +27,7 @@ func (t TransactionConditional) MarshalJSON() ([]byte, error) {
 	enc.BlockNumberMax = (*math.HexOrDecimal256)(t.BlockNumberMax)
 	enc.TimestampMin = (*math.HexOrDecimal64)(t.TimestampMin)
 	enc.TimestampMax = (*math.HexOrDecimal64)(t.TimestampMax)
+	enc.Arkiv = t.Arkiv
 	return json.Marshal(&enc)
 }
 
@@ -37,6 +39,7 @@ func (t *TransactionConditional) UnmarshalJSON(input []byte) error {
 		BlockNumberMax *math.HexOrDecimal256 `json:"blockNumberMax,omitempty"`
 		TimestampMin   *math.HexOrDecimal64  `json:"timestampMin,omitempty"`
 		TimestampMax   *math.HexOrDecimal64  `json:"timestampMax,omitempty"`
+		Arkiv          *ArkivConditional     `json:"arkiv,omitempty"`
 	}
 	var dec TransactionConditional
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -57,5 +60,8 @@ func (t *TransactionConditional) UnmarshalJSON(input []byte) error {
 	if dec.TimestampMax != nil {
 		t.TimestampMax = (*uint64)(dec.TimestampMax)
 	}
+	if dec.Arkiv != nil {
+		t.Arkiv = dec.Arkiv
+	}
 	return nil
 }
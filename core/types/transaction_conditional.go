@@ -82,6 +82,9 @@ type TransactionConditional struct {
 	BlockNumberMax *big.Int `json:"blockNumberMax,omitempty"`
 	TimestampMin   *uint64  `json:"timestampMin,omitempty"`
 	TimestampMax   *uint64  `json:"timestampMax,omitempty"`
+
+	// Arkiv storage layer conditionals
+	Arkiv *ArkivConditional `json:"arkiv,omitempty"`
 }
 
 // field type overrides for gencodec
@@ -100,6 +103,9 @@ func (cond *TransactionConditional) Validate() error {
 	if cond.TimestampMin != nil && cond.TimestampMax != nil && *cond.TimestampMin > *cond.TimestampMax {
 		return fmt.Errorf("timestamp minimum constraint must be less than the maximum")
 	}
+	if cond.Arkiv != nil {
+		return cond.Arkiv.Validate()
+	}
 	return nil
 }
 
@@ -122,5 +128,8 @@ func (cond *TransactionConditional) Cost() int {
 	if cond.TimestampMin != nil || cond.TimestampMax != nil {
 		cost += 1
 	}
+	if cond.Arkiv != nil {
+		cost += cond.Arkiv.Cost()
+	}
 	return cost
 }
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrArkivStoreNotReady is returned if the query preconditions can't be checked
+// because the Arkiv store isn't at the block they are checked against, either
+// as it hasn't ingested the block yet or as it has moved past it.
+var ErrArkivStoreNotReady = errors.New("arkiv store is not at the block")
+
+// ArkivConditional represents preconditions on the Arkiv storage layer that
+// determine the inclusion of the transaction. Like the other preconditions they
+// are enforced out-of-protocol by the sequencer.
+type ArkivConditional struct {
+	// Entities are conditions on single entities, checked against the state the
+	// transaction is applied to
+	Entities []ArkivEntityCondition `json:"entities,omitempty"`
+
+	// Queries are conditions on the number of entities matching a query, checked
+	// against the store at the block the transaction builds on
+	Queries []ArkivQueryCondition `json:"queries,omitempty"`
+}
+
+//go:generate go run github.com/fjl/gencodec -type ArkivEntityCondition -field-override arkivEntityConditionMarshalling -out gen_arkiv_entity_condition_json.go
+
+// ArkivEntityCondition is a precondition on the entity with the given key. Every
+// set field must hold. Requiring an owner or an expiration implies that the
+// entity exists.
+type ArkivEntityCondition struct {
+	Key          common.Hash     `json:"key" gencodec:"required"`
+	Exists       *bool           `json:"exists,omitempty"`       // Whether the entity exists
+	Owner        *common.Address `json:"owner,omitempty"`        // Owner the entity must have
+	ExpiresAfter *uint64         `json:"expiresAfter,omitempty"` // Block the entity must expire after
+}
+
+// field type overrides for gencodec
+type arkivEntityConditionMarshalling struct {
+	ExpiresAfter *math.HexOrDecimal64
+}
+
+//go:generate go run github.com/fjl/gencodec -type ArkivQueryCondition -field-override arkivQueryConditionMarshalling -out gen_arkiv_query_condition_json.go
+
+// ArkivQueryCondition is a precondition on the number of entities matching a
+// query, within the inclusive range of MinResults and MaxResults. A MaxResults
+// of 0 requires the query to match nothing.
+type ArkivQueryCondition struct {
+	Query      string  `json:"query" gencodec:"required"`
+	MinResults *uint64 `json:"minResults,omitempty"`
+	MaxResults *uint64 `json:"maxResults,omitempty"`
+}
+
+// field type overrides for gencodec
+type arkivQueryConditionMarshalling struct {
+	MinResults *math.HexOrDecimal64
+	MaxResults *math.HexOrDecimal64
+}
+
+// Validate will perform sanity checks on the Arkiv preconditions.
+func (cond *ArkivConditional) Validate() error {
+	for _, entity := range cond.Entities {
+		if entity.Exists == nil && entity.Owner == nil && entity.ExpiresAfter == nil {
+			return fmt.Errorf("entity %s constraint has no condition", entity.Key)
+		}
+		if entity.Exists != nil && !*entity.Exists && (entity.Owner != nil || entity.ExpiresAfter != nil) {
+			return fmt.Errorf("entity %s constraint requires an owner or expiration of a missing entity", entity.Key)
+		}
+	}
+	for _, query := range cond.Queries {
+		if query.Query == "" {
+			return errors.New("query constraint has no query")
+		}
+		if query.MinResults == nil && query.MaxResults == nil {
+			return fmt.Errorf("query %q constraint has no result bounds", query.Query)
+		}
+		if query.MinResults != nil && query.MaxResults != nil && *query.MinResults > *query.MaxResults {
+			return fmt.Errorf("query %q constraint minimum results must be less than the maximum", query.Query)
+		}
+	}
+	return nil
+}
+
+// Cost computes the aggregate cost of the Arkiv preconditions. An entity is a
+// single storage lookup, while a query is more expensive to evaluate.
+func (cond *ArkivConditional) Cost() int {
+	return len(cond.Entities) + len(cond.Queries)*params.TransactionConditionalArkivQueryCost
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestTransactionConditionalCost(t *testing.T) {
@@ -76,6 +77,16 @@ func TestTransactionConditionalCost(t *testing.T) {
 					}}},
 			cost: 7,
 		},
+		{
+			name: "arkiv entities and queries",
+			cond: TransactionConditional{
+				Arkiv: &ArkivConditional{
+					Entities: []ArkivEntityCondition{{Key: common.Hash{1}}, {Key: common.Hash{2}}},
+					Queries:  []ArkivQueryCondition{{Query: `type = "note"`}},
+				},
+			},
+			cost: 2 + params.TransactionConditionalArkivQueryCost,
+		},
 	}
 
 	for _, test := range tests {
@@ -123,6 +134,38 @@ func TestTransactionConditionalValidation(t *testing.T) {
 			cond:     TransactionConditional{TimestampMin: uint64Ptr(2), TimestampMax: uint64Ptr(1)},
 			mustFail: true,
 		},
+		{
+			name: "arkiv entity and query constraints",
+			cond: TransactionConditional{Arkiv: &ArkivConditional{
+				Entities: []ArkivEntityCondition{{Key: common.Hash{1}, Owner: &common.Address{1}, ExpiresAfter: uint64Ptr(10)}},
+				Queries:  []ArkivQueryCondition{{Query: `type = "note"`, MinResults: uint64Ptr(1), MaxResults: uint64Ptr(1)}},
+			}},
+			mustFail: false,
+		},
+		{
+			name:     "arkiv entity without condition",
+			cond:     TransactionConditional{Arkiv: &ArkivConditional{Entities: []ArkivEntityCondition{{Key: common.Hash{1}}}}},
+			mustFail: true,
+		},
+		{
+			name: "arkiv owner of missing entity",
+			cond: TransactionConditional{Arkiv: &ArkivConditional{
+				Entities: []ArkivEntityCondition{{Key: common.Hash{1}, Exists: new(bool), Owner: &common.Address{1}}},
+			}},
+			mustFail: true,
+		},
+		{
+			name:     "arkiv query without bounds",
+			cond:     TransactionConditional{Arkiv: &ArkivConditional{Queries: []ArkivQueryCondition{{Query: `type = "note"`}}}},
+			mustFail: true,
+		},
+		{
+			name: "arkiv query min results greater than max",
+			cond: TransactionConditional{Arkiv: &ArkivConditional{
+				Queries: []ArkivQueryCondition{{Query: `type = "note"`, MinResults: uint64Ptr(2), MaxResults: uint64Ptr(1)}},
+			}},
+			mustFail: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -145,6 +188,9 @@ func TestTransactionConditionalSerDeser(t *testing.T) {
 	hashPtr := func(hash common.Hash) *common.Hash {
 		return &hash
 	}
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
 
 	tests := []struct {
 		name     string
@@ -235,6 +281,23 @@ func TestTransactionConditionalSerDeser(t *testing.T) {
 				TimestampMax: uint64Ptr(uint64(0xffffff)),
 			},
 		},
+		{
+			name:     "Arkiv",
+			input:    `{"arkiv":{"entities":[{"key":"0x0100000000000000000000000000000000000000000000000000000000000000","exists":true,"expiresAfter":"0x10"}],"queries":[{"query":"type = \"note\"","maxResults":0}]}}`,
+			mustFail: false,
+			expected: TransactionConditional{
+				Arkiv: &ArkivConditional{
+					Entities: []ArkivEntityCondition{{Key: common.Hash{1}, Exists: boolPtr(true), ExpiresAfter: uint64Ptr(0x10)}},
+					Queries:  []ArkivQueryCondition{{Query: `type = "note"`, MaxResults: uint64Ptr(0)}},
+				},
+			},
+		},
+		{
+			name:     "Arkiv entity without key",
+			input:    `{"arkiv":{"entities":[{"exists":true}]}}`,
+			mustFail: true,
+			expected: TransactionConditional{},
+		},
 		{
 			name:     "Timestamp (decimal)",
 			input:    `{"timestampMin": 0, "timestampMax": 1}`,
@@ -299,6 +299,9 @@ func init() {
 }
 
 func activePrecompiledContracts(rules params.Rules) PrecompiledContracts {
+	if rules.IsArkivPrecompile {
+		return activeArkivPrecompiles(rules).contracts
+	}
 	// note: the order of these switch cases is important
 	switch {
 	case rules.IsOptimismJovian:
@@ -335,6 +338,9 @@ func ActivePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 
 // ActivePrecompiles returns the precompile addresses enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	if rules.IsArkivPrecompile {
+		return activeArkivPrecompiles(rules).addresses
+	}
 	switch {
 	case rules.IsOptimismJovian:
 		return PrecompiledAddressesJovian
@@ -0,0 +1,158 @@
+package vm
+
+import (
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// statefulPrecompiledContract is implemented by precompiles that need read
+// access to the state. The EVM binds the current StateDB before running them.
+type statefulPrecompiledContract interface {
+	PrecompiledContract
+	withState(db StateDB) PrecompiledContract
+}
+
+// PrecompiledContractsArkiv contains the precompiled contracts enabled by the
+// Arkiv precompile fork, on top of the precompiled contracts of the other forks.
+var PrecompiledContractsArkiv = PrecompiledContracts{
+	address.ArkivEntityMetaDataPrecompileAddress: &arkivEntityMetaData{},
+}
+
+// PrecompiledAddressesArkiv are the addresses of PrecompiledContractsArkiv, in
+// the order they follow the addresses of the other forks.
+var PrecompiledAddressesArkiv = []common.Address{
+	address.ArkivEntityMetaDataPrecompileAddress,
+}
+
+// arkivPrecompileSet is the set of precompiled contracts of a fork, extended by
+// the Arkiv precompiled contracts, along with the addresses of the set.
+type arkivPrecompileSet struct {
+	contracts PrecompiledContracts
+	addresses []common.Address
+}
+
+func newArkivPrecompileSet(base PrecompiledContracts) arkivPrecompileSet {
+	contracts := maps.Clone(base)
+	maps.Copy(contracts, PrecompiledContractsArkiv)
+	return arkivPrecompileSet{contracts: contracts}
+}
+
+var (
+	arkivPrecompilesJovian    = newArkivPrecompileSet(PrecompiledContractsJovian)
+	arkivPrecompilesIsthmus   = newArkivPrecompileSet(PrecompiledContractsIsthmus)
+	arkivPrecompilesGranite   = newArkivPrecompileSet(PrecompiledContractsGranite)
+	arkivPrecompilesFjord     = newArkivPrecompileSet(PrecompiledContractsFjord)
+	arkivPrecompilesVerkle    = newArkivPrecompileSet(PrecompiledContractsVerkle)
+	arkivPrecompilesOsaka     = newArkivPrecompileSet(PrecompiledContractsOsaka)
+	arkivPrecompilesPrague    = newArkivPrecompileSet(PrecompiledContractsPrague)
+	arkivPrecompilesCancun    = newArkivPrecompileSet(PrecompiledContractsCancun)
+	arkivPrecompilesBerlin    = newArkivPrecompileSet(PrecompiledContractsBerlin)
+	arkivPrecompilesIstanbul  = newArkivPrecompileSet(PrecompiledContractsIstanbul)
+	arkivPrecompilesByzantium = newArkivPrecompileSet(PrecompiledContractsByzantium)
+	arkivPrecompilesHomestead = newArkivPrecompileSet(PrecompiledContractsHomestead)
+)
+
+// init appends the Arkiv addresses to the addresses of the base forks, which the
+// init of contracts.go collects before, so that every call of ActivePrecompiles
+// returns the same order.
+func init() {
+	for set, base := range map[*arkivPrecompileSet][]common.Address{
+		&arkivPrecompilesJovian:    PrecompiledAddressesJovian,
+		&arkivPrecompilesIsthmus:   PrecompiledAddressesIsthmus,
+		&arkivPrecompilesGranite:   PrecompiledAddressesGranite,
+		&arkivPrecompilesFjord:     PrecompiledAddressesFjord,
+		&arkivPrecompilesVerkle:    PrecompiledAddressesBerlin,
+		&arkivPrecompilesOsaka:     PrecompiledAddressesOsaka,
+		&arkivPrecompilesPrague:    PrecompiledAddressesPrague,
+		&arkivPrecompilesCancun:    PrecompiledAddressesCancun,
+		&arkivPrecompilesBerlin:    PrecompiledAddressesBerlin,
+		&arkivPrecompilesIstanbul:  PrecompiledAddressesIstanbul,
+		&arkivPrecompilesByzantium: PrecompiledAddressesByzantium,
+		&arkivPrecompilesHomestead: PrecompiledAddressesHomestead,
+	} {
+		set.addresses = slices.Concat(base, PrecompiledAddressesArkiv)
+	}
+}
+
+// activeArkivPrecompiles returns the precompiled contracts of the forks active
+// in rules, extended by the Arkiv precompiled contracts. The order of the cases
+// follows activePrecompiledContracts.
+func activeArkivPrecompiles(rules params.Rules) *arkivPrecompileSet {
+	switch {
+	case rules.IsOptimismJovian:
+		return &arkivPrecompilesJovian
+	case rules.IsOptimismIsthmus:
+		return &arkivPrecompilesIsthmus
+	case rules.IsOptimismGranite:
+		return &arkivPrecompilesGranite
+	case rules.IsOptimismFjord:
+		return &arkivPrecompilesFjord
+	case rules.IsVerkle:
+		return &arkivPrecompilesVerkle
+	case rules.IsOsaka:
+		return &arkivPrecompilesOsaka
+	case rules.IsPrague:
+		return &arkivPrecompilesPrague
+	case rules.IsCancun:
+		return &arkivPrecompilesCancun
+	case rules.IsBerlin:
+		return &arkivPrecompilesBerlin
+	case rules.IsIstanbul:
+		return &arkivPrecompilesIstanbul
+	case rules.IsByzantium:
+		return &arkivPrecompilesByzantium
+	default:
+		return &arkivPrecompilesHomestead
+	}
+}
+
+var errArkivEntityMetaDataInputLength = errors.New("invalid input length")
+
+// arkivEntityMetaData implements a read-only precompile returning the metadata
+// of an Arkiv entity.
+//
+// The input is the 32 byte entity key. The output is two 32 byte words: the
+// owner address and the block at which the entity expires. A non-existent
+// entity yields all-zero words, which lets contracts check for existence by
+// comparing the owner against the zero address.
+type arkivEntityMetaData struct {
+	db StateDB
+}
+
+func (c *arkivEntityMetaData) withState(db StateDB) PrecompiledContract {
+	return &arkivEntityMetaData{db: db}
+}
+
+// RequiredGas returns the gas required to execute the precompiled contract.
+// It is priced as a single cold storage read of the metadata slot.
+func (c *arkivEntityMetaData) RequiredGas(input []byte) uint64 {
+	return params.ColdSloadCostEIP2929
+}
+
+func (c *arkivEntityMetaData) Run(input []byte) ([]byte, error) {
+	if len(input) != common.HashLength {
+		return nil, errArkivEntityMetaDataInputLength
+	}
+
+	md, err := entity.GetEntityMetaData(c.db, common.BytesToHash(input))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 2*common.HashLength)
+	copy(out[12:32], md.Owner[:])
+	uint256.NewInt(md.ExpiresAtBlock).PutUint256(out[32:64])
+
+	return out, nil
+}
+
+func (c *arkivEntityMetaData) Name() string {
+	return "ARKIV_ENTITY_METADATA"
+}
@@ -0,0 +1,64 @@
+package vm
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArkivEntityMetaDataPrecompile(t *testing.T) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+
+	key := common.HexToHash("0x1234")
+	owner := common.HexToAddress("0xabcd")
+	require.NoError(t, entity.StoreEntityMetaData(statedb, key, entity.EntityMetaData{
+		Owner:          owner,
+		ExpiresAtBlock: 42,
+	}))
+
+	config := *params.OptimismTestConfig
+	fork := uint64(10)
+	config.ArkivPrecompileTime = &fork
+	blockContext := BlockContext{
+		BlockNumber: big.NewInt(1),
+		Time:        9,
+		Random:      &common.Hash{},
+	}
+
+	// Before the fork, the address is an empty account
+	evm := NewEVM(blockContext, statedb, &config, Config{})
+	require.NotContains(t, ActivePrecompiles(evm.chainRules), address.ArkivEntityMetaDataPrecompileAddress)
+	ret, _, err := evm.StaticCall(common.Address{}, address.ArkivEntityMetaDataPrecompileAddress, key[:], params.ColdSloadCostEIP2929)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+
+	blockContext.Time = 10
+	evm = NewEVM(blockContext, statedb, &config, Config{})
+	require.Contains(t, ActivePrecompiles(evm.chainRules), address.ArkivEntityMetaDataPrecompileAddress)
+
+	// The Arkiv addresses follow the addresses of the base fork, in their order
+	baseRules := evm.chainRules
+	baseRules.IsArkivPrecompile = false
+	require.Equal(t, slices.Concat(ActivePrecompiles(baseRules), PrecompiledAddressesArkiv), ActivePrecompiles(evm.chainRules))
+
+	ret, _, err = evm.StaticCall(common.Address{}, address.ArkivEntityMetaDataPrecompileAddress, key[:], params.ColdSloadCostEIP2929)
+	require.NoError(t, err)
+	require.Len(t, ret, 64)
+	require.Equal(t, owner, common.BytesToAddress(ret[:32]))
+	require.Equal(t, uint64(42), new(big.Int).SetBytes(ret[32:64]).Uint64())
+
+	ret, _, err = evm.StaticCall(common.Address{}, address.ArkivEntityMetaDataPrecompileAddress, common.HexToHash("0x5678").Bytes(), params.ColdSloadCostEIP2929)
+	require.NoError(t, err)
+	require.Equal(t, make([]byte, 64), ret)
+
+	_, _, err = evm.StaticCall(common.Address{}, address.ArkivEntityMetaDataPrecompileAddress, []byte{1, 2, 3}, params.ColdSloadCostEIP2929)
+	require.ErrorIs(t, err, errArkivEntityMetaDataInputLength)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
@@ -44,8 +45,11 @@ type (
 func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	p, ok := evm.precompiles[addr]
 	if evm.Config.PrecompileOverrides != nil {
-		override := evm.Config.PrecompileOverrides(evm.chainRules, p, addr)
-		return override, override != nil
+		p = evm.Config.PrecompileOverrides(evm.chainRules, p, addr)
+		ok = p != nil
+	}
+	if sp, isStateful := p.(statefulPrecompiledContract); isStateful {
+		p = sp.withState(evm.StateDB)
 	}
 	return p, ok
 }
@@ -85,6 +89,7 @@ type TxContext struct {
 	BlobHashes   []common.Hash       // Provides information for BLOBHASH
 	BlobFeeCap   *big.Int            // Is used to zero the blobbasefee if NoBaseFee is set
 	AccessEvents *state.AccessEvents // Capture all state accesses for this tx
+	TxHash       common.Hash         // Used to derive entity keys for Arkiv operations issued by contracts
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -138,6 +143,8 @@ type EVM struct {
 
 	readOnly   bool   // Whether to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
+
+	arkivCalls uint64 // Number of Arkiv processor calls in the current transaction
 }
 
 // NewEVM constructs an EVM instance with the supplied block context, state
@@ -225,6 +232,7 @@ func (evm *EVM) SetTxContext(txCtx TxContext) {
 		txCtx.AccessEvents = state.NewAccessEvents(evm.StateDB.PointCache())
 	}
 	evm.TxContext = txCtx
+	evm.arkivCalls = 0
 }
 
 // Cancel cancels any running EVM operation. This may be called concurrently and
@@ -273,6 +281,7 @@ func (evm *EVM) Call(caller common.Address, addr common.Address, input []byte, g
 	}
 	snapshot := evm.StateDB.Snapshot()
 	p, isPrecompile := evm.precompile(addr)
+	isArkivProcessor := evm.chainRules.IsArkivContractCalls && addr == address.ArkivProcessorAddress
 
 	if !evm.StateDB.Exist(addr) {
 		if !isPrecompile && evm.chainRules.IsEIP4762 && !isSystemCall(caller) {
@@ -291,7 +300,7 @@ func (evm *EVM) Call(caller common.Address, addr common.Address, input []byte, g
 			gas -= wgas
 		}
 
-		if !isPrecompile && evm.chainRules.IsEIP158 && value.IsZero() {
+		if !isPrecompile && !isArkivProcessor && evm.chainRules.IsEIP158 && value.IsZero() {
 			// Calling a non-existing account, don't do anything.
 			return nil, gas, nil
 		}
@@ -301,6 +310,8 @@ func (evm *EVM) Call(caller common.Address, addr common.Address, input []byte, g
 
 	if isPrecompile {
 		ret, gas, err = RunPrecompiledContract(p, input, gas, evm.Config.Tracer)
+	} else if isArkivProcessor {
+		gas, err = evm.callArkivProcessor(caller, input, gas, value)
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		code := evm.resolveCode(addr)
@@ -366,6 +377,8 @@ func (evm *EVM) CallCode(caller common.Address, addr common.Address, input []byt
 	// It is allowed to call precompiles, even via delegatecall
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
 		ret, gas, err = RunPrecompiledContract(p, input, gas, evm.Config.Tracer)
+	} else if evm.chainRules.IsArkivContractCalls && addr == address.ArkivProcessorAddress {
+		err = ErrArkivProcessorCallKind
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
@@ -410,6 +423,8 @@ func (evm *EVM) DelegateCall(originCaller common.Address, caller common.Address,
 	// It is allowed to call precompiles, even via delegatecall
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
 		ret, gas, err = RunPrecompiledContract(p, input, gas, evm.Config.Tracer)
+	} else if evm.chainRules.IsArkivContractCalls && addr == address.ArkivProcessorAddress {
+		err = ErrArkivProcessorCallKind
 	} else {
 		// Initialise a new contract and make initialise the delegate values
 		//
@@ -463,6 +478,8 @@ func (evm *EVM) StaticCall(caller common.Address, addr common.Address, input []b
 
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
 		ret, gas, err = RunPrecompiledContract(p, input, gas, evm.Config.Tracer)
+	} else if evm.chainRules.IsArkivContractCalls && addr == address.ArkivProcessorAddress {
+		err = ErrArkivProcessorCallKind
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
@@ -0,0 +1,108 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// ErrArkivProcessorCallKind is returned when the Arkiv processor is called with
+// CALLCODE, DELEGATECALL or STATICCALL. The operations of a call are issued on
+// behalf of the calling contract, which only CALL defines unambiguously, and
+// they modify the state, which STATICCALL forbids.
+var ErrArkivProcessorCallKind = errors.New("arkiv processor can only be called with CALL")
+
+// arkivCallGas returns the gas charged for a call to the Arkiv processor. It is
+// the gas the state transition charges a transaction sent to the processor with
+// input as data: the intrinsic gas and, since Prague, at least the floor data
+// gas. On top of that, the marker log is charged like a LOG4 with input as data.
+func (evm *EVM) arkivCallGas(input []byte) uint64 {
+	var (
+		dataLen = uint64(len(input))
+		z       = uint64(bytes.Count(input, []byte{0}))
+		nz      = dataLen - z
+	)
+	nonZeroGas := params.TxDataNonZeroGasFrontier
+	if evm.chainRules.IsIstanbul {
+		nonZeroGas = params.TxDataNonZeroGasEIP2028
+	}
+	gas := params.TxGas + nz*nonZeroGas + z*params.TxDataZeroGas
+	if evm.chainRules.IsPrague {
+		floorDataGas := params.TxGas + (nz*params.TxTokenPerNonZeroByte+z)*params.TxCostFloorPerToken
+		gas = max(gas, floorDataGas)
+	}
+	return gas + params.LogGas + 4*params.LogTopicGas + dataLen*params.LogDataGas
+}
+
+// ArkivCallHash returns the hash that stands for the transaction hash in the
+// execution of the Arkiv processor call with the given index, as carried by its
+// ArkivContractCall log.
+func ArkivCallHash(txHash common.Hash, callIndex common.Hash) common.Hash {
+	return crypto.Keccak256Hash(txHash[:], callIndex[:])
+}
+
+// callArkivProcessor executes the Arkiv transaction in input on behalf of caller.
+// This is the counterpart of the top-level path in the state transition for
+// CALLs issued by contracts. The call is charged the gas returned by
+// arkivCallGas, and a failing transaction reverts the call without consuming
+// the remaining gas.
+//
+// Entity keys are derived from the transaction hash mixed with the index of the
+// call within the transaction, so that several calls in one transaction don't
+// produce colliding keys. The index counts every call, including the ones that
+// fail or are reverted later, so it is carried by the marker log rather than
+// derived from the number of marker logs.
+//
+// The value of the call has been transferred to the processor already, it pays
+// for the renewal deposits like the value of a top-level transaction, and is
+// carried by the marker log as well.
+func (evm *EVM) callArkivProcessor(caller common.Address, input []byte, gas uint64, value *uint256.Int) (uint64, error) {
+	if evm.readOnly {
+		return gas, ErrWriteProtection
+	}
+	cost := evm.arkivCallGas(input)
+	if gas < cost {
+		return 0, ErrOutOfGas
+	}
+	if t := evm.Config.Tracer; t != nil && t.OnGasChange != nil {
+		t.OnGasChange(gas, gas-cost, tracing.GasChangeCallPrecompiledContract)
+	}
+	gas -= cost
+
+	callIndex := common.Hash(uint256.NewInt(evm.arkivCalls).Bytes32())
+	evm.arkivCalls++
+	txHash := ArkivCallHash(evm.TxContext.TxHash, callIndex)
+
+	// The transaction index is not used by the execution, same as for the top-level path
+	logs, err := storagetx.ExecuteArkivTransaction(input, evm.chainRules, evm.Context.BlockNumber.Uint64(), txHash, 0, caller, value, evm.StateDB)
+	if err != nil {
+		return gas, ErrExecutionReverted
+	}
+
+	// The marker log carries the call data, the index and the value of the
+	// call, so that indexers can decode the operations that are not visible in
+	// the transaction itself.
+	callerHash := common.Hash{}
+	copy(callerHash[12:], caller[:])
+	evm.StateDB.AddLog(&types.Log{
+		Address:     address.ArkivProcessorAddress,
+		Topics:      []common.Hash{arkivlogs.ArkivContractCall, callerHash, callIndex, value.Bytes32()},
+		Data:        common.CopyBytes(input),
+		BlockNumber: evm.Context.BlockNumber.Uint64(),
+	})
+
+	for _, log := range logs {
+		evm.StateDB.AddLog(log)
+	}
+
+	return gas, nil
+}
@@ -0,0 +1,139 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func packArkivTransaction(t *testing.T, tx *storagetx.ArkivTransaction) []byte {
+	t.Helper()
+	encoded, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+	return compression.MustBrotliCompress(encoded)
+}
+
+func TestCallArkivProcessor(t *testing.T) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	statedb.SetTxContext(common.HexToHash("0x01"), 0)
+
+	config := *params.OptimismTestConfig
+	fork := uint64(10)
+	config.ArkivContractCallsTime = &fork
+	config.ArkivRenewalTime = &fork
+	blockContext := BlockContext{
+		BlockNumber: big.NewInt(10),
+		Time:        9,
+		Random:      &common.Hash{},
+		CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+	}
+
+	contract := common.HexToAddress("0xc0ffee")
+
+	input := packArkivTransaction(t, &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			{BTL: 100, ContentType: "text/plain", Payload: []byte("hello")},
+			{BTL: 100, ContentType: "text/plain", Payload: []byte("hello")},
+		},
+	})
+
+	// before the fork, the processor is an empty account
+	evm := NewEVM(blockContext, statedb, &config, Config{})
+	evm.SetTxContext(TxContext{TxHash: common.HexToHash("0x01")})
+	_, leftOverGas, err := evm.Call(contract, address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.NoError(t, err)
+	require.Equal(t, uint64(100000), leftOverGas)
+	require.Empty(t, statedb.Logs())
+
+	blockContext.Time = 10
+	evm = NewEVM(blockContext, statedb, &config, Config{})
+	evm.SetTxContext(TxContext{TxHash: common.HexToHash("0x01")})
+
+	cost := evm.arkivCallGas(input)
+	require.Greater(t, cost, params.TxGas)
+
+	_, leftOverGas, err = evm.Call(contract, address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.NoError(t, err)
+	require.Equal(t, 100000-cost, leftOverGas)
+
+	// a second call in the same transaction must not collide with the first one
+	_, _, err = evm.Call(contract, address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.NoError(t, err)
+
+	logs := statedb.Logs()
+	require.Len(t, logs, 6)
+	require.Equal(t, arkivlogs.ArkivContractCall, logs[0].Topics[0])
+	require.Equal(t, contract, common.BytesToAddress(logs[0].Topics[1].Bytes()))
+	require.Equal(t, common.Hash{}, logs[0].Topics[2])
+	require.Equal(t, common.Hash{}, logs[0].Topics[3])
+	require.Equal(t, input, logs[0].Data)
+	require.Equal(t, common.BigToHash(big.NewInt(1)), logs[3].Topics[2])
+
+	keys := map[common.Hash]bool{}
+	for _, log := range logs {
+		if log.Topics[0] != arkivlogs.ArkivEntityCreated {
+			continue
+		}
+		keys[log.Topics[1]] = true
+
+		md, err := entity.GetEntityMetaData(statedb, log.Topics[1])
+		require.NoError(t, err)
+		require.Equal(t, contract, md.Owner)
+		require.Equal(t, uint64(110), md.ExpiresAtBlock)
+	}
+	require.Len(t, keys, 4)
+
+	// a call without enough gas for the operations fails without executing them
+	_, leftOverGas, err = evm.Call(contract, address.ArkivProcessorAddress, input, cost-1, new(uint256.Int))
+	require.ErrorIs(t, err, ErrOutOfGas)
+	require.Zero(t, leftOverGas)
+	require.Len(t, statedb.Logs(), 6)
+
+	// deleting an entity owned by someone else reverts without consuming the remaining gas
+	input = packArkivTransaction(t, &storagetx.ArkivTransaction{
+		Delete: []common.Hash{logs[1].Topics[1]},
+	})
+	_, leftOverGas, err = evm.Call(common.HexToAddress("0xbad"), address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.ErrorIs(t, err, ErrExecutionReverted)
+	require.Equal(t, 100000-evm.arkivCallGas(input), leftOverGas)
+	require.Len(t, statedb.Logs(), 6)
+
+	// the value of the call pays for the renewal deposits, and the marker log
+	// carries it along with the index of the call, which counts the failed one
+	renewal := entity.EntityRenewal{Period: 10}
+	input = packArkivTransaction(t, &storagetx.ArkivTransaction{
+		Renew: []storagetx.ArkivRenew{{EntityKey: logs[1].Topics[1], Period: 10, Deposit: renewal.Fee()}},
+	})
+	_, _, err = evm.Call(contract, address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.ErrorIs(t, err, ErrExecutionReverted)
+	_, _, err = evm.Call(contract, address.ArkivProcessorAddress, input, 100000, renewal.Fee())
+	require.NoError(t, err)
+	logs = statedb.Logs()
+	require.Len(t, logs, 8)
+	require.Equal(t, arkivlogs.ArkivContractCall, logs[6].Topics[0])
+	require.Equal(t, common.BigToHash(big.NewInt(4)), logs[6].Topics[2])
+	require.Equal(t, common.Hash(renewal.Fee().Bytes32()), logs[6].Topics[3])
+	require.Equal(t, arkivlogs.ArkivEntityRenewalSet, logs[7].Topics[0])
+
+	// the other kinds of calls are rejected
+	_, _, err = evm.DelegateCall(contract, contract, address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.ErrorIs(t, err, ErrArkivProcessorCallKind)
+	_, _, err = evm.CallCode(contract, address.ArkivProcessorAddress, input, 100000, new(uint256.Int))
+	require.ErrorIs(t, err, ErrArkivProcessorCallKind)
+	_, _, err = evm.StaticCall(contract, address.ArkivProcessorAddress, input, 100000)
+	require.ErrorIs(t, err, ErrArkivProcessorCallKind)
+	require.Len(t, statedb.Logs(), 8)
+}
@@ -2,56 +2,106 @@ package eth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum/arkiv/snapshot"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	queryTimer       = metrics.NewRegisteredTimer("arkiv/api/query/time", nil)
+	queryErrorsMeter = metrics.NewRegisteredMeter("arkiv/api/query/errors", nil)
+)
+
+const (
+	// arkivMaxSnapshots is the number of pinned queries and streams that can be
+	// in progress at the same time. Each of them holds a connection of the
+	// store's read pool.
+	arkivMaxSnapshots = 4
+	// arkivSnapshotIdleTimeout is how long a pinned query stays valid without
+	// reading its next page.
+	arkivSnapshotIdleTimeout = time.Minute
+	// arkivSnapshotMaxLifetime is how long a pinned query stays valid, however
+	// often its pages are read.
+	arkivSnapshotMaxLifetime = 10 * time.Minute
 )
 
 type arkivAPI struct {
-	eth   *Ethereum
-	store *sqlitestore.SQLiteStore
+	eth       *Ethereum
+	store     *sqlitestore.SQLiteStore
+	snapshots *snapshot.Manager
 }
 
 func NewArkivAPI(eth *Ethereum, store *sqlitestore.SQLiteStore) (*arkivAPI, error) {
 	return &arkivAPI{
-		eth:   eth,
-		store: store,
+		eth:       eth,
+		store:     store,
+		snapshots: snapshot.NewManager(store, arkivMaxSnapshots, arkivSnapshotIdleTimeout, arkivSnapshotMaxLifetime),
 	}, nil
 }
 
 func (api *arkivAPI) Query(
 	ctx context.Context,
 	req string,
-	op *sqlitestore.Options,
-) (*sqlitestore.QueryResponse, error) {
+	op *ArkivQueryOptions,
+) (_ *sqlitestore.QueryResponse, err error) {
+
+	defer func(start time.Time) {
+		queryTimer.UpdateSince(start)
+		if err != nil {
+			queryErrorsMeter.Mark(1)
+		}
+	}(time.Now())
 
 	lastBlock := api.eth.blockchain.CurrentHeader().Number.Uint64()
 
 	log.Info("api", "last_block", lastBlock)
 
 	if op == nil {
-		op = &sqlitestore.Options{}
+		op = &ArkivQueryOptions{}
 	}
 	if op.AtBlock == nil {
 		op.AtBlock = &lastBlock
 	}
 
-	response, err := api.store.QueryEntities(ctx, req, op)
+	if op.MinBlock != nil {
+		if err := api.waitForBlock(ctx, *op.MinBlock, op.WaitTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	if op.Pinned || snapshot.IsCursor(op.Cursor) {
+		if op.Pending {
+			return nil, errors.New("pinned queries are not supported at the pending block")
+		}
+		return api.snapshots.Query(ctx, req, &op.Options)
+	}
+
+	if op.Pending {
+		return api.queryPending(ctx, req, op)
+	}
+
+	storeBlock, _ := api.eth.arkivSync.Status()
+
+	response, err := api.store.QueryEntities(ctx, req, &op.Options)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err)
 	}
+	response.BlockNumber = hexutil.Uint64(storeBlock)
 
 	return response, nil
 }
 
 // GetEntityCount returns the total number of entities in the storage.
 func (api *arkivAPI) GetEntityCount(ctx context.Context) (uint64, error) {
-
-	return 0, nil
+	return api.eth.ArkivEntityCount(ctx)
 }
 
 func (api *arkivAPI) GetNumberOfUsedSlots() (*hexutil.Big, error) {
@@ -0,0 +1,267 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	arkivaddress "github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity/entityexpiration"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxArkivExpirationRange caps the number of blocks an expiration schedule or
+// an expiration warning looks ahead, as every block is a separate lookup.
+const maxArkivExpirationRange = 10_000
+
+// arkivExpirationChanges are the events of the entities whose expiration or
+// owner an Arkiv transaction or housekeeping set.
+var arkivExpirationChanges = map[common.Hash]bool{
+	arkivlogs.ArkivEntityCreated:      true,
+	arkivlogs.ArkivEntityUpdated:      true,
+	arkivlogs.ArkivEntityBTLExtended:  true,
+	arkivlogs.ArkivEntityOwnerChanged: true,
+	arkivlogs.ArkivEntityRenewalSet:   true,
+	arkivlogs.ArkivEntityRenewed:      true,
+}
+
+// ArkivExpiringBlock is a block at which entities expire.
+type ArkivExpiringBlock struct {
+	Block uint64        `json:"block"`
+	Count uint64        `json:"count"`
+	Keys  []common.Hash `json:"keys,omitempty"`
+}
+
+// ArkivExpirationSchedule lists the blocks at which entities expire, as of the
+// head block. Blocks without expiring entities are left out.
+type ArkivExpirationSchedule struct {
+	HeadBlock uint64               `json:"headBlock"`
+	Blocks    []ArkivExpiringBlock `json:"blocks"`
+}
+
+// ArkivExpirationWarning notifies the owner of an entity that it is about to
+// expire. If the entity renews, housekeeping extends it instead of deleting it.
+type ArkivExpirationWarning struct {
+	Key            common.Hash    `json:"key"`
+	Owner          common.Address `json:"owner"`
+	ExpiresAtBlock uint64         `json:"expiresAtBlock"`
+	HeadBlock      uint64         `json:"headBlock"`
+	Renews         bool           `json:"renews"`
+}
+
+// GetExpirationSchedule returns the entities expiring between fromBlock and
+// toBlock, inclusive, according to the state of the head block. Entities can
+// only expire after the head block, so the range starts after it at the
+// earliest. The entities can be restricted to those of owner, and their keys
+// are only returned if includeKeys is set.
+func (api *arkivAPI) GetExpirationSchedule(fromBlock, toBlock uint64, owner *common.Address, includeKeys *bool) (*ArkivExpirationSchedule, error) {
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("fromBlock %d is after toBlock %d", fromBlock, toBlock)
+	}
+	if toBlock-fromBlock >= maxArkivExpirationRange {
+		return nil, fmt.Errorf("block range exceeds the maximum of %d blocks", maxArkivExpirationRange)
+	}
+
+	header := api.eth.blockchain.CurrentBlock()
+	stateDB, err := api.eth.BlockChain().StateAt(header.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	schedule := &ArkivExpirationSchedule{
+		HeadBlock: header.Number.Uint64(),
+		Blocks:    []ArkivExpiringBlock{},
+	}
+	keys := includeKeys != nil && *includeKeys
+	for block := max(fromBlock, schedule.HeadBlock+1); block <= toBlock; block++ {
+		expiring := ArkivExpiringBlock{Block: block}
+		if owner == nil && !keys {
+			expiring.Count = entityexpiration.NumberOfEntitiesToExpireAtBlock(stateDB, block)
+		} else {
+			for key := range entityexpiration.IteratorOfEntitiesToExpireAtBlock(stateDB, block) {
+				if owner != nil {
+					md, err := entity.GetEntityMetaData(stateDB, key)
+					if err != nil {
+						return nil, fmt.Errorf("failed to get entity meta data for %s: %w", key.Hex(), err)
+					}
+					if md.Owner != *owner {
+						continue
+					}
+				}
+				expiring.Count++
+				if keys {
+					expiring.Keys = append(expiring.Keys, key)
+				}
+			}
+		}
+		if expiring.Count > 0 {
+			schedule.Blocks = append(schedule.Blocks, expiring)
+		}
+	}
+	return schedule, nil
+}
+
+// ExpirationWarnings notifies owner of each of their entities once it expires
+// within blocksAhead blocks of the chain head. Entities that are already that
+// close to expiring when the subscription is created are notified right away,
+// as are entities that a new block creates or changes to expire that close.
+// An entity that is extended past the window is notified again when its new
+// expiration comes close.
+func (api *arkivAPI) ExpirationWarnings(ctx context.Context, owner common.Address, blocksAhead uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if blocksAhead == 0 || blocksAhead > maxArkivExpirationRange {
+		return nil, fmt.Errorf("blocksAhead must be between 1 and %d", maxArkivExpirationRange)
+	}
+
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	headSub := api.eth.blockchain.SubscribeChainHeadEvent(headCh)
+	sub := notifier.CreateSubscription()
+
+	warned := make(arkivWarnedEntities)
+
+	// warnKey notifies the owner of the entity if it expires within the window
+	// of the given head block, according to its state.
+	warnKey := func(head uint64, stateDB storageutil.StateAccess, key common.Hash) error {
+		md, err := entity.GetEntityMetaData(stateDB, key)
+		if err != nil {
+			return fmt.Errorf("failed to get entity meta data for %s: %w", key.Hex(), err)
+		}
+		if md.Owner != owner || md.ExpiresAtBlock <= head || md.ExpiresAtBlock > head+blocksAhead {
+			return nil
+		}
+		if !warned.add(key, md.ExpiresAtBlock) {
+			return nil
+		}
+		return notifier.Notify(sub.ID, &ArkivExpirationWarning{
+			Key:            key,
+			Owner:          md.Owner,
+			ExpiresAtBlock: md.ExpiresAtBlock,
+			HeadBlock:      head,
+			Renews:         entity.GetEntityRenewal(stateDB, key).Renews(),
+		})
+	}
+
+	// warn notifies the owner of their entities expiring between fromBlock and
+	// toBlock, inclusive, according to the state of the given head block.
+	warn := func(head uint64, stateDB storageutil.StateAccess, fromBlock, toBlock uint64) error {
+		for block := fromBlock; block <= toBlock; block++ {
+			for key := range entityexpiration.IteratorOfEntitiesToExpireAtBlock(stateDB, block) {
+				if err := warnKey(head, stateDB, key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	// warnChanged notifies the owner of their entities that the blocks after
+	// fromBlock up to the head block created or changed to expire within the
+	// window, as those that were already in the window aren't scanned again.
+	warnChanged := func(head uint64, stateDB storageutil.StateAccess, fromBlock uint64) error {
+		for number := fromBlock + 1; number <= head; number++ {
+			hash := api.eth.blockchain.GetCanonicalHash(number)
+			for _, receipt := range api.eth.blockchain.GetReceiptsByHash(hash) {
+				for _, l := range receipt.Logs {
+					if l.Address != arkivaddress.ArkivProcessorAddress || len(l.Topics) < 2 || !arkivExpirationChanges[l.Topics[0]] {
+						continue
+					}
+					if err := warnKey(head, stateDB, l.Topics[1]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	header := api.eth.blockchain.CurrentBlock()
+	stateDB, err := api.eth.BlockChain().StateAt(header.Root)
+	if err != nil {
+		headSub.Unsubscribe()
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+	last := header.Number.Uint64()
+
+	go func() {
+		defer headSub.Unsubscribe()
+
+		if err := warn(last, stateDB, last+1, last+blocksAhead); err != nil {
+			log.Warn("Arkiv expiration warnings failed", "owner", owner, "error", err)
+			return
+		}
+		for {
+			select {
+			case ev := <-headCh:
+				head := ev.Header.Number.Uint64()
+				if head <= last {
+					// Either the head was reported before the subscription
+					// started, or the chain was rewound. The window of the last
+					// head has been warned about already in both cases.
+					continue
+				}
+				stateDB, err := api.eth.BlockChain().StateAt(ev.Header.Root)
+				if err != nil {
+					log.Warn("Arkiv expiration warnings failed", "owner", owner, "error", err)
+					return
+				}
+				warned.prune(head)
+				// Only the blocks that came into the window since the last head
+				// are scanned, along with the entities that the new blocks
+				// changed, which may expire anywhere in the window, unless the
+				// whole window is new.
+				from := max(last+blocksAhead, head) + 1
+				if err := warn(head, stateDB, from, head+blocksAhead); err != nil {
+					log.Warn("Arkiv expiration warnings failed", "owner", owner, "error", err)
+					return
+				}
+				if head < last+blocksAhead {
+					if err := warnChanged(head, stateDB, last); err != nil {
+						log.Warn("Arkiv expiration warnings failed", "owner", owner, "error", err)
+						return
+					}
+				}
+				last = head
+			case <-headSub.Err():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// arkivWarnedEntities holds the expiration that each entity was warned about,
+// so that an entity is only warned about once per expiration. Entities are
+// dropped once the head passes the expiration they were warned about, so it
+// only holds the entities warned about within the window.
+type arkivWarnedEntities map[common.Hash]uint64
+
+// add records a warning about the entity expiring at the given block, and
+// reports whether the entity wasn't warned about that expiration yet.
+func (w arkivWarnedEntities) add(key common.Hash, expiresAt uint64) bool {
+	if warned, ok := w[key]; ok && warned == expiresAt {
+		return false
+	}
+	w[key] = expiresAt
+	return true
+}
+
+// prune drops the entities whose warned about expiration the head block
+// reached, as they expired, were renewed or were extended since.
+func (w arkivWarnedEntities) prune(head uint64) {
+	for key, expiresAt := range w {
+		if expiresAt <= head {
+			delete(w, key)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArkivWarnedEntities(t *testing.T) {
+	warned := make(arkivWarnedEntities)
+	soon, later := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	require.True(t, warned.add(soon, 5))
+	require.True(t, warned.add(later, 8))
+	require.False(t, warned.add(soon, 5))
+
+	// an extended entity is warned about its new expiration
+	require.True(t, warned.add(later, 9))
+	require.False(t, warned.add(later, 9))
+
+	warned.prune(4)
+	require.Len(t, warned, 2)
+
+	warned.prune(5)
+	require.Equal(t, arkivWarnedEntities{later: 9}, warned)
+
+	// a renewed entity is warned about again
+	require.True(t, warned.add(soon, 15))
+
+	warned.prune(20)
+	require.Empty(t, warned)
+}
@@ -0,0 +1,34 @@
+package eth
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/arkiv/history"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GetEntityHistory returns the revisions of an entity between fromBlock and
+// toBlock, inclusive, in the order they were executed. The range defaults to
+// everything indexed so far.
+func (api *arkivAPI) GetEntityHistory(key common.Hash, fromBlock, toBlock *uint64) ([]history.Revision, error) {
+	indexed, err := api.eth.arkivHistory.LastBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last indexed block: %w", err)
+	}
+
+	from, to := uint64(0), indexed
+	if fromBlock != nil {
+		from = *fromBlock
+	}
+	if toBlock != nil {
+		if *toBlock > indexed {
+			return nil, fmt.Errorf("history is only indexed up to block %d", indexed)
+		}
+		to = *toBlock
+	}
+	if from > to {
+		return nil, fmt.Errorf("fromBlock %d is after toBlock %d", from, to)
+	}
+
+	return api.eth.arkivHistory.Revisions(key, from, to)
+}
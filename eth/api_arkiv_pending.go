@@ -0,0 +1,269 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/Arkiv-Network/sqlite-bitmap-store/query"
+	"github.com/ethereum/go-ethereum/arkiv/pending"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	arkivaddress "github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ArkivQueryOptions are the options of arkiv_query.
+// Besides a block number, atBlock accepts the "latest" and "pending" tags.
+// With "pending", the operations of the transactions pending in the pool are
+// applied on top of the latest state, optionally only those sent by pendingFrom,
+// and the affected results are flagged as unconfirmed.
+// With minBlock, the query waits until the store has ingested that block, for at
+// most waitTimeout milliseconds.
+// With pinned, all pages of the query are read at the block of the first page.
+type ArkivQueryOptions struct {
+	sqlitestore.Options
+
+	Pending     bool            `json:"-"`
+	PendingFrom *common.Address `json:"pendingFrom,omitempty"`
+	MinBlock    *uint64         `json:"minBlock,omitempty"`
+	WaitTimeout *uint64         `json:"waitTimeout,omitempty"`
+	Pinned      bool            `json:"pinned,omitempty"`
+}
+
+func (o *ArkivQueryOptions) UnmarshalJSON(data []byte) error {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*o = ArkivQueryOptions{}
+
+	for name, field := range map[string]any{
+		"pendingFrom": &o.PendingFrom,
+		"minBlock":    &o.MinBlock,
+		"waitTimeout": &o.WaitTimeout,
+		"pinned":      &o.Pinned,
+	} {
+		if raw, ok := fields[name]; ok {
+			if err := json.Unmarshal(raw, field); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			delete(fields, name)
+		}
+	}
+
+	if raw, ok := fields["atBlock"]; ok && bytes.HasPrefix(bytes.TrimSpace(raw), []byte(`"`)) {
+		var tag string
+		if err := json.Unmarshal(raw, &tag); err != nil {
+			return err
+		}
+		switch tag {
+		case "latest":
+		case "pending":
+			o.Pending = true
+		default:
+			return fmt.Errorf("invalid atBlock tag %q, expected a block number, latest or pending", tag)
+		}
+		delete(fields, "atBlock")
+	}
+
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(rest, &o.Options)
+}
+
+// pendingCursorPrefix marks the cursors of pending queries whose next page
+// starts within the pending results. The cursors of the store are hex numbers,
+// and those of snapshots contain a colon.
+const pendingCursorPrefix = "pending-"
+
+// queryPending answers a query at the pending block: results of the store that
+// are modified or deleted by pending transactions are replaced by their pending
+// version, flagged as unconfirmed. The pending results come first, followed by
+// the remaining results of the store, and are paginated along with them.
+func (api *arkivAPI) queryPending(ctx context.Context, req string, op *ArkivQueryOptions) (*sqlitestore.QueryResponse, error) {
+	q, err := query.Parse(req)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing query: %w", err)
+	}
+
+	head := *op.AtBlock
+	overlay := pending.NewOverlay(head, func(key common.Hash) (*pending.Entity, error) {
+		return api.lookupEntity(ctx, key, head)
+	})
+	api.applyPendingTransactions(overlay, op.PendingFrom)
+
+	include := op.GetIncludeData()
+	perPage := op.GetResultsPerPage()
+
+	matched := []*pending.Entity{}
+	for _, e := range overlay.Entities() {
+		if pending.Match(q, e) {
+			matched = append(matched, e)
+		}
+	}
+
+	// The results of the store that pending transactions touched are replaced,
+	// whether they are on this page or not
+	replaced := uint64(0)
+	for _, key := range overlay.Keys() {
+		confirmed, err := api.lookupEntity(ctx, key, head)
+		if err != nil {
+			return nil, err
+		}
+		if confirmed != nil && pending.Match(q, confirmed) {
+			replaced++
+		}
+	}
+
+	// The page starts within the pending results unless the cursor is one of
+	// the store
+	offset := uint64(0)
+	storeCursor := ""
+	switch {
+	case op.Cursor == "":
+	case strings.HasPrefix(op.Cursor, pendingCursorPrefix):
+		offset, err = hexutil.DecodeUint64(strings.TrimPrefix(op.Cursor, pendingCursorPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding cursor: %w", err)
+		}
+	default:
+		offset = uint64(len(matched))
+		storeCursor = op.Cursor
+	}
+	page := matched[min(offset, uint64(len(matched))):]
+	page = page[:min(perPage, uint64(len(page)))]
+
+	data := []json.RawMessage{}
+	for _, e := range page {
+		d, err := json.Marshal(pending.QueryResult{EntityData: e.EntityData(include), Unconfirmed: true})
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling entity data: %w", err)
+		}
+		data = append(data, d)
+	}
+
+	// Keys are needed to drop the results that are replaced by the overlay. If
+	// the page is full already, the store is only queried for the count.
+	remaining := perPage - uint64(len(page))
+	storeOp := op.Options
+	storeOp.Cursor = storeCursor
+	storeInclude := include
+	storeInclude.Key = true
+	storeOp.IncludeData = &storeInclude
+	storeOp.ResultsPerPage = &remaining
+	if remaining == 0 {
+		one := uint64(1)
+		storeOp.ResultsPerPage = &one
+		storeOp.IncludeData = &sqlitestore.IncludeData{Key: true}
+	}
+
+	response, err := api.store.QueryEntities(ctx, req, &storeOp)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	count := uint64(response.TotalCount) - min(replaced, uint64(response.TotalCount)) + uint64(len(matched))
+
+	if remaining == 0 {
+		response.Cursor = nil
+		if next := offset + uint64(len(page)); next < count {
+			cursor := pendingCursorPrefix + hexutil.EncodeUint64(next)
+			response.Cursor = &cursor
+		}
+		response.Data = data
+		response.TotalCount = hexutil.Uint64(count)
+		return response, nil
+	}
+
+	for _, raw := range response.Data {
+		ed := &sqlitestore.EntityData{}
+		if err := json.Unmarshal(raw, ed); err != nil {
+			return nil, fmt.Errorf("error decoding entity data: %w", err)
+		}
+		if ed.Key != nil && overlay.Touched(*ed.Key) {
+			continue
+		}
+		if !include.Key {
+			ed.Key = nil
+			raw, err = json.Marshal(ed)
+			if err != nil {
+				return nil, fmt.Errorf("error marshalling entity data: %w", err)
+			}
+		}
+		data = append(data, raw)
+	}
+
+	response.Data = data
+	response.TotalCount = hexutil.Uint64(count)
+
+	return response, nil
+}
+
+// applyPendingTransactions applies the Arkiv transactions pending in the pool to
+// overlay, or only those sent by from if it is set.
+func (api *arkivAPI) applyPendingTransactions(overlay *pending.Overlay, from *common.Address) {
+	all := api.eth.txPool.Pending(txpool.PendingFilter{OnlyPlainTxs: true})
+
+	senders := make([]common.Address, 0, len(all))
+	for sender := range all {
+		if from == nil || sender == *from {
+			senders = append(senders, sender)
+		}
+	}
+	slices.SortFunc(senders, func(a, b common.Address) int { return a.Cmp(b) })
+
+	for _, sender := range senders {
+		// The pool hands out the payloads it decoded when the transactions
+		// were added, so they are not decompressed again for every query
+		for _, ltx := range all[sender] {
+			if ltx.ArkivTx == nil {
+				continue
+			}
+			if err := overlay.Apply(ltx.Hash, sender, ltx.ArkivTx); err != nil {
+				log.Debug("Skipping pending arkiv transaction", "hash", ltx.Hash, "err", err)
+			}
+		}
+	}
+}
+
+// lookupEntity returns the entity with the given key from the store, or nil if
+// it does not exist.
+func (api *arkivAPI) lookupEntity(ctx context.Context, key common.Hash, atBlock uint64) (*pending.Entity, error) {
+	response, err := api.store.QueryEntities(ctx, fmt.Sprintf("$key = %s", key.Hex()), &sqlitestore.Options{
+		AtBlock: &atBlock,
+		IncludeData: &sqlitestore.IncludeData{
+			Key:                 true,
+			Attributes:          true,
+			SyntheticAttributes: true,
+			Payload:             true,
+			ContentType:         true,
+			Owner:               true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error looking up entity %s: %w", key.Hex(), err)
+	}
+	if len(response.Data) == 0 {
+		return nil, nil
+	}
+
+	ed := &sqlitestore.EntityData{}
+	if err := json.Unmarshal(response.Data[0], ed); err != nil {
+		return nil, fmt.Errorf("error decoding entity %s: %w", key.Hex(), err)
+	}
+	return pending.FromEntityData(ed), nil
+}
+
+func isArkivTransaction(tx *types.Transaction) bool {
+	to := tx.To()
+	return to != nil && *to == arkivaddress.ArkivProcessorAddress
+}
@@ -0,0 +1,304 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	arkivaddress "github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/housekeepingtx"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+// ArkivSimulationArgs describes the Arkiv transaction to simulate, either as a
+// signed raw transaction, or as the sender, the compressed transaction data and
+// the value paying for the renewal deposits. Entity keys depend on the
+// transaction hash, so the keys predicted for an unsigned transaction are not
+// the keys the entities will get.
+type ArkivSimulationArgs struct {
+	Raw   hexutil.Bytes   `json:"raw,omitempty"`
+	From  *common.Address `json:"from,omitempty"`
+	Data  hexutil.Bytes   `json:"data,omitempty"`
+	Value *hexutil.Big    `json:"value,omitempty"`
+}
+
+// ArkivOperationResult is the outcome of a single operation of a simulated transaction.
+type ArkivOperationResult struct {
+	Type  string      `json:"type"`
+	Index int         `json:"index"`
+	Key   common.Hash `json:"key"`
+	Error string      `json:"error,omitempty"`
+}
+
+// ArkivSimulationResult is the outcome of a simulated Arkiv transaction.
+type ArkivSimulationResult struct {
+	Success         bool                   `json:"success"`
+	Error           string                 `json:"error,omitempty"`
+	Operations      []ArkivOperationResult `json:"operations"`
+	CreatedEntities []common.Hash          `json:"createdEntities"`
+	KeysFinal       bool                   `json:"keysFinal"`
+	Logs            []*types.Log           `json:"logs"`
+	UsedSlotsDelta  *hexutil.Big           `json:"usedSlotsDelta"`
+	Gas             hexutil.Uint64         `json:"gas"`
+	Cost            *hexutil.Big           `json:"cost"`
+}
+
+// SimulateTransaction executes an Arkiv transaction against a copy of the state
+// at the given block, as if it was included in the following block, without
+// broadcasting it. The housekeeping of the following block runs first, so
+// entities expiring in it are gone. Besides the outcome of the whole transaction, it reports the
+// outcome of every operation, so that failures can be told apart.
+func (api *arkivAPI) SimulateTransaction(ctx context.Context, args ArkivSimulationArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*ArkivSimulationResult, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+
+	statedb, header, err := api.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if statedb == nil || header == nil {
+		return nil, errors.New("block not found")
+	}
+
+	var (
+		sender    common.Address
+		data      []byte
+		value     = new(uint256.Int)
+		txHash    common.Hash
+		keysFinal bool
+	)
+	switch {
+	case len(args.Raw) > 0:
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(args.Raw); err != nil {
+			return nil, fmt.Errorf("invalid raw transaction: %w", err)
+		}
+		if !isArkivTransaction(tx) {
+			return nil, errors.New("not an arkiv transaction")
+		}
+		sender, err = types.Sender(types.LatestSigner(api.eth.blockchain.Config()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sender: %w", err)
+		}
+		data, txHash, keysFinal = tx.Data(), tx.Hash(), true
+		value = uint256.MustFromBig(tx.Value())
+	case args.From != nil:
+		sender, data = *args.From, args.Data
+		if args.Value != nil {
+			var overflow bool
+			if value, overflow = uint256.FromBig(args.Value.ToInt()); overflow {
+				return nil, errors.New("value overflows 256 bits")
+			}
+		}
+	default:
+		return nil, errors.New("either raw or from and data must be set")
+	}
+
+	blockNumber, blockTime := header.Number.Uint64()+1, api.nextBlockTime(header)
+	rules := api.eth.blockchain.Config().Rules(new(big.Int).SetUint64(blockNumber), true, blockTime)
+
+	atx, _, err := storagetx.UnpackArkivTransactionForRules(data, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := housekeepingtx.ExecuteTransaction(rules, blockNumber, common.Hash{}, statedb); err != nil {
+		return nil, fmt.Errorf("failed to execute housekeeping of block %d: %w", blockNumber, err)
+	}
+
+	gas, cost, err := api.arkivTransactionCost(statedb, rules, header.BaseFee, blockTime, data)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ArkivSimulationResult{
+		Operations:      []ArkivOperationResult{},
+		CreatedEntities: []common.Hash{},
+		KeysFinal:       keysFinal,
+		Logs:            []*types.Log{},
+		UsedSlotsDelta:  (*hexutil.Big)(new(big.Int)),
+		Gas:             hexutil.Uint64(gas),
+		Cost:            (*hexutil.Big)(cost),
+	}
+
+	for opIx, create := range atx.Create {
+		res.CreatedEntities = append(res.CreatedEntities, storagetx.EntityKey(txHash, create.Payload, opIx))
+	}
+
+	if err := atx.Validate(); err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+	if err := transferToProcessor(statedb, sender, value); err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+
+	snapshot := statedb.Snapshot()
+	res.Operations = simulateOperations(statedb, atx, blockNumber, txHash, sender, value)
+	statedb.RevertToSnapshot(snapshot)
+
+	usedSlotsBefore := storageaccounting.GetNumberOfUsedSlots(statedb).ToBig()
+
+	logs, err := storagetx.ExecuteArkivTransaction(data, rules, blockNumber, txHash, 0, sender, value, statedb)
+	if err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+
+	usedSlotsAfter := storageaccounting.GetNumberOfUsedSlots(statedb).ToBig()
+
+	res.Success = true
+	res.Logs = logs
+	res.UsedSlotsDelta = (*hexutil.Big)(new(big.Int).Sub(usedSlotsAfter, usedSlotsBefore))
+
+	return res, nil
+}
+
+// transferToProcessor transfers the value of the transaction, which pays for
+// the renewal deposits, to the processor, as the state transition does before
+// executing the transaction.
+func transferToProcessor(statedb *state.StateDB, sender common.Address, value *uint256.Int) error {
+	if value.IsZero() {
+		return nil
+	}
+	if !core.CanTransfer(statedb, sender, value) {
+		return fmt.Errorf("%w: address %v", core.ErrInsufficientFundsForTransfer, sender.Hex())
+	}
+	core.Transfer(statedb, sender, arkivaddress.ArkivProcessorAddress, value)
+	return nil
+}
+
+// simulateOperations executes the operations of atx one by one, in the order of
+// storagetx.ArkivTransaction.Run, and reports the outcome of each one. A failed
+// operation doesn't modify the state, so the following ones are still attempted.
+// Creates are executed together, as their keys depend on their index. Each
+// renewal may pay its deposit out of the whole value.
+func simulateOperations(statedb *state.StateDB, atx *storagetx.ArkivTransaction, blockNumber uint64, txHash common.Hash, sender common.Address, value *uint256.Int) []ArkivOperationResult {
+	results := []ArkivOperationResult{}
+
+	run := func(op *storagetx.ArkivTransaction) string {
+		snapshot := statedb.Snapshot()
+		counter := storageaccounting.NewSlotUsageCounter(statedb)
+		if _, err := op.Run(blockNumber, txHash, 0, sender, value, counter); err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			return err.Error()
+		}
+		counter.UpdateUsedSlotsForGolemBase()
+		return ""
+	}
+
+	if len(atx.Create) > 0 {
+		failure := run(&storagetx.ArkivTransaction{Create: atx.Create})
+		for opIx, create := range atx.Create {
+			results = append(results, ArkivOperationResult{
+				Type:  "create",
+				Index: opIx,
+				Key:   storagetx.EntityKey(txHash, create.Payload, opIx),
+				Error: failure,
+			})
+		}
+	}
+
+	for i, key := range atx.Delete {
+		results = append(results, ArkivOperationResult{
+			Type:  "delete",
+			Index: i,
+			Key:   key,
+			Error: run(&storagetx.ArkivTransaction{Delete: []common.Hash{key}}),
+		})
+	}
+
+	for i, update := range atx.Update {
+		results = append(results, ArkivOperationResult{
+			Type:  "update",
+			Index: i,
+			Key:   update.EntityKey,
+			Error: run(&storagetx.ArkivTransaction{Update: []storagetx.ArkivUpdate{update}}),
+		})
+	}
+
+	for i, extend := range atx.Extend {
+		results = append(results, ArkivOperationResult{
+			Type:  "extend",
+			Index: i,
+			Key:   extend.EntityKey,
+			Error: run(&storagetx.ArkivTransaction{Extend: []storagetx.ExtendBTL{extend}}),
+		})
+	}
+
+	for i, changeOwner := range atx.ChangeOwner {
+		results = append(results, ArkivOperationResult{
+			Type:  "changeOwner",
+			Index: i,
+			Key:   changeOwner.EntityKey,
+			Error: run(&storagetx.ArkivTransaction{ChangeOwner: []storagetx.ArkivChangeOwner{changeOwner}}),
+		})
+	}
+
+	for i, renew := range atx.Renew {
+		results = append(results, ArkivOperationResult{
+			Type:  "renew",
+			Index: i,
+			Key:   renew.EntityKey,
+			Error: run(&storagetx.ArkivTransaction{Renew: []storagetx.ArkivRenew{renew}}),
+		})
+	}
+
+	return results
+}
+
+// nextBlockTime returns the timestamp of the block following header, which is
+// produced after the same interval as header itself.
+func (api *arkivAPI) nextBlockTime(header *types.Header) uint64 {
+	blockTime := uint64(1)
+	if parent := api.eth.blockchain.GetHeaderByHash(header.ParentHash); parent != nil && header.Time > parent.Time {
+		blockTime = header.Time - parent.Time
+	}
+	return header.Time + blockTime
+}
+
+// arkivTransactionCost returns the gas an Arkiv transaction with the given data
+// uses, which is only the intrinsic gas, and its cost at baseFee including the
+// L1 data fee, in a block with the given rules and timestamp.
+func (api *arkivAPI) arkivTransactionCost(statedb *state.StateDB, rules params.Rules, baseFee *big.Int, time uint64, data []byte) (uint64, *big.Int, error) {
+	gas, err := core.IntrinsicGas(data, nil, nil, false, rules.IsHomestead, rules.IsIstanbul, rules.IsShanghai)
+	if err != nil {
+		return 0, nil, err
+	}
+	if rules.IsPrague {
+		floor, err := core.FloorDataGas(data)
+		if err != nil {
+			return 0, nil, err
+		}
+		gas = max(gas, floor)
+	}
+
+	cost := new(big.Int).SetUint64(gas)
+	if baseFee != nil {
+		cost.Mul(cost, baseFee)
+	} else {
+		cost.SetUint64(0)
+	}
+
+	if l1CostFn := types.NewL1CostFunc(api.eth.blockchain.Config(), statedb); l1CostFn != nil {
+		tx := types.NewTx(&types.DynamicFeeTx{Data: data})
+		if l1Cost := l1CostFn(tx.RollupCostData(), time); l1Cost != nil {
+			cost.Add(cost, l1Cost)
+		}
+	}
+
+	return gas, cost, nil
+}
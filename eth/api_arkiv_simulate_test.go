@@ -0,0 +1,58 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateArkivOperations(t *testing.T) {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x1111")
+	other := common.HexToAddress("0x2222")
+	createHash := common.HexToHash("0x01")
+
+	create := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 10, ContentType: "text/plain", Payload: []byte("hello")}},
+	}
+	_, err = create.Run(1, createHash, 0, owner, nil, storageaccounting.NewSlotUsageCounter(statedb))
+	require.NoError(t, err)
+	key := storagetx.EntityKey(createHash, []byte("hello"), 0)
+
+	missing := common.HexToHash("0xdead")
+	atx := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 10, ContentType: "text/plain", Payload: []byte("new")}},
+		Delete: []common.Hash{missing},
+		Update: []storagetx.ArkivUpdate{{EntityKey: key, BTL: 10, ContentType: "text/plain", Payload: []byte("updated")}},
+		Extend: []storagetx.ExtendBTL{{EntityKey: key, NumberOfBlocks: 5}},
+		Renew:  []storagetx.ArkivRenew{{EntityKey: key, Period: 5}},
+	}
+
+	txHash := common.HexToHash("0x02")
+	results := simulateOperations(statedb, atx, 2, txHash, other, uint256.NewInt(0))
+	require.Len(t, results, 5)
+
+	require.Equal(t, "create", results[0].Type)
+	require.Equal(t, storagetx.EntityKey(txHash, []byte("new"), 0), results[0].Key)
+	require.Empty(t, results[0].Error)
+
+	require.Equal(t, "delete", results[1].Type)
+	require.NotEmpty(t, results[1].Error)
+
+	require.Equal(t, "update", results[2].Type)
+	require.Contains(t, results[2].Error, "is not the owner")
+
+	require.Equal(t, "extend", results[3].Type)
+	require.Empty(t, results[3].Error)
+
+	require.Equal(t, "renew", results[4].Type)
+	require.Contains(t, results[4].Error, "is not the owner")
+}
@@ -0,0 +1,60 @@
+package eth
+
+import (
+	"context"
+	"errors"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// QueryPages streams all the results of a query as notifications, one page per
+// notification, all read at the last block ingested by the store when the
+// subscription was created. The cursor of the last page is empty.
+func (api *arkivAPI) QueryPages(ctx context.Context, req string, op *ArkivQueryOptions) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	if op == nil {
+		op = &ArkivQueryOptions{}
+	}
+	if op.Pending {
+		return nil, errors.New("streaming is not supported at the pending block")
+	}
+	if op.Cursor != "" {
+		return nil, errors.New("streaming always starts at the first page")
+	}
+	if op.MinBlock != nil {
+		if err := api.waitForBlock(ctx, *op.MinBlock, op.WaitTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	sub := notifier.CreateSubscription()
+
+	done, err := api.snapshots.Stream(streamCtx, req, &op.Options, func(page *sqlitestore.QueryResponse) error {
+		return notifier.Notify(sub.ID, page)
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		defer cancel()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Warn("Arkiv query stream failed", "query", req, "error", err)
+			}
+		case <-sub.Err():
+		}
+	}()
+
+	return sub, nil
+}
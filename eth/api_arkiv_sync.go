@@ -0,0 +1,53 @@
+package eth
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// defaultArkivWaitTimeout is how long a query with minBlock waits for the
+	// store when no waitTimeout is given.
+	defaultArkivWaitTimeout = 5 * time.Second
+	// maxArkivWaitTimeout caps the waitTimeout of a query.
+	maxArkivWaitTimeout = time.Minute
+)
+
+// ArkivSyncStatus reports how far the Arkiv store, which is filled
+// asynchronously, is behind the chain head.
+type ArkivSyncStatus struct {
+	StoreBlock uint64 `json:"storeBlock"`
+	HeadBlock  uint64 `json:"headBlock"`
+	Backlog    uint64 `json:"backlog"`
+	Synced     bool   `json:"synced"`
+}
+
+// SyncStatus returns the last block ingested by the Arkiv store and the chain head.
+func (api *arkivAPI) SyncStatus() *ArkivSyncStatus {
+	storeBlock, _ := api.eth.arkivSync.Status()
+	head := api.eth.blockchain.CurrentHeader().Number.Uint64()
+
+	status := &ArkivSyncStatus{
+		StoreBlock: storeBlock,
+		HeadBlock:  head,
+		Synced:     storeBlock >= head,
+	}
+	if head > storeBlock {
+		status.Backlog = head - storeBlock
+	}
+	return status
+}
+
+// waitForBlock blocks until the store has ingested the given block, for at most
+// timeoutMs milliseconds.
+func (api *arkivAPI) waitForBlock(ctx context.Context, number uint64, timeoutMs *uint64) error {
+	timeout := defaultArkivWaitTimeout
+	if timeoutMs != nil {
+		timeout = time.Duration(min(*timeoutMs, uint64(maxArkivWaitTimeout/time.Millisecond))) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return api.eth.arkivSync.WaitForBlock(ctx, number)
+}
@@ -0,0 +1,33 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+)
+
+// ArkivEntityCount returns the number of live entities in the Arkiv store.
+func (s *Ethereum) ArkivEntityCount(ctx context.Context) (uint64, error) {
+	// Only the total count is needed, so a single key is retrieved
+	resultsPerPage := uint64(1)
+	res, err := s.arkivStore.QueryEntities(ctx, "$all", &sqlitestore.Options{
+		ResultsPerPage: &resultsPerPage,
+		IncludeData:    &sqlitestore.IncludeData{Key: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count entities: %w", err)
+	}
+	return uint64(res.TotalCount), nil
+}
+
+// ArkivEntityCount returns the number of live entities in the Arkiv store.
+func (b *EthAPIBackend) ArkivEntityCount(ctx context.Context) (uint64, error) {
+	return b.eth.ArkivEntityCount(ctx)
+}
+
+// ArkivSyncStatus returns the last block ingested by the Arkiv store and the
+// last chain head.
+func (b *EthAPIBackend) ArkivSyncStatus() (ingested, head uint64) {
+	return b.eth.arkivSync.Status()
+}
@@ -0,0 +1,105 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+var _ miner.BackendWithArkiv = (*Ethereum)(nil)
+
+// arkivQueryConditionTimeout bounds the time the queries of a conditional
+// transaction may take, as they are checked while building a block.
+const arkivQueryConditionTimeout = 100 * time.Millisecond
+
+// CheckArkivQueryConditions validates the query preconditions of a conditional
+// transaction against the Arkiv store. The store only holds the latest state,
+// so it must be at the block with the given number for the whole check,
+// otherwise types.ErrArkivStoreNotReady is returned. The deleted entities,
+// which the store still holds, aren't counted.
+func (s *Ethereum) CheckArkivQueryConditions(ctx context.Context, number uint64, conditions []types.ArkivQueryCondition, deleted []common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, arkivQueryConditionTimeout)
+	defer cancel()
+
+	if err := s.checkArkivStoreAt(ctx, number); err != nil {
+		return err
+	}
+
+	// Only the total count is needed, so a single key is retrieved
+	resultsPerPage := uint64(1)
+	options := &sqlitestore.Options{
+		ResultsPerPage: &resultsPerPage,
+		IncludeData:    &sqlitestore.IncludeData{Key: true},
+	}
+	for _, cond := range conditions {
+		res, err := s.arkivStore.QueryEntities(ctx, cond.Query, options)
+		if err != nil {
+			return fmt.Errorf("failed arkiv query %q constraint: %w", cond.Query, err)
+		}
+		count := uint64(res.TotalCount)
+		if len(deleted) > 0 && count > 0 {
+			res, err := s.arkivStore.QueryEntities(ctx, arkivQueryOfKeys(cond.Query, deleted), options)
+			if err != nil {
+				return fmt.Errorf("failed arkiv query %q constraint: %w", cond.Query, err)
+			}
+			count -= min(count, uint64(res.TotalCount))
+		}
+		if cond.MinResults != nil && count < *cond.MinResults {
+			return fmt.Errorf("failed arkiv query %q constraint. Got %d results, Expected at least %d", cond.Query, count, *cond.MinResults)
+		}
+		if cond.MaxResults != nil && count > *cond.MaxResults {
+			return fmt.Errorf("failed arkiv query %q constraint. Got %d results, Expected at most %d", cond.Query, count, *cond.MaxResults)
+		}
+	}
+	// The store may have ingested another block while it was queried
+	return s.checkArkivStoreAt(ctx, number)
+}
+
+// arkivQueryOfKeys restricts query to the entities with the given keys.
+func arkivQueryOfKeys(query string, keys []common.Hash) string {
+	var b strings.Builder
+	if q := strings.TrimSpace(query); q != "$all" && q != "*" {
+		b.WriteString("(" + q + ") && ")
+	}
+	b.WriteString("$key in (")
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(key.Hex())
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// checkArkivStoreAt returns types.ErrArkivStoreNotReady if the last block that
+// the store committed isn't the block with the given number.
+func (s *Ethereum) checkArkivStoreAt(ctx context.Context, number uint64) error {
+	last, err := s.arkivStore.GetLastBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the last block of the arkiv store: %w", err)
+	}
+	if uint64(last) != number {
+		return fmt.Errorf("%w: block %d, store is at block %d", types.ErrArkivStoreNotReady, number, last)
+	}
+	return nil
+}
+
+// CheckArkivQueryConditions validates the query preconditions of a conditional
+// transaction submitted over RPC, waiting a while for the store to ingest the
+// block with the given number.
+func (b *EthAPIBackend) CheckArkivQueryConditions(ctx context.Context, number uint64, conditions []types.ArkivQueryCondition) error {
+	waitCtx, cancel := context.WithTimeout(ctx, defaultArkivWaitTimeout)
+	defer cancel()
+
+	if err := b.eth.arkivSync.WaitForBlock(waitCtx, number); err != nil {
+		return fmt.Errorf("%w: %w", types.ErrArkivStoreNotReady, err)
+	}
+	return b.eth.CheckArkivQueryConditions(ctx, number, conditions, nil)
+}
@@ -0,0 +1,188 @@
+package eth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/Arkiv-Network/arkiv-events/events"
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	sqlstore "github.com/Arkiv-Network/sqlite-bitmap-store/store"
+	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// arkivStoreSchemaVersion is the version of the SQL store schema that the genesis
+// entities are written for.
+const arkivStoreSchemaVersion = 1
+
+// arkivGenesis returns the Arkiv section of the genesis, either of the given
+// specification or, if the chain has been initialized already, of the stored one.
+func arkivGenesis(chainDb ethdb.Database, genesis *core.Genesis) (*core.GenesisArkiv, error) {
+	if genesis != nil {
+		return genesis.Arkiv, nil
+	}
+	hash := rawdb.ReadCanonicalHash(chainDb, 0)
+	if hash == (common.Hash{}) {
+		return nil, nil
+	}
+	blob := rawdb.ReadArkivGenesisSpec(chainDb, hash)
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	spec := new(core.GenesisArkiv)
+	if err := json.Unmarshal(blob, spec); err != nil {
+		return nil, fmt.Errorf("invalid arkiv genesis: %w", err)
+	}
+	return spec, nil
+}
+
+// seedArkivStore creates the genesis entities in the SQL store, as the operations
+// of block 0. It does nothing if the store holds them already.
+func seedArkivStore(store *sqlitestore.SQLiteStore, path string, spec *core.GenesisArkiv) error {
+	if spec == nil || len(spec.Entities) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+
+	// The entities are written in a single transaction, so the store holds
+	// either all of them or none
+	seeded, err := countGenesisEntities(ctx, store)
+	if err != nil {
+		return err
+	}
+	switch seeded {
+	case uint64(len(spec.Entities)):
+		return nil
+	case 0:
+	default:
+		return fmt.Errorf("store holds %d genesis entities, expected %d", seeded, len(spec.Entities))
+	}
+	// The store skips the blocks up to its last block, which starts at 0, so
+	// it can't ingest block 0 itself. The operations of block 0 are written
+	// through its queries directly instead, the way the store ingests creates,
+	// over a connection opened like its own write connection.
+	db, err := sql.Open("sqlite3", arkivStoreWriteDSN(path))
+	if err != nil {
+		return fmt.Errorf("failed to open SQL state file: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The rows are only written like the store writes them for the schema they
+	// were written against. A store with another schema has to be seeded by the
+	// store itself.
+	var version uint64
+	if err := tx.QueryRowContext(ctx, "SELECT version FROM schema_migrations").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read SQL store schema version: %w", err)
+	}
+	if version != arkivStoreSchemaVersion {
+		return fmt.Errorf("can't seed SQL store with schema version %d, only %d is supported", version, arkivStoreSchemaVersion)
+	}
+
+	st := sqlstore.New(tx)
+	for _, op := range dbevents.GenesisBlock(spec).Operations {
+		if err := storeGenesisEntity(ctx, st, op); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit genesis entities: %w", err)
+	}
+	log.Info("Seeded Arkiv store with genesis entities", "entities", len(spec.Entities))
+	return nil
+}
+
+// arkivStoreWriteDSN returns the data source name of a write connection to the
+// SQL store at path, with the options the store opens its own write connection with.
+func arkivStoreWriteDSN(path string) string {
+	return fmt.Sprintf("file:%s?mode=rwc&_busy_timeout=11000&_journal_mode=WAL&_auto_vacuum=incremental&_foreign_keys=true&_txlock=immediate&_cache_size=65536", path)
+}
+
+// countGenesisEntities returns the number of genesis entities of the store.
+// Seeding happens before the store ingests block 1, so the store holds no other
+// entities, and none of the genesis entities can have been deleted yet.
+func countGenesisEntities(ctx context.Context, store *sqlitestore.SQLiteStore) (uint64, error) {
+	resultsPerPage := uint64(1)
+	res, err := store.QueryEntities(ctx, "$all", &sqlitestore.Options{
+		ResultsPerPage: &resultsPerPage,
+		IncludeData:    &sqlitestore.IncludeData{Key: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count genesis entities: %w", err)
+	}
+	return uint64(res.TotalCount), nil
+}
+
+// storeGenesisEntity writes the create operation of a genesis entity the same
+// way the store writes the create operations of the blocks it follows.
+func storeGenesisEntity(ctx context.Context, st *sqlstore.Queries, op events.Operation) error {
+	create := op.Create
+
+	stringAttributes := maps.Clone(create.StringAttributes)
+	stringAttributes["$owner"] = strings.ToLower(create.Owner.Hex())
+	stringAttributes["$creator"] = strings.ToLower(create.Owner.Hex())
+	stringAttributes["$key"] = strings.ToLower(create.Key.Hex())
+
+	numericAttributes := maps.Clone(create.NumericAttributes)
+	numericAttributes["$expiration"] = create.BTL
+	numericAttributes["$createdAtBlock"] = 0
+	numericAttributes["$lastModifiedAtBlock"] = 0
+	numericAttributes["$sequence"] = op.TxIndex<<16 | op.OpIndex
+	numericAttributes["$txIndex"] = op.TxIndex
+	numericAttributes["$opIndex"] = op.OpIndex
+
+	id, err := st.UpsertPayload(ctx, sqlstore.UpsertPayloadParams{
+		EntityKey:         create.Key.Bytes(),
+		Payload:           create.Content,
+		ContentType:       create.ContentType,
+		StringAttributes:  sqlstore.NewStringAttributes(stringAttributes),
+		NumericAttributes: sqlstore.NewNumericAttributes(numericAttributes),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert genesis entity %s: %w", create.Key.Hex(), err)
+	}
+
+	for name, value := range stringAttributes {
+		bitmap, err := st.GetStringAttributeValueBitmap(ctx, sqlstore.GetStringAttributeValueBitmapParams{Name: name, Value: value})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get string attribute %q bitmap: %w", name, err)
+		}
+		if bitmap == nil {
+			bitmap = sqlstore.NewBitmap()
+		}
+		bitmap.Add(id)
+		err = st.UpsertStringAttributeValueBitmap(ctx, sqlstore.UpsertStringAttributeValueBitmapParams{Name: name, Value: value, Bitmap: bitmap})
+		if err != nil {
+			return fmt.Errorf("failed to upsert string attribute %q bitmap: %w", name, err)
+		}
+	}
+	for name, value := range numericAttributes {
+		bitmap, err := st.GetNumericAttributeValueBitmap(ctx, sqlstore.GetNumericAttributeValueBitmapParams{Name: name, Value: value})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get numeric attribute %q bitmap: %w", name, err)
+		}
+		if bitmap == nil {
+			bitmap = sqlstore.NewBitmap()
+		}
+		bitmap.Add(id)
+		err = st.UpsertNumericAttributeValueBitmap(ctx, sqlstore.UpsertNumericAttributeValueBitmapParams{Name: name, Value: value, Bitmap: bitmap})
+		if err != nil {
+			return fmt.Errorf("failed to upsert numeric attribute %q bitmap: %w", name, err)
+		}
+	}
+	return nil
+}
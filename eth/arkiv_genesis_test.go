@@ -0,0 +1,132 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"testing"
+
+	arkivevents "github.com/Arkiv-Network/arkiv-events"
+	"github.com/Arkiv-Network/arkiv-events/events"
+	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
+	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeedArkivStoreMatchesIngestion checks that the genesis entities are written
+// like the store writes the creates of the blocks it follows, by comparing them
+// with the same creates ingested by the store as block 1.
+func TestSeedArkivStoreMatchesIngestion(t *testing.T) {
+	spec := &core.GenesisArkiv{Entities: []core.GenesisEntity{
+		{
+			Owner:              common.HexToAddress("0x1111"),
+			BTL:                100,
+			ContentType:        "text/plain",
+			Payload:            []byte("first"),
+			StringAnnotations:  []storagetx.StringAnnotation{{Key: "type", Value: "a"}},
+			NumericAnnotations: []storagetx.NumericAnnotation{{Key: "rank", Value: 1}},
+		},
+		{
+			Owner:              common.HexToAddress("0x2222"),
+			ExpiresAt:          50,
+			ContentType:        "application/json",
+			Payload:            []byte(`{"b":1}`),
+			StringAnnotations:  []storagetx.StringAnnotation{{Key: "type", Value: "b"}},
+			NumericAnnotations: []storagetx.NumericAnnotation{{Key: "rank", Value: 2}},
+		},
+	}}
+
+	seeded := newTestArkivStore(t)
+	require.NoError(t, seedArkivStore(seeded.store, seeded.path, spec))
+
+	// seeding again is a no-op
+	require.NoError(t, seedArkivStore(seeded.store, seeded.path, spec))
+
+	// The same creates as block 1, expiring at the same blocks
+	block := dbevents.GenesisBlock(spec)
+	block.Number = 1
+	for _, op := range block.Operations {
+		op.Create.BTL--
+	}
+	ingested := newTestArkivStore(t)
+	batches := arkivevents.BatchIterator(func(yield func(arkivevents.BatchOrError) bool) {
+		yield(arkivevents.BatchOrError{Batch: events.BlockBatch{Blocks: []events.Block{*block}}})
+	})
+	require.NoError(t, ingested.store.FollowEvents(context.Background(), batches))
+
+	queries := []string{
+		`$all`,
+		`type = "a"`,
+		`rank >= 1`,
+		`$owner = "0x0000000000000000000000000000000000002222"`,
+		`$expiration = 50`,
+		`$creator = "0x0000000000000000000000000000000000001111"`,
+	}
+	for _, q := range queries {
+		want := queryTestArkivStore(t, ingested.store, q)
+		have := queryTestArkivStore(t, seeded.store, q)
+		require.NotEmpty(t, want, q)
+
+		// Only the attributes derived from the block number differ
+		for i := range want {
+			require.Equal(t, uint64(1), *want[i].CreatedAtBlock, q)
+			require.Equal(t, uint64(0), *have[i].CreatedAtBlock, q)
+			want[i].CreatedAtBlock, have[i].CreatedAtBlock = nil, nil
+			want[i].LastModifiedAtBlock, have[i].LastModifiedAtBlock = nil, nil
+			want[i].NumericAttributes = slices.DeleteFunc(want[i].NumericAttributes, isBlockAttribute)
+			have[i].NumericAttributes = slices.DeleteFunc(have[i].NumericAttributes, isBlockAttribute)
+		}
+		require.Equal(t, want, have, q)
+	}
+
+	require.Len(t, queryTestArkivStore(t, seeded.store, `$sequence = 1`), 1)
+}
+
+type testArkivStore struct {
+	store *sqlitestore.SQLiteStore
+	path  string
+}
+
+func newTestArkivStore(t *testing.T) testArkivStore {
+	path := arkivMemoryStore()
+	store, err := sqlitestore.NewSQLiteStore(slog.New(slog.DiscardHandler), path, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return testArkivStore{store: store, path: path}
+}
+
+func queryTestArkivStore(t *testing.T, store *sqlitestore.SQLiteStore, q string) []sqlitestore.EntityData {
+	res, err := store.QueryEntities(context.Background(), q, &sqlitestore.Options{
+		IncludeData: &sqlitestore.IncludeData{
+			Key:                         true,
+			Attributes:                  true,
+			SyntheticAttributes:         true,
+			Payload:                     true,
+			ContentType:                 true,
+			Expiration:                  true,
+			Owner:                       true,
+			CreatedAtBlock:              true,
+			LastModifiedAtBlock:         true,
+			TransactionIndexInBlock:     true,
+			OperationIndexInTransaction: true,
+		},
+	})
+	require.NoError(t, err, q)
+	entities := make([]sqlitestore.EntityData, len(res.Data))
+	for i, data := range res.Data {
+		require.NoError(t, json.Unmarshal(data, &entities[i]))
+	}
+	return entities
+}
+
+func isBlockAttribute(a sqlitestore.Attribute[uint64]) bool {
+	switch a.Key {
+	case "$createdAtBlock", "$lastModifiedAtBlock", "$sequence":
+		return true
+	}
+	return false
+}
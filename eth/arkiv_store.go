@@ -0,0 +1,20 @@
+package eth
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// arkivMemoryStores numbers the in-memory SQL stores of the process.
+var arkivMemoryStores atomic.Uint64
+
+// arkivMemoryStore returns the path of a new in-memory SQL store.
+//
+// The store opens separate read and write connection pools, which don't share a
+// ":memory:" database. A database of the memdb VFS whose name starts with "/" is
+// shared by all connections of the process, until the last one is closed. The
+// store appends its own options to the path after a "?", which the trailing
+// parameter swallows.
+func arkivMemoryStore() string {
+	return fmt.Sprintf("/arkiv-%d?vfs=memdb&_ignored=", arkivMemoryStores.Add(1))
+}
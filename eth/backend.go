@@ -25,6 +25,7 @@ import (
 	"math"
 	"math/big"
 	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,10 +33,13 @@ import (
 	"github.com/holiman/uint256"
 	"golang.org/x/time/rate"
 
+	"github.com/Arkiv-Network/arkiv-events/events"
 	sqlitestore "github.com/Arkiv-Network/sqlite-bitmap-store"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/arkiv/dbevents"
+	"github.com/ethereum/go-ethereum/arkiv/eventsink"
+	"github.com/ethereum/go-ethereum/arkiv/history"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -141,12 +145,19 @@ type Ethereum struct {
 	interopRPC           *interop.InteropClient
 	supervisorFailsafe   atomic.Bool
 
+	// Arkiv additions
+	arkivStore   *sqlitestore.SQLiteStore
+	arkivSinks   *eventsink.Runner
+	arkivSync    *dbevents.SyncTracker
+	arkivHistory *history.Index
+	arkivCleanup []func() // releases the Arkiv resources, in reverse order
+
 	nodeCloser func() error
 }
 
 // New creates a new Ethereum object (including the initialisation of the common Ethereum object),
 // whose lifecycle will be managed by the provided node.
-func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
+func New(stack *node.Node, config *ethconfig.Config) (_ *Ethereum, err error) {
 	// Ensure configuration values are compatible and sane
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
@@ -315,7 +326,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	sqlStateFile := stack.Config().GolemBaseSQLStateFile
 
 	if sqlStateFile == "" {
-		sqlStateFile = ":memory:"
+		sqlStateFile = arkivMemoryStore()
 	}
 
 	store, err := sqlitestore.NewSQLiteStore(
@@ -326,22 +337,106 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sql store: %w", err)
 	}
+	eth.arkivStore = store
+
+	// The Arkiv resources are released in reverse order on Stop, or if the
+	// backend fails to start after they are acquired.
+	defer func() {
+		if err != nil {
+			eth.closeArkiv()
+		}
+	}()
+	eth.arkivCleanup = append(eth.arkivCleanup, func() {
+		if err := store.Close(); err != nil {
+			log.Error("Failed to close sql store", "err", err)
+		}
+	})
 
 	lastBlock, err := store.GetLastBlock(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last block from store: %w", err)
 	}
+	arkivSpec, err := arkivGenesis(chainDb, config.Genesis)
+	if err != nil {
+		return nil, err
+	}
+	if lastBlock == 0 {
+		if err = seedArkivStore(store, sqlStateFile, arkivSpec); err != nil {
+			return nil, fmt.Errorf("failed to seed sql store: %w", err)
+		}
+	}
 
-	batchIterator, onNewHead := dbevents.NewChainBatchIterator(chainDb, uint64(lastBlock))
+	followCtx, stopFollowing := context.WithCancel(context.Background())
+	batchIterator, onNewHead := dbevents.NewChainBatchIterator(followCtx, chainDb, uint64(lastBlock))
+	eth.arkivSync = dbevents.NewSyncTracker(uint64(lastBlock))
+	batchIterator = eth.arkivSync.Track(batchIterator)
 
+	followDone := make(chan struct{})
 	go func() {
-		err := store.FollowEvents(context.Background(), batchIterator)
+		defer close(followDone)
+		err := store.FollowEvents(followCtx, batchIterator)
 		if err != nil {
 			log.Error("failed to follow events", "error", err)
 		}
 	}()
+	eth.arkivCleanup = append(eth.arkivCleanup, func() {
+		stopFollowing()
+		<-followDone
+	})
 
-	eth.blockchain, err = core.NewBlockChainWithOnNewBlock(chainDb, config.Genesis, eth.engine, options, onNewHead)
+	// The history database is opened before the sinks, which are owned by the
+	// runner once it is started.
+	historyDb, err := stack.OpenDatabaseWithOptions("arkiv-history", node.DatabaseOptions{
+		Cache:            16,
+		Handles:          16,
+		MetricsNamespace: "eth/db/arkivhistory/",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open arkiv history database: %w", err)
+	}
+	eth.arkivCleanup = append(eth.arkivCleanup, func() {
+		if err := historyDb.Close(); err != nil {
+			log.Error("Failed to close arkiv history database", "err", err)
+		}
+	})
+
+	sinks, err := eventsink.New(eventsink.Config{
+		File:       stack.Config().ArkivSinkFile,
+		FileFormat: stack.Config().ArkivSinkFileFormat,
+		Webhook:    stack.Config().ArkivSinkWebhook,
+		Plugins:    stack.Config().ArkivSinkPlugins,
+		DataDir:    stack.ResolvePath("arkiv-sinks"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create arkiv event sinks: %w", err)
+	}
+	eth.arkivHistory = history.NewIndex(historyDb, chainDb, chainConfig)
+	sinks = append(sinks, eth.arkivHistory)
+
+	var genesisBlock *events.Block
+	if arkivSpec != nil && len(arkivSpec.Entities) > 0 {
+		genesisBlock = dbevents.GenesisBlock(arkivSpec)
+	}
+	eth.arkivSinks, err = eventsink.Start(chainDb, sinks, genesisBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start arkiv event sinks: %w", err)
+	}
+	eth.arkivCleanup = append(eth.arkivCleanup, func() {
+		if err := eth.arkivSinks.Close(); err != nil {
+			log.Error("Failed to close arkiv event sinks", "err", err)
+		}
+	})
+
+	onNewHeadWithSinks := func(cc *params.ChainConfig, block *types.Block) error {
+		err := onNewHead(cc, block)
+		if err != nil {
+			return err
+		}
+		eth.arkivSync.OnNewHead(block.NumberU64())
+		return eth.arkivSinks.OnNewHead(cc, block)
+	}
+
+	eth.blockchain, err = core.NewBlockChainWithOnNewBlock(chainDb, config.Genesis, eth.engine, options, onNewHeadWithSinks)
 	if err != nil {
 		return nil, err
 	}
@@ -475,6 +570,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error creating Arkiv API: %w", err)
 	}
+	eth.arkivCleanup = append(eth.arkivCleanup, arkivAPI.snapshots.Close)
 	// Register the backend on the node
 	stack.RegisterAPIs([]rpc.API{
 		{
@@ -560,6 +656,9 @@ func (s *Ethereum) Synced() bool                       { return s.handler.synced
 func (s *Ethereum) SetSynced()                         { s.handler.enableSyncedFeatures() }
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
 
+// ArkivSync returns the tracker of the blocks ingested by the Arkiv store.
+func (s *Ethereum) ArkivSync() *dbevents.SyncTracker { return s.arkivSync }
+
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -731,6 +830,7 @@ func (s *Ethereum) Stop() error {
 	if s.miner != nil {
 		s.miner.Close()
 	}
+	s.closeArkiv()
 
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
@@ -741,6 +841,14 @@ func (s *Ethereum) Stop() error {
 	return nil
 }
 
+// closeArkiv releases the Arkiv resources in the reverse order they were acquired.
+func (s *Ethereum) closeArkiv() {
+	for _, cleanup := range slices.Backward(s.arkivCleanup) {
+		cleanup()
+	}
+	s.arkivCleanup = nil
+}
+
 // SyncMode retrieves the current sync mode, either explicitly set, or derived
 // from the chain status.
 func (s *Ethereum) SyncMode() ethconfig.SyncMode {
@@ -336,6 +336,9 @@ func (s *Service) loop(chainHeadCh chan core.ChainHeadEvent, txEventCh chan core
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Post-block transaction stats report failed", "err", err)
 					}
+					if err = s.reportArkiv(conn); err != nil {
+						log.Warn("Post-block Arkiv stats report failed", "err", err)
+					}
 				case <-txCh:
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Transaction stats report failed", "err", err)
@@ -524,6 +527,9 @@ func (s *Service) report(conn *connWrapper) error {
 	if err := s.reportStats(conn); err != nil {
 		return err
 	}
+	if err := s.reportArkiv(conn); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -582,6 +588,8 @@ type blockStats struct {
 	TxHash     common.Hash    `json:"transactionsRoot"`
 	Root       common.Hash    `json:"stateRoot"`
 	Uncles     uncleStats     `json:"uncles"`
+
+	Arkiv *arkivBlockStats `json:"arkiv,omitempty"` // Arkiv operations, if supported by the node
 }
 
 // txStats is the information to report about individual transactions.
@@ -629,6 +637,7 @@ func (s *Service) assembleBlockStats(header *types.Header) *blockStats {
 	var (
 		txs    []txStats
 		uncles []*types.Header
+		arkiv  *arkivBlockStats
 	)
 
 	// check if backend is a full node
@@ -647,6 +656,7 @@ func (s *Service) assembleBlockStats(header *types.Header) *blockStats {
 			txs[i].Hash = tx.Hash()
 		}
 		uncles = block.Uncles()
+		arkiv = s.assembleArkivBlockStats(block)
 	} else {
 		// Light nodes would need on-demand lookups for transactions/uncles, skip
 		if header == nil {
@@ -671,6 +681,7 @@ func (s *Service) assembleBlockStats(header *types.Header) *blockStats {
 		TxHash:     header.TxHash,
 		Root:       header.Root,
 		Uncles:     uncles,
+		Arkiv:      arkiv,
 	}
 }
 
@@ -0,0 +1,121 @@
+package ethstats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity/entityexpiration"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// arkivExpiringRange is the number of upcoming blocks in which expiring entities
+// are counted.
+const arkivExpiringRange = 300
+
+// arkivBackend encompasses the functionality necessary for a node to report its
+// Arkiv storage to ethstats
+type arkivBackend interface {
+	StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+	ArkivEntityCount(ctx context.Context) (uint64, error)
+	ArkivSyncStatus() (ingested, head uint64)
+}
+
+// arkivStats is the information to report about the Arkiv storage of the node.
+type arkivStats struct {
+	Entities      uint64 `json:"entities"`      // Live entities in the store
+	UsedSlots     uint64 `json:"usedSlots"`     // Storage slots used at the chain head
+	Expiring      uint64 `json:"expiring"`      // Entities expiring in the next ExpiringRange blocks
+	ExpiringRange uint64 `json:"expiringRange"` // Number of blocks Expiring covers
+	StoreBlock    uint64 `json:"storeBlock"`    // Last block ingested by the store
+	IngestionLag  uint64 `json:"ingestionLag"`  // Blocks the store is behind the chain head
+}
+
+// arkivBlockStats is the information to report about the Arkiv operations of
+// individual blocks.
+type arkivBlockStats struct {
+	Ops          uint64 `json:"ops"`
+	PayloadBytes uint64 `json:"payloadBytes"`
+}
+
+// assembleArkivBlockStats sums up the Arkiv operations executed in block. It
+// returns nil if the backend doesn't support Arkiv or the receipts are missing.
+func (s *Service) assembleArkivBlockStats(block *types.Block) *arkivBlockStats {
+	backend, ok := s.backend.(arkivBackend)
+	if !ok {
+		return nil
+	}
+	receipts, err := backend.GetReceipts(context.Background(), block.Hash())
+	if err != nil || len(receipts) != len(block.Transactions()) {
+		return nil
+	}
+	var usage storagetx.BlockUsage
+	for i, tx := range block.Transactions() {
+		usage = usage.Add(storagetx.ReceiptUsage(tx, receipts[i]))
+	}
+	return &arkivBlockStats{
+		Ops:          usage.Ops,
+		PayloadBytes: usage.PayloadBytes,
+	}
+}
+
+// assembleArkivStats retrieves the storage statistics of the chain head and the
+// Arkiv store.
+func (s *Service) assembleArkivStats(backend arkivBackend) (*arkivStats, error) {
+	ctx := context.Background()
+
+	statedb, header, err := backend.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+	entities, err := backend.ArkivEntityCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &arkivStats{
+		Entities:      entities,
+		UsedSlots:     storageaccounting.GetNumberOfUsedSlots(statedb).Uint64(),
+		ExpiringRange: arkivExpiringRange,
+	}
+	number := header.Number.Uint64()
+	for block := number + 1; block <= number+arkivExpiringRange; block++ {
+		stats.Expiring += entityexpiration.NumberOfEntitiesToExpireAtBlock(statedb, block)
+	}
+	ingested, head := backend.ArkivSyncStatus()
+	stats.StoreBlock = ingested
+	if head > ingested {
+		stats.IngestionLag = head - ingested
+	}
+	return stats, nil
+}
+
+// reportArkiv retrieves the Arkiv storage statistics and reports them to the
+// stats server. Nodes without Arkiv support don't report anything.
+func (s *Service) reportArkiv(conn *connWrapper) error {
+	backend, ok := s.backend.(arkivBackend)
+	if !ok {
+		return nil
+	}
+	details, err := s.assembleArkivStats(backend)
+	if err != nil {
+		// Short circuit, as the storage is not a reason to drop the connection
+		log.Debug("Failed to gather Arkiv stats", "err", err)
+		return nil
+	}
+	log.Trace("Sending Arkiv stats to ethstats", "entities", details.Entities, "lag", details.IngestionLag)
+
+	stats := map[string]interface{}{
+		"id":    s.node,
+		"stats": details,
+	}
+	report := map[string][]interface{}{
+		"emit": {"arkiv", stats},
+	}
+	return conn.WriteJSON(report)
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity/entityexpiration"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/gorilla/websocket"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// testArkivBackend is a full node backend with Arkiv support serving a single
+// head block.
+type testArkivBackend struct {
+	block    *types.Block
+	receipts types.Receipts
+	statedb  *state.StateDB
+	entities uint64
+	ingested uint64
+}
+
+func (b *testArkivBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return nil
+}
+func (b *testArkivBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return nil
+}
+func (b *testArkivBackend) CurrentHeader() *types.Header { return b.block.Header() }
+func (b *testArkivBackend) CurrentBlock() *types.Header  { return b.block.Header() }
+func (b *testArkivBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.block.Header(), nil
+}
+func (b *testArkivBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	return b.block, nil
+}
+func (b *testArkivBackend) Stats() (pending int, queued int) { return 0, 0 }
+func (b *testArkivBackend) SyncProgress(ctx context.Context) ethereum.SyncProgress {
+	return ethereum.SyncProgress{}
+}
+func (b *testArkivBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return new(big.Int), nil
+}
+func (b *testArkivBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.statedb, b.block.Header(), nil
+}
+func (b *testArkivBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts, nil
+}
+func (b *testArkivBackend) ArkivEntityCount(ctx context.Context) (uint64, error) {
+	return b.entities, nil
+}
+func (b *testArkivBackend) ArkivSyncStatus() (ingested, head uint64) {
+	return b.ingested, b.block.NumberU64()
+}
+
+// startTestServer starts a stand-in stats server, which forwards the messages
+// it receives, and returns a connection to it.
+func startTestServer(t *testing.T) (*connWrapper, <-chan map[string][]json.RawMessage) {
+	msgs := make(chan map[string][]json.RawMessage, 16)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var msg map[string][]json.RawMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			msgs <- msg
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return newConnectionWrapper(conn), msgs
+}
+
+// readEmit reads the next message from the stats server and decodes its content.
+func readEmit(t *testing.T, msgs <-chan map[string][]json.RawMessage, name string, content interface{}) {
+	msg := <-msgs
+	require.Len(t, msg["emit"], 2)
+
+	var emitted string
+	require.NoError(t, json.Unmarshal(msg["emit"][0], &emitted))
+	require.Equal(t, name, emitted)
+	require.NoError(t, json.Unmarshal(msg["emit"][1], content))
+}
+
+func TestReportArkiv(t *testing.T) {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	// Two entities expire within the reported range and one after it
+	const head = 100
+	require.NoError(t, entityexpiration.AddToEntitiesToExpireAtBlock(statedb, head+1, common.Hash{1}))
+	require.NoError(t, entityexpiration.AddToEntitiesToExpireAtBlock(statedb, head+arkivExpiringRange, common.Hash{2}))
+	require.NoError(t, entityexpiration.AddToEntitiesToExpireAtBlock(statedb, head+arkivExpiringRange+1, common.Hash{3}))
+	statedb.SetState(address.ArkivProcessorAddress, storageaccounting.UsedSlotsKey, uint256.NewInt(42).Bytes32())
+
+	encoded, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{
+			{BTL: 100, ContentType: "text/plain", Payload: []byte("hello")},
+			{BTL: 100, ContentType: "text/plain", Payload: []byte("world")},
+		},
+	})
+	require.NoError(t, err)
+	data := compression.MustBrotliCompress(encoded)
+
+	// Only the successful transaction to the processor performs operations
+	txs := types.Transactions{
+		types.NewTx(&types.LegacyTx{Nonce: 0, To: &address.ArkivProcessorAddress, Data: data}),
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Value: big.NewInt(1)}),
+		types.NewTx(&types.LegacyTx{Nonce: 2, To: &address.ArkivProcessorAddress, Data: data}),
+	}
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful},
+		{Status: types.ReceiptStatusSuccessful},
+		{Status: types.ReceiptStatusFailed},
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(head), Difficulty: new(big.Int)}, &types.Body{Transactions: txs}, receipts, trie.NewStackTrie(nil), types.DefaultBlockConfig)
+
+	s := &Service{
+		backend: &testArkivBackend{
+			block:    block,
+			receipts: receipts,
+			statedb:  statedb,
+			entities: 7,
+			ingested: head - 3,
+		},
+		engine: ethash.NewFaker(),
+		node:   "test",
+	}
+	conn, msgs := startTestServer(t)
+
+	require.NoError(t, s.reportArkiv(conn))
+	var arkiv struct {
+		ID    string     `json:"id"`
+		Stats arkivStats `json:"stats"`
+	}
+	readEmit(t, msgs, "arkiv", &arkiv)
+	require.Equal(t, "test", arkiv.ID)
+	require.Equal(t, arkivStats{
+		Entities:      7,
+		UsedSlots:     42,
+		Expiring:      2,
+		ExpiringRange: arkivExpiringRange,
+		StoreBlock:    head - 3,
+		IngestionLag:  3,
+	}, arkiv.Stats)
+
+	require.NoError(t, s.reportBlock(conn, nil))
+	var report struct {
+		Block struct {
+			Number *big.Int         `json:"number"`
+			Arkiv  *arkivBlockStats `json:"arkiv"`
+		} `json:"block"`
+	}
+	readEmit(t, msgs, "block", &report)
+	require.Equal(t, uint64(head), report.Block.Number.Uint64())
+	require.Equal(t, &arkivBlockStats{Ops: 2, PayloadBytes: uint64(len(encoded))}, report.Block.Arkiv)
+}
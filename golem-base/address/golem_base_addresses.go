@@ -4,4 +4,8 @@ import "github.com/ethereum/go-ethereum/common"
 
 var (
 	ArkivProcessorAddress = common.HexToAddress("0x00000000000000000000000000000061726B6976")
+
+	// ArkivEntityMetaDataPrecompileAddress is the address of the precompile that
+	// exposes entity metadata (owner, expiration) to smart contracts.
+	ArkivEntityMetaDataPrecompileAddress = common.HexToAddress("0x0000000000000000000000000000000000000A01")
 )
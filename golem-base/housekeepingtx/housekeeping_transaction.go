@@ -3,6 +3,7 @@ package housekeepingtx
 import (
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/tracing"
@@ -13,6 +14,15 @@ import (
 	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
 	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
 	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity/entityexpiration"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	housekeepingTimer = metrics.NewRegisteredTimer("arkiv/housekeeping/time", nil)
+	expiredMeter      = metrics.NewRegisteredMeter("arkiv/housekeeping/expired", nil)
+	renewedMeter      = metrics.NewRegisteredMeter("arkiv/housekeeping/renewed", nil)
 )
 
 func addressToHash(a common.Address) common.Hash {
@@ -21,7 +31,14 @@ func addressToHash(a common.Address) common.Hash {
 	return h
 }
 
-func ExecuteTransaction(blockNumber uint64, txHash common.Hash, db vm.StateDB) (_ []*types.Log, err error) {
+// ExecuteTransaction expires the entities that expire at blockNumber. Since the
+// Arkiv renewal fork of rules, the entities with a renewal budget covering the
+// fee are extended instead. The renewal fees and the budgets of the expired
+// entities are burned from the balance of the processor, which holds the
+// budgets since the deposits were transferred to it.
+func ExecuteTransaction(rules params.Rules, blockNumber uint64, txHash common.Hash, db vm.StateDB) (_ []*types.Log, err error) {
+
+	defer housekeepingTimer.UpdateSince(time.Now())
 
 	// create the golem base storage processor address if it doesn't exist
 	// this is needed to be able to use the state access interface
@@ -41,6 +58,9 @@ func ExecuteTransaction(blockNumber uint64, txHash common.Hash, db vm.StateDB) (
 		}
 	}()
 
+	// burned sums up the renewal fees and the forfeited renewal budgets
+	burned := new(uint256.Int)
+
 	deleteEntity := func(toDelete common.Hash) error {
 
 		owner, err := entity.Delete(st, toDelete)
@@ -48,6 +68,12 @@ func ExecuteTransaction(blockNumber uint64, txHash common.Hash, db vm.StateDB) (
 			return fmt.Errorf("failed to delete entity: %w", err)
 		}
 
+		if rules.IsArkivRenewal {
+			// what is left of the renewal budget doesn't cover a renewal anymore
+			burned.Add(burned, &entity.GetEntityRenewal(st, toDelete).Budget)
+			entity.DeleteEntityRenewal(st, toDelete)
+		}
+
 		// create the log for the created entity
 		logs = append(
 			logs,
@@ -66,14 +92,78 @@ func ExecuteTransaction(blockNumber uint64, txHash common.Hash, db vm.StateDB) (
 		return nil
 	}
 
-	toDelete := slices.Collect(entityexpiration.IteratorOfEntitiesToExpireAtBlock(st, blockNumber))
+	// renewEntity extends the entity by its renewal period if its renewal
+	// budget covers the fee, and reports whether it did.
+	renewEntity := func(toRenew common.Hash) (bool, error) {
+
+		if !rules.IsArkivRenewal {
+			return false, nil
+		}
+
+		renewal := entity.GetEntityRenewal(st, toRenew)
+		if !renewal.Renews() {
+			return false, nil
+		}
+		fee := renewal.Fee()
+
+		oldExpiresAtBlock, owner, err := entity.ExtendBTL(st, toRenew, renewal.Period)
+		if err != nil {
+			return false, fmt.Errorf("failed to extend entity: %w", err)
+		}
+
+		renewal.Budget.Sub(&renewal.Budget, fee)
+		burned.Add(burned, fee)
+		entity.StoreEntityRenewal(st, toRenew, *renewal)
+
+		data := make([]byte, 128)
+		uint256.NewInt(oldExpiresAtBlock).PutUint256(data[:32])
+		uint256.NewInt(oldExpiresAtBlock + renewal.Period).PutUint256(data[32:64])
+		fee.PutUint256(data[64:96])
+		renewal.Budget.PutUint256(data[96:])
+
+		logs = append(
+			logs,
+			&types.Log{
+				Address: common.Address(address.ArkivProcessorAddress),
+				Topics: []common.Hash{
+					arkivlogs.ArkivEntityRenewed,
+					toRenew,
+					addressToHash(owner),
+				},
+				Data:        data,
+				BlockNumber: blockNumber,
+			},
+		)
+
+		return true, nil
+	}
+
+	toExpire := slices.Collect(entityexpiration.IteratorOfEntitiesToExpireAtBlock(st, blockNumber))
 
-	for _, key := range toDelete {
-		err := deleteEntity(key)
+	expired, renewed := 0, 0
+	for _, key := range toExpire {
+		ok, err := renewEntity(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to renew entity %s: %w", key.Hex(), err)
+		}
+		if ok {
+			renewed++
+			continue
+		}
+
+		err = deleteEntity(key)
 		if err != nil {
 			return nil, fmt.Errorf("failed to delete entity %s: %w", key.Hex(), err)
 		}
+		expired++
+	}
+
+	if !burned.IsZero() {
+		db.SubBalance(address.ArkivProcessorAddress, burned, tracing.BalanceDecreaseArkivBurn)
 	}
 
+	expiredMeter.Mark(int64(expired))
+	renewedMeter.Mark(int64(renewed))
+
 	return logs, nil
 }
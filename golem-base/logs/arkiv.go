@@ -25,3 +25,16 @@ var ArkivEntityBTLExtended = crypto.Keccak256Hash([]byte("ArkivEntityBTLExtended
 // ArkivEntityOwnerChanged is the event signature for changing the owner of an entity.
 // Parameters: entityKey (indexed), oldOwnerAddress(indexed), newOwnerAddress(indexed)
 var ArkivEntityOwnerChanged = crypto.Keccak256Hash([]byte("ArkivEntityOwnerChanged(uint256,address,address)"))
+
+// ArkivEntityRenewalSet is the event signature for setting the automatic renewal of an entity.
+// Parameters: entityKey (indexed), ownerAddress(indexed), renewalPeriod, renewalBudget (wei)
+var ArkivEntityRenewalSet = crypto.Keccak256Hash([]byte("ArkivEntityRenewalSet(uint256,address,uint256,uint256)"))
+
+// ArkivEntityRenewed is the event signature for the automatic renewal of an expiring entity by housekeeping.
+// Parameters: entityKey (indexed), ownerAddress(indexed), oldExpirationBlock, newExpirationBlock, fee (wei), remainingBudget (wei)
+var ArkivEntityRenewed = crypto.Keccak256Hash([]byte("ArkivEntityRenewed(uint256,address,uint256,uint256,uint256,uint256)"))
+
+// ArkivContractCall is the event signature emitted when a contract issues an Arkiv transaction
+// through a CALL to the processor address. It precedes the logs of the executed operations.
+// Parameters: senderAddress(indexed), callIndex(indexed), value(indexed) (wei), call data (brotli compressed RLP encoded transaction)
+var ArkivContractCall = crypto.Keccak256Hash([]byte("ArkivContractCall(address,uint256,uint256,bytes)"))
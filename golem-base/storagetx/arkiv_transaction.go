@@ -2,12 +2,15 @@ package storagetx
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"time"
 
 	"github.com/andybalholm/brotli"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/golem-base/address"
@@ -16,12 +19,23 @@ import (
 	"github.com/ethereum/go-ethereum/golem-base/storageutil"
 	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/holiman/uint256"
 )
 
 //go:generate go run ../../rlp/rlpgen -type ArkivTransaction -out gen_arkiv_transaction_rlp.go
 
+var (
+	// ErrRenewalNotActive is returned when a transaction has renewals before
+	// the Arkiv renewal fork.
+	ErrRenewalNotActive = errors.New("arkiv renewals are not active")
+
+	// ErrTypedAnnotationsNotActive is returned when a create or an update has
+	// typed annotations before the Arkiv typed annotations fork.
+	ErrTypedAnnotationsNotActive = errors.New("arkiv typed annotations are not active")
+)
+
 // ArkivTransaction represents a transaction that can be applied to the storage layer.
 // It contains a list of Create operations, a list of Update operations and a list of Delete operations.
 //
@@ -29,18 +43,22 @@ import (
 //   - Create: adds new entities to the storage layer. Each entity has a BTL (number of blocks), a payload and a list of annotations. The Key of the entity is derived from the payload content, the transaction hash where the entity was created and the index of the create operation in the transaction.
 //   - Update: updates existing entities. Each entity has a key, a BTL (number of blocks), a payload and a list of annotations. If the entity does not exist, the operation fails, failing the whole transaction.
 //   - Delete: removes entities from the storage layer. If the entity does not exist, the operation fails, failing back the whole transaction.
+//   - Renew: sets the period by which housekeeping extends expiring entities, and adds deposits from the transaction value to their renewal budget.
 //
 // The transaction is atomic, meaning that all operations are applied or none are.
 //
-// Annotations are key-value pairs where the key is a string and the value is either a string or a number.
-// The key-value pairs are used to build indexes and to query the storage layer.
-// Same key can have both string and numeric annotation, but not multiple values of the same type.
+// Annotations are key-value pairs where the key is a string and the value is a string, a number, a signed
+// integer, a fixed-point decimal, a bool, an address or a 32 byte value. The key-value pairs are used to build
+// indexes and to query the storage layer. Every annotation is indexed either as a string (strings, signed
+// integers, decimals, addresses and 32 byte values) or as a number (numbers and bools). Same key can have both a
+// string and a numeric annotation, but not multiple values indexed the same way.
 type ArkivTransaction struct {
 	Create      []ArkivCreate      `json:"create"`
 	Update      []ArkivUpdate      `json:"update"`
 	Delete      []common.Hash      `json:"delete"`
 	Extend      []ExtendBTL        `json:"extend"`
 	ChangeOwner []ArkivChangeOwner `json:"changeOwner"`
+	Renew       []ArkivRenew       `json:"renew" rlp:"optional"`
 }
 
 type ExtendBTL struct {
@@ -55,9 +73,6 @@ func (tx *ArkivTransaction) Validate() error {
 			return fmt.Errorf("create BTL is 0")
 		}
 
-		seenStringAnnotations := make(map[string]bool)
-		seenNumericAnnotations := make(map[string]bool)
-
 		if create.ContentType == "" {
 			return fmt.Errorf("create[%d] contentType is empty", i)
 		}
@@ -66,34 +81,9 @@ func (tx *ArkivTransaction) Validate() error {
 			return fmt.Errorf("create[%d] contentType is too long", i)
 		}
 
-		// Validate the annotation identifiers
-		for _, annotation := range create.StringAnnotations {
-			if !entity.AnnotationIdentRegexCompiled.MatchString(annotation.Key) {
-				return fmt.Errorf("invalid annotation identifier (must match `%s`): %s",
-					entity.AnnotationIdentRegexCompiled.String(),
-					annotation.Key,
-				)
-			}
-			if seenStringAnnotations[annotation.Key] {
-				return fmt.Errorf("create[%d] string annotation key %s is duplicated", i, annotation.Key)
-			}
-
-			seenStringAnnotations[annotation.Key] = true
-
-		}
-		for _, annotation := range create.NumericAnnotations {
-			if !entity.AnnotationIdentRegexCompiled.MatchString(annotation.Key) {
-				return fmt.Errorf("invalid annotation identifier (must match `%s`): %s",
-					entity.AnnotationIdentRegexCompiled.String(),
-					annotation.Key,
-				)
-			}
-			if seenNumericAnnotations[annotation.Key] {
-				return fmt.Errorf("create[%d] numeric annotation key %s is duplicated", i, annotation.Key)
-			}
-			seenNumericAnnotations[annotation.Key] = true
+		if err := create.Annotations().Validate(); err != nil {
+			return fmt.Errorf("create[%d] %w", i, err)
 		}
-
 	}
 
 	for i, update := range tx.Update {
@@ -109,34 +99,9 @@ func (tx *ArkivTransaction) Validate() error {
 			return fmt.Errorf("update[%d] contentType is too long", i)
 		}
 
-		seenStringAnnotations := make(map[string]bool)
-		seenNumericAnnotations := make(map[string]bool)
-
-		for _, annotation := range update.StringAnnotations {
-			if !entity.AnnotationIdentRegexCompiled.MatchString(annotation.Key) {
-				return fmt.Errorf("invalid annotation identifier (must match `%s`): %s",
-					entity.AnnotationIdentRegexCompiled.String(),
-					annotation.Key,
-				)
-			}
-			if seenStringAnnotations[annotation.Key] {
-				return fmt.Errorf("update[%d] string annotation key %s is duplicated", i, annotation.Key)
-			}
-			seenStringAnnotations[annotation.Key] = true
-		}
-		for _, annotation := range update.NumericAnnotations {
-			if !entity.AnnotationIdentRegexCompiled.MatchString(annotation.Key) {
-				return fmt.Errorf("invalid annotation identifier (must match `%s`): %s",
-					entity.AnnotationIdentRegexCompiled.String(),
-					annotation.Key,
-				)
-			}
-			if seenNumericAnnotations[annotation.Key] {
-				return fmt.Errorf("update[%d] numeric annotation key %s is duplicated", i, annotation.Key)
-			}
-			seenNumericAnnotations[annotation.Key] = true
+		if err := update.Annotations().Validate(); err != nil {
+			return fmt.Errorf("update[%d] %w", i, err)
 		}
-
 	}
 
 	for i, extend := range tx.Extend {
@@ -155,6 +120,11 @@ type ArkivCreate struct {
 	Payload            []byte              `json:"payload"`
 	StringAnnotations  []StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []NumericAnnotation `json:"numericAnnotations"`
+	IntAnnotations     []IntAnnotation     `json:"intAnnotations,omitempty" rlp:"optional"`
+	DecimalAnnotations []DecimalAnnotation `json:"decimalAnnotations,omitempty" rlp:"optional"`
+	BoolAnnotations    []BoolAnnotation    `json:"boolAnnotations,omitempty" rlp:"optional"`
+	AddressAnnotations []AddressAnnotation `json:"addressAnnotations,omitempty" rlp:"optional"`
+	Bytes32Annotations []Bytes32Annotation `json:"bytes32Annotations,omitempty" rlp:"optional"`
 }
 
 type ArkivUpdate struct {
@@ -164,6 +134,11 @@ type ArkivUpdate struct {
 	Payload            []byte              `json:"payload"`
 	StringAnnotations  []StringAnnotation  `json:"stringAnnotations"`
 	NumericAnnotations []NumericAnnotation `json:"numericAnnotations"`
+	IntAnnotations     []IntAnnotation     `json:"intAnnotations,omitempty" rlp:"optional"`
+	DecimalAnnotations []DecimalAnnotation `json:"decimalAnnotations,omitempty" rlp:"optional"`
+	BoolAnnotations    []BoolAnnotation    `json:"boolAnnotations,omitempty" rlp:"optional"`
+	AddressAnnotations []AddressAnnotation `json:"addressAnnotations,omitempty" rlp:"optional"`
+	Bytes32Annotations []Bytes32Annotation `json:"bytes32Annotations,omitempty" rlp:"optional"`
 }
 
 type StringAnnotation struct {
@@ -181,13 +156,36 @@ type ArkivChangeOwner struct {
 	NewOwner  common.Address `json:"newOwner"`
 }
 
+// ArkivRenew sets the automatic renewal of an entity: when the entity expires,
+// housekeeping extends it by Period blocks as long as its renewal budget covers
+// the fee. Deposit is added to the budget and is paid out of the value of the
+// transaction. A Period of 0 stops the renewals, keeping the budget.
+type ArkivRenew struct {
+	EntityKey common.Hash  `json:"entityKey"`
+	Period    uint64       `json:"period"`
+	Deposit   *uint256.Int `json:"deposit"`
+}
+
+// EntityKey returns the key of the entity created by the create operation
+// with index opIx and the given payload in the transaction txHash.
+func EntityKey(txHash common.Hash, payload []byte, opIx int) common.Hash {
+	// Convert i to a big integer and pad to 32 bytes
+	bigI := big.NewInt(int64(opIx))
+	paddedI := common.LeftPadBytes(bigI.Bytes(), 32)
+
+	return crypto.Keccak256Hash(txHash.Bytes(), payload, paddedI)
+}
+
 func addressToHash(a common.Address) common.Hash {
 	h := common.Hash{}
 	copy(h[12:], a[:])
 	return h
 }
 
-func (tx *ArkivTransaction) Run(blockNumber uint64, txHash common.Hash, txIx int, sender common.Address, access storageutil.StateAccess) (_ []*types.Log, err error) {
+// Run applies the operations of the transaction. The value is the amount of wei
+// the transaction transferred to the processor, which pays for the renewal
+// deposits.
+func (tx *ArkivTransaction) Run(blockNumber uint64, txHash common.Hash, txIx int, sender common.Address, value *uint256.Int, access storageutil.StateAccess) (_ []*types.Log, err error) {
 
 	defer func() {
 		if err != nil {
@@ -242,11 +240,7 @@ func (tx *ArkivTransaction) Run(blockNumber uint64, txHash common.Hash, txIx int
 
 	for opIx, create := range tx.Create {
 
-		// Convert i to a big integer and pad to 32 bytes
-		bigI := big.NewInt(int64(opIx))
-		paddedI := common.LeftPadBytes(bigI.Bytes(), 32)
-
-		key := crypto.Keccak256Hash(txHash.Bytes(), create.Payload, paddedI)
+		key := EntityKey(txHash, create.Payload, opIx)
 
 		ap := &entity.EntityMetaData{
 			Owner:          sender,
@@ -305,6 +299,9 @@ func (tx *ArkivTransaction) Run(blockNumber uint64, txHash common.Hash, txIx int
 		if err != nil {
 			return nil, err
 		}
+
+		// the remaining renewal budget is forfeited
+		entity.DeleteEntityRenewal(access, toDelete)
 	}
 
 	for _, update := range tx.Update {
@@ -427,45 +424,223 @@ func (tx *ArkivTransaction) Run(blockNumber uint64, txHash common.Hash, txIx int
 		)
 	}
 
+	if value == nil {
+		value = uint256.NewInt(0)
+	}
+	deposits := uint256.NewInt(0)
+
+	for _, renew := range tx.Renew {
+		md, err := entity.GetEntityMetaData(access, renew.EntityKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity meta data for renew %s: %w", renew.EntityKey.Hex(), err)
+		}
+
+		if md.Owner != sender {
+			return nil, fmt.Errorf("failed to set renewal of entity %s: %s is not the owner", renew.EntityKey.Hex(), sender.Hex())
+		}
+
+		renewal := entity.GetEntityRenewal(access, renew.EntityKey)
+		renewal.Period = renew.Period
+
+		if renew.Deposit != nil {
+			if _, overflow := deposits.AddOverflow(deposits, renew.Deposit); overflow || deposits.Gt(value) {
+				return nil, fmt.Errorf("renewal deposits exceed the transaction value of %s wei", value.Dec())
+			}
+			renewal.Budget.Add(&renewal.Budget, renew.Deposit)
+			if renewal.Budget.Gt(entity.MaxRenewalBudget) || renewal.Budget.Lt(renew.Deposit) {
+				return nil, fmt.Errorf("renewal budget of entity %s overflows", renew.EntityKey.Hex())
+			}
+		}
+
+		entity.StoreEntityRenewal(access, renew.EntityKey, *renewal)
+
+		data := make([]byte, 64)
+		uint256.NewInt(renewal.Period).PutUint256(data[:32])
+		renewal.Budget.PutUint256(data[32:])
+
+		logs = append(
+			logs,
+			&types.Log{
+				Address: common.Address(address.ArkivProcessorAddress),
+				Topics: []common.Hash{
+					arkivlogs.ArkivEntityRenewalSet,
+					renew.EntityKey,
+					addressToHash(md.Owner),
+				},
+				Data:        data,
+				BlockNumber: blockNumber,
+			},
+		)
+	}
+
 	return logs, nil
 }
 
 const maxCompressedSize = 1024 * 1024 * 20 // 20MB
 
 func UnpackArkivTransaction(compressed []byte) (*ArkivTransaction, error) {
+	tx, _, err := UnpackArkivTransactionWithSize(compressed)
+	return tx, err
+}
+
+// UnpackArkivTransactionWithSize decompresses and decodes an Arkiv transaction
+// like UnpackArkivTransaction, and also returns the size of the decompressed data.
+func UnpackArkivTransactionWithSize(compressed []byte) (*ArkivTransaction, int, error) {
+	tx, d, err := unpackArkivTransaction(compressed)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tx, len(d), nil
+}
+
+// UnpackArkivTransactionForRules decompresses and decodes an Arkiv transaction
+// like UnpackArkivTransactionWithSize, but only accepts the fields of the forks
+// active in rules. Before a fork, a transaction using its fields fails to decode,
+// the same as on the nodes that don't know the fork.
+func UnpackArkivTransactionForRules(compressed []byte, rules params.Rules) (*ArkivTransaction, int, error) {
+	tx, d, err := unpackArkivTransaction(compressed)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkForkFields(d, rules); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode storage transaction: %w", err)
+	}
+	return tx, len(d), nil
+}
+
+func unpackArkivTransaction(compressed []byte) (*ArkivTransaction, []byte, error) {
 	reader := brotli.NewReader(bytes.NewReader(compressed))
 	lr := io.LimitReader(reader, maxCompressedSize)
 
 	d, err := io.ReadAll(lr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read compressed storage transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to read compressed storage transaction: %w", err)
 	}
 
 	tx := &ArkivTransaction{}
 	err = rlp.DecodeBytes(d, tx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode storage transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to decode storage transaction: %w", err)
+	}
+
+	// Renewals are optional in the encoding, an empty list is the same as none
+	if len(tx.Renew) == 0 {
+		tx.Renew = nil
 	}
 
-	return tx, nil
+	return tx, d, nil
 }
 
-func ExecuteArkivTransaction(compressed []byte, blockNumber uint64, txHash common.Hash, txIx int, sender common.Address, access storageutil.StateAccess) ([]*types.Log, error) {
+// The number of fields of the encodings before the forks that added optional
+// fields: the Renew list of ArkivTransaction, and the typed annotations of
+// ArkivCreate and ArkivUpdate.
+const (
+	legacyArkivTransactionFields = 5
+	legacyArkivCreateFields      = 5
+	legacyArkivUpdateFields      = 6
+)
 
-	tx, err := UnpackArkivTransaction(compressed)
+// checkForkFields returns an error if the encoded transaction d has optional
+// fields of forks that are not active in rules. Those fields are rejected even
+// if they are empty, as the decoders before the forks reject them.
+func checkForkFields(d []byte, rules params.Rules) error {
+	content, _, err := rlp.SplitList(d)
+	if err != nil {
+		return err
+	}
+	fields, err := rlp.CountValues(content)
+	if err != nil {
+		return err
+	}
+	if !rules.IsArkivRenewal && fields > legacyArkivTransactionFields {
+		return ErrRenewalNotActive
+	}
+	if !rules.IsArkivTypedAnnotations {
+		creates, rest, err := rlp.SplitList(content)
+		if err != nil {
+			return err
+		}
+		if err := checkListItemFields(creates, legacyArkivCreateFields); err != nil {
+			return err
+		}
+		updates, _, err := rlp.SplitList(rest)
+		if err != nil {
+			return err
+		}
+		if err := checkListItemFields(updates, legacyArkivUpdateFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkListItemFields returns ErrTypedAnnotationsNotActive if an item of the
+// encoded list content has more than the given number of fields.
+func checkListItemFields(content []byte, fields int) error {
+	for len(content) > 0 {
+		item, rest, err := rlp.SplitList(content)
+		if err != nil {
+			return err
+		}
+		n, err := rlp.CountValues(item)
+		if err != nil {
+			return err
+		}
+		if n > fields {
+			return ErrTypedAnnotationsNotActive
+		}
+		content = rest
+	}
+	return nil
+}
+
+// ExecuteArkivTransaction decodes and runs the compressed Arkiv transaction under
+// the forks active in rules. On success, the renewal budgets forfeited by the
+// deleted entities are burned from the balance of the processor, which holds
+// the budgets since the deposits were transferred to it.
+func ExecuteArkivTransaction(compressed []byte, rules params.Rules, blockNumber uint64, txHash common.Hash, txIx int, sender common.Address, value *uint256.Int, db storageutil.StateDB) (_ []*types.Log, err error) {
+
+	defer func(start time.Time) {
+		executedMeter.Mark(1)
+		executionTimer.UpdateSince(start)
+		if err != nil {
+			failedMeter.Mark(1)
+		}
+	}(time.Now())
+
+	tx, _, err := UnpackArkivTransactionForRules(compressed, rules)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unpack arkiv transaction: %w", err)
 	}
 
-	st := storageaccounting.NewSlotUsageCounter(access)
+	// The deletes fail unless the entities exist until then, so the budgets
+	// of the entities before the transaction are the forfeited ones.
+	forfeited := new(uint256.Int)
+	for _, key := range tx.Delete {
+		forfeited.Add(forfeited, &entity.GetEntityRenewal(db, key).Budget)
+	}
+
+	st := storageaccounting.NewSlotUsageCounter(db)
 
-	logs, err := tx.Run(blockNumber, txHash, txIx, sender, st)
+	logs, err := tx.Run(blockNumber, txHash, txIx, sender, value, st)
 	if err != nil {
 		log.Error("Failed to run storage transaction", "error", err)
 		return nil, fmt.Errorf("failed to run storage transaction: %w", err)
 	}
 
+	operationsMeter.Mark(int64(tx.OperationCount()))
+	for _, create := range tx.Create {
+		payloadBytesMeter.Mark(int64(len(create.Payload)))
+	}
+	for _, update := range tx.Update {
+		payloadBytesMeter.Mark(int64(len(update.Payload)))
+	}
+
 	st.UpdateUsedSlotsForGolemBase()
 
+	if !forfeited.IsZero() {
+		db.SubBalance(address.ArkivProcessorAddress, forfeited, tracing.BalanceDecreaseArkivBurn)
+	}
+
 	return logs, nil
 }
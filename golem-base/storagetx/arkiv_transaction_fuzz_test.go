@@ -0,0 +1,338 @@
+package storagetx_test
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/housekeepingtx"
+	"github.com/ethereum/go-ethereum/golem-base/storageaccounting"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity/entityexpiration"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/keyset"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/keyset/hashmap"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// The seed corpora in testdata/fuzz are derived from the cucumber scenarios in
+// golem-base/features. Run the fuzzers with e.g.
+//
+//	go test ./golem-base/storagetx -run '^$' -fuzz FuzzArkivStateTransitions
+
+var fuzzSenders = []common.Address{
+	common.HexToAddress("0x1111"),
+	common.HexToAddress("0x2222"),
+	common.HexToAddress("0x3333"),
+}
+
+// FuzzUnpackArkivTransaction decodes arbitrary bytes as a compressed Arkiv
+// transaction, and runs the transactions that decode on an empty state.
+func FuzzUnpackArkivTransaction(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		atx, err := storagetx.UnpackArkivTransaction(data)
+		if err != nil {
+			return
+		}
+
+		// The decoded transaction must survive a round trip
+		encoded, err := rlp.EncodeToBytes(atx)
+		require.NoError(t, err)
+		again, err := storagetx.UnpackArkivTransaction(compression.MustBrotliCompress(encoded))
+		require.NoError(t, err)
+		require.Equal(t, atx, again)
+
+		h := newStateHarness(t)
+		for _, update := range atx.Update {
+			h.reference(update.EntityKey)
+		}
+		h.reference(atx.Delete...)
+		for _, extend := range atx.Extend {
+			h.reference(extend.EntityKey)
+		}
+		for _, changeOwner := range atx.ChangeOwner {
+			h.reference(changeOwner.EntityKey)
+		}
+
+		h.execute(data, fuzzSenders[0])
+		h.check()
+	})
+}
+
+// FuzzArkivStateTransitions interprets arbitrary bytes as a sequence of Arkiv
+// operations and new blocks, applies them to a state.StateDB, and checks the
+// invariants of the entity storage after each step.
+func FuzzArkivStateTransitions(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h := newStateHarness(t)
+		p := &opProgram{data: data, h: h}
+
+		for steps := 0; !p.done() && steps < 64; steps++ {
+			if p.next()%8 == 7 {
+				for n := p.next()%8 + 1; n > 0; n-- {
+					h.newBlock()
+				}
+			} else {
+				atx, sender := p.transaction()
+				h.execute(compression.MustBrotliCompress(mustEncode(t, atx)), sender)
+			}
+			h.check()
+		}
+	})
+}
+
+// opProgram turns fuzzer input into Arkiv transactions. Entity keys are picked
+// among the keys the harness has seen so far, or made up.
+type opProgram struct {
+	data []byte
+	h    *stateHarness
+}
+
+func (p *opProgram) done() bool {
+	return len(p.data) == 0
+}
+
+func (p *opProgram) next() byte {
+	if len(p.data) == 0 {
+		return 0
+	}
+	b := p.data[0]
+	p.data = p.data[1:]
+	return b
+}
+
+func (p *opProgram) bytes(n int) []byte {
+	n = min(n, len(p.data))
+	b := p.data[:n]
+	p.data = p.data[n:]
+	return b
+}
+
+func (p *opProgram) sender() common.Address {
+	return fuzzSenders[int(p.next())%len(fuzzSenders)]
+}
+
+func (p *opProgram) key() common.Hash {
+	b := p.next()
+	if int(b) < len(p.h.keys) {
+		return p.h.keys[b]
+	}
+	key := crypto.Keccak256Hash([]byte{b})
+	p.h.reference(key)
+	return key
+}
+
+func (p *opProgram) transaction() (*storagetx.ArkivTransaction, common.Address) {
+	atx := &storagetx.ArkivTransaction{}
+	sender := p.sender()
+
+	// Most transactions carry a single operation, some combine a few of them
+	// to exercise atomicity.
+	ops := 1
+	if b := p.next(); b >= 224 {
+		ops = int(b-224)%3 + 2
+	}
+
+	for range ops {
+		switch p.next() % 5 {
+		case 0:
+			atx.Create = append(atx.Create, storagetx.ArkivCreate{
+				BTL:         uint64(p.next()%32) + 1,
+				ContentType: "application/octet-stream",
+				Payload:     slices.Clone(p.bytes(int(p.next() % 8))),
+			})
+		case 1:
+			atx.Update = append(atx.Update, storagetx.ArkivUpdate{
+				EntityKey:   p.key(),
+				BTL:         uint64(p.next()%32) + 1,
+				ContentType: "application/octet-stream",
+				Payload:     slices.Clone(p.bytes(int(p.next() % 8))),
+			})
+		case 2:
+			atx.Delete = append(atx.Delete, p.key())
+		case 3:
+			atx.Extend = append(atx.Extend, storagetx.ExtendBTL{
+				EntityKey:      p.key(),
+				NumberOfBlocks: uint64(p.next()) + 1,
+			})
+		case 4:
+			atx.ChangeOwner = append(atx.ChangeOwner, storagetx.ArkivChangeOwner{
+				EntityKey: p.key(),
+				NewOwner:  p.sender(),
+			})
+		}
+	}
+
+	return atx, sender
+}
+
+func mustEncode(t *testing.T, atx *storagetx.ArkivTransaction) []byte {
+	encoded, err := rlp.EncodeToBytes(atx)
+	require.NoError(t, err)
+	return encoded
+}
+
+// stateHarness applies Arkiv transactions and housekeeping to a StateDB the way
+// block processing does, and remembers the entity keys, expiration buckets and
+// storage slots it has seen, so that the invariants can be checked.
+type stateHarness struct {
+	t       *testing.T
+	statedb *state.StateDB
+
+	block uint64
+	txs   uint64
+
+	keys    []common.Hash
+	known   map[common.Hash]bool
+	buckets map[uint64]bool
+	slots   *slotRecorder
+}
+
+// arkivRules are the rules of a chain with all the Arkiv forks active.
+var arkivRules = params.AllDevChainProtocolChanges.Rules(common.Big0, true, 0)
+
+func newStateHarness(t *testing.T) *stateHarness {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	return &stateHarness{
+		t:       t,
+		statedb: statedb,
+		block:   1,
+		known:   map[common.Hash]bool{},
+		buckets: map[uint64]bool{},
+		slots:   &slotRecorder{StateDB: statedb, slots: map[common.Hash]bool{}},
+	}
+}
+
+func (h *stateHarness) reference(keys ...common.Hash) {
+	for _, key := range keys {
+		if !h.known[key] {
+			h.known[key] = true
+			h.keys = append(h.keys, key)
+		}
+	}
+}
+
+// execute runs a compressed Arkiv transaction in the current block. A failed
+// transaction is reverted, as a failed transaction in a block would be.
+func (h *stateHarness) execute(compressed []byte, sender common.Address) {
+	h.txs++
+	txHash := common.BigToHash(new(big.Int).SetUint64(h.txs))
+
+	if atx, err := storagetx.UnpackArkivTransaction(compressed); err == nil {
+		for opIx, create := range atx.Create {
+			h.reference(storagetx.EntityKey(txHash, create.Payload, opIx))
+		}
+	}
+
+	snapshot := h.statedb.Snapshot()
+	_, err := storagetx.ExecuteArkivTransaction(compressed, arkivRules, h.block, txHash, 0, sender, nil, h.slots)
+	if err != nil {
+		h.statedb.RevertToSnapshot(snapshot)
+	}
+}
+
+// newBlock moves to the next block and expires the entities of that block.
+// Housekeeping only clears slots that an earlier transaction has written, so
+// it doesn't need to go through the slot recorder.
+func (h *stateHarness) newBlock() {
+	h.block++
+	h.txs++
+	_, err := housekeepingtx.ExecuteTransaction(arkivRules, h.block, common.BigToHash(new(big.Int).SetUint64(h.txs)), h.statedb)
+	require.NoError(h.t, err)
+}
+
+// check verifies that
+//   - every live entity sits in exactly one expiration bucket, the one of its
+//     expiration block,
+//   - the array and the map of every expiration keyset agree,
+//   - the UsedSlotsKey counter equals the number of non-zero slots, and
+//   - deleted entities leave no slots behind, so that all non-zero slots belong
+//     to a live entity or to a non-empty expiration bucket.
+func (h *stateHarness) check() {
+	t := h.t
+
+	live := map[common.Hash]uint64{}
+	for _, key := range h.keys {
+		md, err := entity.GetEntityMetaData(h.statedb, key)
+		require.NoError(t, err)
+		if md.Marshal() != (common.Hash{}) {
+			live[key] = md.ExpiresAtBlock
+			h.buckets[md.ExpiresAtBlock] = true
+		}
+	}
+
+	expectedSlots := len(live)
+	inBucket := map[common.Hash]uint64{}
+
+	for block := range h.buckets {
+		setKey := crypto.Keccak256Hash(entityexpiration.BlockExpirationSalt, uint256.NewInt(block).Bytes())
+		m := hashmap.NewMap(h.statedb, keyset.MapKeyPrefix, setKey[:])
+
+		members := slices.Collect(entityexpiration.IteratorOfEntitiesToExpireAtBlock(h.statedb, block))
+		require.Equal(t, uint64(len(members)), keyset.Size(h.statedb, setKey).Uint64(), "size of bucket %d", block)
+
+		for i, member := range members {
+			require.Equal(t, uint256.NewInt(uint64(i+1)).Bytes32(), [32]byte(m.Get(member)), "map entry of %s in bucket %d", member.Hex(), block)
+
+			expiresAt, ok := live[member]
+			require.True(t, ok, "bucket %d holds %s which is not a live entity", block, member.Hex())
+			require.Equal(t, expiresAt, block, "bucket %d holds %s which expires at %d", block, member.Hex(), expiresAt)
+
+			_, seen := inBucket[member]
+			require.False(t, seen, "%s is in bucket %d and %d", member.Hex(), block, inBucket[member])
+			inBucket[member] = block
+		}
+
+		for _, key := range h.keys {
+			if !slices.Contains(members, key) {
+				require.Equal(t, common.Hash{}, m.Get(key), "bucket %d has a map entry for %s", block, key.Hex())
+			}
+		}
+
+		if len(members) > 0 {
+			// the size, and an array element and a map entry per member
+			expectedSlots += 1 + 2*len(members)
+		}
+	}
+
+	for key, expiresAt := range live {
+		_, ok := inBucket[key]
+		require.True(t, ok, "live entity %s is not in bucket %d", key.Hex(), expiresAt)
+	}
+
+	nonZero := 0
+	for slot := range h.slots.slots {
+		if slot != storageaccounting.UsedSlotsKey && h.statedb.GetState(address.ArkivProcessorAddress, slot) != (common.Hash{}) {
+			nonZero++
+		}
+	}
+
+	require.Equal(t, uint64(nonZero), storageaccounting.GetNumberOfUsedSlots(h.statedb).Uint64(), "used slots counter")
+	require.Equal(t, expectedSlots, nonZero, "slots not accounted for by live entities and expiration buckets")
+}
+
+// slotRecorder records the storage slots of the processor address that are
+// written through it.
+type slotRecorder struct {
+	storageutil.StateDB
+	slots map[common.Hash]bool
+}
+
+func (r *slotRecorder) SetState(addr common.Address, key common.Hash, value common.Hash) common.Hash {
+	if addr == address.ArkivProcessorAddress {
+		r.slots[key] = true
+	}
+	return r.StateDB.SetState(addr, key, value)
+}
@@ -0,0 +1,115 @@
+package storagetx_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnpackArkivTransactionRenewalFork checks that renewals only decode since
+// the renewal fork, even as an empty list, as the decoders before the fork
+// reject the extra field.
+func TestUnpackArkivTransactionRenewalFork(t *testing.T) {
+	var (
+		before = params.Rules{}
+		after  = params.Rules{IsArkivRenewal: true}
+		keys   = []common.Hash{common.HexToHash("0x01")}
+	)
+
+	legacy, err := rlp.EncodeToBytes([]any{[]any{}, []any{}, keys, []any{}, []any{}})
+	require.NoError(t, err)
+	emptyRenew, err := rlp.EncodeToBytes([]any{[]any{}, []any{}, keys, []any{}, []any{}, []any{}})
+	require.NoError(t, err)
+	renew, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{
+		Delete: keys,
+		Renew:  []storagetx.ArkivRenew{{EntityKey: keys[0], Period: 10, Deposit: uint256.NewInt(1)}},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name    string
+		encoded []byte
+		rules   params.Rules
+		err     error
+	}{
+		{name: "legacy before", encoded: legacy, rules: before},
+		{name: "legacy after", encoded: legacy, rules: after},
+		{name: "empty renew before", encoded: emptyRenew, rules: before, err: storagetx.ErrRenewalNotActive},
+		{name: "empty renew after", encoded: emptyRenew, rules: after},
+		{name: "renew before", encoded: renew, rules: before, err: storagetx.ErrRenewalNotActive},
+		{name: "renew after", encoded: renew, rules: after},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _, err := storagetx.UnpackArkivTransactionForRules(compression.MustBrotliCompress(tt.encoded), tt.rules)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, keys, tx.Delete)
+		})
+	}
+}
+
+// TestUnpackArkivTransactionTypedAnnotationsFork checks that creates and
+// updates only decode with typed annotations since the typed annotations fork,
+// even as empty lists, as the decoders before the fork reject the extra fields.
+func TestUnpackArkivTransactionTypedAnnotationsFork(t *testing.T) {
+	var (
+		before = params.Rules{}
+		after  = params.Rules{IsArkivTypedAnnotations: true}
+		key    = common.HexToHash("0x01")
+	)
+
+	encode := func(create, update []any) []byte {
+		encoded, err := rlp.EncodeToBytes([]any{[]any{create}, []any{update}, []any{}, []any{}, []any{}})
+		require.NoError(t, err)
+		return encoded
+	}
+	legacyCreate := []any{uint64(1), "text/plain", []byte("a"), []any{}, []any{}}
+	legacyUpdate := []any{key, "text/plain", uint64(1), []byte("a"), []any{}, []any{}}
+
+	legacy := encode(legacyCreate, legacyUpdate)
+	emptyTypedCreate := encode(append(legacyCreate, []any{}), legacyUpdate)
+	emptyTypedUpdate := encode(legacyCreate, append(legacyUpdate, []any{}))
+	typed, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:             1,
+			ContentType:     "text/plain",
+			BoolAnnotations: []storagetx.BoolAnnotation{{Key: "flag", Value: true}},
+		}},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name    string
+		encoded []byte
+		rules   params.Rules
+		err     error
+	}{
+		{name: "legacy before", encoded: legacy, rules: before},
+		{name: "legacy after", encoded: legacy, rules: after},
+		{name: "empty typed create before", encoded: emptyTypedCreate, rules: before, err: storagetx.ErrTypedAnnotationsNotActive},
+		{name: "empty typed create after", encoded: emptyTypedCreate, rules: after},
+		{name: "empty typed update before", encoded: emptyTypedUpdate, rules: before, err: storagetx.ErrTypedAnnotationsNotActive},
+		{name: "empty typed update after", encoded: emptyTypedUpdate, rules: after},
+		{name: "typed before", encoded: typed, rules: before, err: storagetx.ErrTypedAnnotationsNotActive},
+		{name: "typed after", encoded: typed, rules: after},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _, err := storagetx.UnpackArkivTransactionForRules(compression.MustBrotliCompress(tt.encoded), tt.rules)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, tx.Create, 1)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package storagetx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrBlockLimitExceeded is returned if the Arkiv transactions executed in a block
+// exceed the Arkiv block limits of the chain config.
+var ErrBlockLimitExceeded = errors.New("arkiv block limit exceeded")
+
+// OperationCount returns the number of operations in the transaction.
+func (tx *ArkivTransaction) OperationCount() int {
+	return len(tx.Create) + len(tx.Update) + len(tx.Delete) + len(tx.Extend) + len(tx.ChangeOwner) + len(tx.Renew)
+}
+
+// BlockUsage is the load that executed Arkiv transactions put on the indexers.
+type BlockUsage struct {
+	PayloadBytes uint64 // Size of the decompressed payloads
+	Ops          uint64 // Number of operations
+}
+
+// Add returns the sum of both usages.
+func (u BlockUsage) Add(other BlockUsage) BlockUsage {
+	return BlockUsage{
+		PayloadBytes: u.PayloadBytes + other.PayloadBytes,
+		Ops:          u.Ops + other.Ops,
+	}
+}
+
+// CheckLimits returns an error if the usage exceeds the Arkiv block limits of
+// config. A nil config or a zero limit doesn't limit the usage.
+func (u BlockUsage) CheckLimits(config *params.ArkivConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.MaxBlockPayloadBytes != 0 && u.PayloadBytes > config.MaxBlockPayloadBytes {
+		return fmt.Errorf("%w: %d decompressed payload bytes, limit %d", ErrBlockLimitExceeded, u.PayloadBytes, config.MaxBlockPayloadBytes)
+	}
+	if config.MaxBlockOps != 0 && u.Ops > config.MaxBlockOps {
+		return fmt.Errorf("%w: %d operations, limit %d", ErrBlockLimitExceeded, u.Ops, config.MaxBlockOps)
+	}
+	return nil
+}
+
+// PayloadUsage returns the usage of a compressed Arkiv transaction. A payload
+// that can't be decoded is never executed and doesn't use anything.
+func PayloadUsage(compressed []byte) BlockUsage {
+	tx, size, err := UnpackArkivTransactionWithSize(compressed)
+	if err != nil {
+		return BlockUsage{}
+	}
+	return BlockUsage{
+		PayloadBytes: uint64(size),
+		Ops:          uint64(tx.OperationCount()),
+	}
+}
+
+// ReceiptUsage returns the usage of the Arkiv transactions executed by tx: tx
+// itself if it is sent to the processor and succeeded, and the calls issued by
+// contracts, which are recorded in the receipt by their marker logs.
+//
+// Deposit transactions don't use anything, as they are forced into the block by
+// L1 and a sequencer can't leave them out to stay within the limits.
+func ReceiptUsage(tx *types.Transaction, receipt *types.Receipt) BlockUsage {
+	if tx.IsDepositTx() || receipt.Status != types.ReceiptStatusSuccessful {
+		return BlockUsage{}
+	}
+	return ExecutionUsage(tx.To(), tx.Data(), receipt.Logs)
+}
+
+// ExecutionUsage returns the usage of the Arkiv transactions executed by a
+// successful transaction to the recipient to with the given data, which emitted
+// logs. It is the counterpart of ReceiptUsage before the receipt is made.
+func ExecutionUsage(to *common.Address, data []byte, logs []*types.Log) BlockUsage {
+	var usage BlockUsage
+	if to != nil && *to == address.ArkivProcessorAddress {
+		usage = usage.Add(PayloadUsage(data))
+	}
+	for _, l := range logs {
+		if l.Address == address.ArkivProcessorAddress && len(l.Topics) > 0 && l.Topics[0] == arkivlogs.ArkivContractCall {
+			usage = usage.Add(PayloadUsage(l.Data))
+		}
+	}
+	return usage
+}
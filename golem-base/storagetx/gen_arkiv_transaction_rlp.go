@@ -30,56 +30,182 @@ func (obj *ArkivTransaction) EncodeRLP(_w io.Writer) error {
 			w.ListEnd(_tmp9)
 		}
 		w.ListEnd(_tmp7)
-		w.ListEnd(_tmp3)
-	}
-	w.ListEnd(_tmp1)
-	_tmp10 := w.List()
-	for _, _tmp11 := range obj.Update {
-		_tmp12 := w.List()
-		w.WriteBytes(_tmp11.EntityKey[:])
-		w.WriteString(_tmp11.ContentType)
-		w.WriteUint64(_tmp11.BTL)
-		w.WriteBytes(_tmp11.Payload)
-		_tmp13 := w.List()
-		for _, _tmp14 := range _tmp11.StringAnnotations {
+		_tmp10 := len(_tmp2.IntAnnotations) > 0
+		_tmp11 := len(_tmp2.DecimalAnnotations) > 0
+		_tmp12 := len(_tmp2.BoolAnnotations) > 0
+		_tmp13 := len(_tmp2.AddressAnnotations) > 0
+		_tmp14 := len(_tmp2.Bytes32Annotations) > 0
+		if _tmp10 || _tmp11 || _tmp12 || _tmp13 || _tmp14 {
 			_tmp15 := w.List()
-			w.WriteString(_tmp14.Key)
-			w.WriteString(_tmp14.Value)
+			for _, _tmp16 := range _tmp2.IntAnnotations {
+				_tmp17 := w.List()
+				w.WriteString(_tmp16.Key)
+				w.WriteString(_tmp16.Value)
+				w.ListEnd(_tmp17)
+			}
 			w.ListEnd(_tmp15)
 		}
-		w.ListEnd(_tmp13)
-		_tmp16 := w.List()
-		for _, _tmp17 := range _tmp11.NumericAnnotations {
+		if _tmp11 || _tmp12 || _tmp13 || _tmp14 {
 			_tmp18 := w.List()
-			w.WriteString(_tmp17.Key)
-			w.WriteUint64(_tmp17.Value)
+			for _, _tmp19 := range _tmp2.DecimalAnnotations {
+				_tmp20 := w.List()
+				w.WriteString(_tmp19.Key)
+				w.WriteString(_tmp19.Value)
+				w.ListEnd(_tmp20)
+			}
 			w.ListEnd(_tmp18)
 		}
-		w.ListEnd(_tmp16)
-		w.ListEnd(_tmp12)
+		if _tmp12 || _tmp13 || _tmp14 {
+			_tmp21 := w.List()
+			for _, _tmp22 := range _tmp2.BoolAnnotations {
+				_tmp23 := w.List()
+				w.WriteString(_tmp22.Key)
+				w.WriteBool(_tmp22.Value)
+				w.ListEnd(_tmp23)
+			}
+			w.ListEnd(_tmp21)
+		}
+		if _tmp13 || _tmp14 {
+			_tmp24 := w.List()
+			for _, _tmp25 := range _tmp2.AddressAnnotations {
+				_tmp26 := w.List()
+				w.WriteString(_tmp25.Key)
+				w.WriteBytes(_tmp25.Value[:])
+				w.ListEnd(_tmp26)
+			}
+			w.ListEnd(_tmp24)
+		}
+		if _tmp14 {
+			_tmp27 := w.List()
+			for _, _tmp28 := range _tmp2.Bytes32Annotations {
+				_tmp29 := w.List()
+				w.WriteString(_tmp28.Key)
+				w.WriteBytes(_tmp28.Value[:])
+				w.ListEnd(_tmp29)
+			}
+			w.ListEnd(_tmp27)
+		}
+		w.ListEnd(_tmp3)
+	}
+	w.ListEnd(_tmp1)
+	_tmp30 := w.List()
+	for _, _tmp31 := range obj.Update {
+		_tmp32 := w.List()
+		w.WriteBytes(_tmp31.EntityKey[:])
+		w.WriteString(_tmp31.ContentType)
+		w.WriteUint64(_tmp31.BTL)
+		w.WriteBytes(_tmp31.Payload)
+		_tmp33 := w.List()
+		for _, _tmp34 := range _tmp31.StringAnnotations {
+			_tmp35 := w.List()
+			w.WriteString(_tmp34.Key)
+			w.WriteString(_tmp34.Value)
+			w.ListEnd(_tmp35)
+		}
+		w.ListEnd(_tmp33)
+		_tmp36 := w.List()
+		for _, _tmp37 := range _tmp31.NumericAnnotations {
+			_tmp38 := w.List()
+			w.WriteString(_tmp37.Key)
+			w.WriteUint64(_tmp37.Value)
+			w.ListEnd(_tmp38)
+		}
+		w.ListEnd(_tmp36)
+		_tmp39 := len(_tmp31.IntAnnotations) > 0
+		_tmp40 := len(_tmp31.DecimalAnnotations) > 0
+		_tmp41 := len(_tmp31.BoolAnnotations) > 0
+		_tmp42 := len(_tmp31.AddressAnnotations) > 0
+		_tmp43 := len(_tmp31.Bytes32Annotations) > 0
+		if _tmp39 || _tmp40 || _tmp41 || _tmp42 || _tmp43 {
+			_tmp44 := w.List()
+			for _, _tmp45 := range _tmp31.IntAnnotations {
+				_tmp46 := w.List()
+				w.WriteString(_tmp45.Key)
+				w.WriteString(_tmp45.Value)
+				w.ListEnd(_tmp46)
+			}
+			w.ListEnd(_tmp44)
+		}
+		if _tmp40 || _tmp41 || _tmp42 || _tmp43 {
+			_tmp47 := w.List()
+			for _, _tmp48 := range _tmp31.DecimalAnnotations {
+				_tmp49 := w.List()
+				w.WriteString(_tmp48.Key)
+				w.WriteString(_tmp48.Value)
+				w.ListEnd(_tmp49)
+			}
+			w.ListEnd(_tmp47)
+		}
+		if _tmp41 || _tmp42 || _tmp43 {
+			_tmp50 := w.List()
+			for _, _tmp51 := range _tmp31.BoolAnnotations {
+				_tmp52 := w.List()
+				w.WriteString(_tmp51.Key)
+				w.WriteBool(_tmp51.Value)
+				w.ListEnd(_tmp52)
+			}
+			w.ListEnd(_tmp50)
+		}
+		if _tmp42 || _tmp43 {
+			_tmp53 := w.List()
+			for _, _tmp54 := range _tmp31.AddressAnnotations {
+				_tmp55 := w.List()
+				w.WriteString(_tmp54.Key)
+				w.WriteBytes(_tmp54.Value[:])
+				w.ListEnd(_tmp55)
+			}
+			w.ListEnd(_tmp53)
+		}
+		if _tmp43 {
+			_tmp56 := w.List()
+			for _, _tmp57 := range _tmp31.Bytes32Annotations {
+				_tmp58 := w.List()
+				w.WriteString(_tmp57.Key)
+				w.WriteBytes(_tmp57.Value[:])
+				w.ListEnd(_tmp58)
+			}
+			w.ListEnd(_tmp56)
+		}
+		w.ListEnd(_tmp32)
 	}
-	w.ListEnd(_tmp10)
-	_tmp19 := w.List()
-	for _, _tmp20 := range obj.Delete {
-		w.WriteBytes(_tmp20[:])
+	w.ListEnd(_tmp30)
+	_tmp59 := w.List()
+	for _, _tmp60 := range obj.Delete {
+		w.WriteBytes(_tmp60[:])
 	}
-	w.ListEnd(_tmp19)
-	_tmp21 := w.List()
-	for _, _tmp22 := range obj.Extend {
-		_tmp23 := w.List()
-		w.WriteBytes(_tmp22.EntityKey[:])
-		w.WriteUint64(_tmp22.NumberOfBlocks)
-		w.ListEnd(_tmp23)
+	w.ListEnd(_tmp59)
+	_tmp61 := w.List()
+	for _, _tmp62 := range obj.Extend {
+		_tmp63 := w.List()
+		w.WriteBytes(_tmp62.EntityKey[:])
+		w.WriteUint64(_tmp62.NumberOfBlocks)
+		w.ListEnd(_tmp63)
 	}
-	w.ListEnd(_tmp21)
-	_tmp24 := w.List()
-	for _, _tmp25 := range obj.ChangeOwner {
-		_tmp26 := w.List()
-		w.WriteBytes(_tmp25.EntityKey[:])
-		w.WriteBytes(_tmp25.NewOwner[:])
-		w.ListEnd(_tmp26)
+	w.ListEnd(_tmp61)
+	_tmp64 := w.List()
+	for _, _tmp65 := range obj.ChangeOwner {
+		_tmp66 := w.List()
+		w.WriteBytes(_tmp65.EntityKey[:])
+		w.WriteBytes(_tmp65.NewOwner[:])
+		w.ListEnd(_tmp66)
+	}
+	w.ListEnd(_tmp64)
+	_tmp67 := len(obj.Renew) > 0
+	if _tmp67 {
+		_tmp68 := w.List()
+		for _, _tmp69 := range obj.Renew {
+			_tmp70 := w.List()
+			w.WriteBytes(_tmp69.EntityKey[:])
+			w.WriteUint64(_tmp69.Period)
+			if _tmp69.Deposit == nil {
+				w.Write(rlp.EmptyString)
+			} else {
+				w.WriteUint256(_tmp69.Deposit)
+			}
+			w.ListEnd(_tmp70)
+		}
+		w.ListEnd(_tmp68)
 	}
-	w.ListEnd(_tmp24)
 	w.ListEnd(_tmp0)
 	return w.Flush()
 }
@@ -0,0 +1,14 @@
+package storagetx
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+// Metrics of executed Arkiv transactions. Executions include those of eth_call,
+// tracing and simulations; metrics of the canonical chain are reported by
+// arkiv/dbevents.
+var (
+	executedMeter     = metrics.NewRegisteredMeter("arkiv/storagetx/executed", nil)
+	failedMeter       = metrics.NewRegisteredMeter("arkiv/storagetx/failed", nil)
+	operationsMeter   = metrics.NewRegisteredMeter("arkiv/storagetx/operations", nil)
+	payloadBytesMeter = metrics.NewRegisteredMeter("arkiv/storagetx/payload", nil)
+	executionTimer    = metrics.NewRegisteredTimer("arkiv/storagetx/time", nil)
+)
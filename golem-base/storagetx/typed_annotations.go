@@ -0,0 +1,256 @@
+package storagetx
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
+)
+
+// DecimalScale is the number of fractional digits of decimal annotations.
+const DecimalScale = 18
+
+// decimalRegex matches the values of decimal annotations. The integer digits are
+// limited so that every value, scaled by DecimalScale, fits the index encoding.
+var decimalRegex = regexp.MustCompile(fmt.Sprintf(`^-?[0-9]{1,58}(\.[0-9]{1,%d})?$`, DecimalScale))
+
+// decimalOffset shifts the scaled decimals to non-negative numbers for the index
+// encoding.
+var decimalOffset = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// IntAnnotation is a signed 64 bit integer annotation. The value is in base 10,
+// as JSON numbers can't represent all 64 bit integers exactly.
+type IntAnnotation struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DecimalAnnotation is a fixed-point decimal annotation, with an optional sign
+// and up to DecimalScale fractional digits, such as "-12.5".
+type DecimalAnnotation struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type BoolAnnotation struct {
+	Key   string `json:"key"`
+	Value bool   `json:"value"`
+}
+
+type AddressAnnotation struct {
+	Key   string         `json:"key"`
+	Value common.Address `json:"value"`
+}
+
+type Bytes32Annotation struct {
+	Key   string      `json:"key"`
+	Value common.Hash `json:"value"`
+}
+
+// NewIntAnnotation returns the annotation of a signed integer.
+func NewIntAnnotation(key string, value int64) IntAnnotation {
+	return IntAnnotation{Key: key, Value: strconv.FormatInt(value, 10)}
+}
+
+// Int returns the value of the annotation.
+func (a IntAnnotation) Int() (int64, error) {
+	v, err := strconv.ParseInt(a.Value, 10, 64)
+	if err != nil || strconv.FormatInt(v, 10) != a.Value {
+		return 0, fmt.Errorf("invalid int annotation value %q", a.Value)
+	}
+	return v, nil
+}
+
+// Scaled returns the value of the annotation multiplied by 10^DecimalScale.
+func (a DecimalAnnotation) Scaled() (*big.Int, error) {
+	return scaleDecimal(a.Value)
+}
+
+func scaleDecimal(value string) (*big.Int, error) {
+	if !decimalRegex.MatchString(value) {
+		return nil, fmt.Errorf("invalid decimal annotation value %q", value)
+	}
+	integer, fraction, _ := strings.Cut(value, ".")
+	scaled, _ := new(big.Int).SetString(integer+fraction+strings.Repeat("0", DecimalScale-len(fraction)), 10)
+	return scaled, nil
+}
+
+// The store indexes every annotation either as a string or as a numeric
+// attribute. The typed annotations are encoded so that the order of the
+// attributes is the order of the values, which keeps range queries working.
+// The query values of the typed annotations have to be encoded the same way.
+// Numeric attributes are limited to 63 bits by the store, so signed integers
+// are indexed as strings, like decimals.
+
+// IntAttribute returns the string attribute of a signed integer: the integer
+// offset by 2^63, as 8 bytes of lowercase hex.
+func IntAttribute(v int64) string {
+	return fmt.Sprintf("0x%016x", uint64(v)^1<<63)
+}
+
+// BoolAttribute returns the numeric attribute of a bool: 1 for true, 0 for false.
+func BoolAttribute(v bool) uint64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// DecimalAttribute returns the string attribute of a decimal: the decimal scaled
+// by 10^DecimalScale and offset by 2^255, as 32 bytes of lowercase hex. Decimals
+// with trailing fractional zeros have the same attribute.
+func DecimalAttribute(v string) (string, error) {
+	scaled, err := scaleDecimal(v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%064x", scaled.Add(scaled, decimalOffset)), nil
+}
+
+// AddressAttribute returns the string attribute of an address, in lowercase hex.
+func AddressAttribute(v common.Address) string {
+	return hexutil.Encode(v[:])
+}
+
+// Bytes32Attribute returns the string attribute of a 32 byte value, in
+// lowercase hex.
+func Bytes32Attribute(v common.Hash) string {
+	return v.Hex()
+}
+
+// Annotations are the annotations of a create or update operation.
+type Annotations struct {
+	String  []StringAnnotation
+	Numeric []NumericAnnotation
+	Int     []IntAnnotation
+	Decimal []DecimalAnnotation
+	Bool    []BoolAnnotation
+	Address []AddressAnnotation
+	Bytes32 []Bytes32Annotation
+}
+
+func (c *ArkivCreate) Annotations() Annotations {
+	return Annotations{
+		String:  c.StringAnnotations,
+		Numeric: c.NumericAnnotations,
+		Int:     c.IntAnnotations,
+		Decimal: c.DecimalAnnotations,
+		Bool:    c.BoolAnnotations,
+		Address: c.AddressAnnotations,
+		Bytes32: c.Bytes32Annotations,
+	}
+}
+
+func (u *ArkivUpdate) Annotations() Annotations {
+	return Annotations{
+		String:  u.StringAnnotations,
+		Numeric: u.NumericAnnotations,
+		Int:     u.IntAnnotations,
+		Decimal: u.DecimalAnnotations,
+		Bool:    u.BoolAnnotations,
+		Address: u.AddressAnnotations,
+		Bytes32: u.Bytes32Annotations,
+	}
+}
+
+// Validate checks the keys and values of the annotations. As the annotations
+// are indexed as string or numeric attributes, a key can only be used once by
+// the string, int, decimal, address and bytes32 annotations, and once by the
+// numeric and bool annotations.
+func (a Annotations) Validate() error {
+	seenString := make(map[string]bool)
+	seenNumeric := make(map[string]bool)
+
+	check := func(kind, key string, seen map[string]bool) error {
+		if !entity.AnnotationIdentRegexCompiled.MatchString(key) {
+			return fmt.Errorf("invalid annotation identifier (must match `%s`): %s",
+				entity.AnnotationIdentRegexCompiled.String(),
+				key,
+			)
+		}
+		if seen[key] {
+			return fmt.Errorf("%s annotation key %s is duplicated", kind, key)
+		}
+		seen[key] = true
+		return nil
+	}
+
+	for _, annotation := range a.String {
+		if err := check("string", annotation.Key, seenString); err != nil {
+			return err
+		}
+	}
+	for _, annotation := range a.Int {
+		if err := check("int", annotation.Key, seenString); err != nil {
+			return err
+		}
+		if _, err := annotation.Int(); err != nil {
+			return err
+		}
+	}
+	for _, annotation := range a.Decimal {
+		if err := check("decimal", annotation.Key, seenString); err != nil {
+			return err
+		}
+		if _, err := annotation.Scaled(); err != nil {
+			return err
+		}
+	}
+	for _, annotation := range a.Address {
+		if err := check("address", annotation.Key, seenString); err != nil {
+			return err
+		}
+	}
+	for _, annotation := range a.Bytes32 {
+		if err := check("bytes32", annotation.Key, seenString); err != nil {
+			return err
+		}
+	}
+	for _, annotation := range a.Numeric {
+		if err := check("numeric", annotation.Key, seenNumeric); err != nil {
+			return err
+		}
+	}
+	for _, annotation := range a.Bool {
+		if err := check("bool", annotation.Key, seenNumeric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attributes returns the string and numeric attributes the annotations are
+// indexed with. The annotations have to be valid.
+func (a Annotations) Attributes() (map[string]string, map[string]uint64) {
+	stringAttributes := make(map[string]string)
+	numericAttributes := make(map[string]uint64)
+
+	for _, annotation := range a.String {
+		stringAttributes[annotation.Key] = annotation.Value
+	}
+	for _, annotation := range a.Int {
+		v, _ := annotation.Int()
+		stringAttributes[annotation.Key] = IntAttribute(v)
+	}
+	for _, annotation := range a.Decimal {
+		stringAttributes[annotation.Key], _ = DecimalAttribute(annotation.Value)
+	}
+	for _, annotation := range a.Address {
+		stringAttributes[annotation.Key] = AddressAttribute(annotation.Value)
+	}
+	for _, annotation := range a.Bytes32 {
+		stringAttributes[annotation.Key] = Bytes32Attribute(annotation.Value)
+	}
+	for _, annotation := range a.Numeric {
+		numericAttributes[annotation.Key] = annotation.Value
+	}
+	for _, annotation := range a.Bool {
+		numericAttributes[annotation.Key] = BoolAttribute(annotation.Value)
+	}
+	return stringAttributes, numericAttributes
+}
@@ -0,0 +1,200 @@
+package storagetx_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTypedAnnotationAttributesOrder checks that the attributes of the typed
+// annotations are ordered like their values, as the store compares them.
+func TestTypedAnnotationAttributesOrder(t *testing.T) {
+	ints := []int64{math.MinInt64, -1 << 40, -2, -1, 0, 1, 2, 1 << 40, math.MaxInt64}
+	for i := 1; i < len(ints); i++ {
+		require.Less(t, storagetx.IntAttribute(ints[i-1]), storagetx.IntAttribute(ints[i]))
+	}
+
+	decimals := []string{
+		"-9999999999999999999999999999999999999999999999999999999999.999999999999999999",
+		"-100", "-1.5", "-1.000000000000000001", "-1", "-0.5", "0", "0.000000000000000001", "0.5", "1", "1.5", "100",
+		"9999999999999999999999999999999999999999999999999999999999.999999999999999999",
+	}
+	for i := 1; i < len(decimals); i++ {
+		prev, err := storagetx.DecimalAttribute(decimals[i-1])
+		require.NoError(t, err)
+		next, err := storagetx.DecimalAttribute(decimals[i])
+		require.NoError(t, err)
+		require.Less(t, prev, next, "%s < %s", decimals[i-1], decimals[i])
+	}
+
+	// The same decimal has the same attribute, however it is written
+	a, _ := storagetx.DecimalAttribute("1.5")
+	b, _ := storagetx.DecimalAttribute("1.500")
+	c, _ := storagetx.DecimalAttribute("-0")
+	d, _ := storagetx.DecimalAttribute("0")
+	require.Equal(t, a, b)
+	require.Equal(t, c, d)
+
+	require.Less(t, storagetx.BoolAttribute(false), storagetx.BoolAttribute(true))
+	require.Less(t,
+		storagetx.AddressAttribute(common.HexToAddress("0x0f")),
+		storagetx.AddressAttribute(common.HexToAddress("0xA0")),
+	)
+}
+
+func TestTypedAnnotationsValidate(t *testing.T) {
+	create := func(annotations func(c *storagetx.ArkivCreate)) *storagetx.ArkivTransaction {
+		c := storagetx.ArkivCreate{BTL: 1, ContentType: "text/plain"}
+		annotations(&c)
+		return &storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{c}}
+	}
+
+	for _, tt := range []struct {
+		name string
+		tx   *storagetx.ArkivTransaction
+		err  string
+	}{
+		{
+			name: "all kinds",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.StringAnnotations = []storagetx.StringAnnotation{{Key: "a", Value: "x"}}
+				c.NumericAnnotations = []storagetx.NumericAnnotation{{Key: "a", Value: 1}}
+				c.IntAnnotations = []storagetx.IntAnnotation{storagetx.NewIntAnnotation("b", -1)}
+				c.BoolAnnotations = []storagetx.BoolAnnotation{{Key: "b", Value: false}}
+				c.DecimalAnnotations = []storagetx.DecimalAnnotation{{Key: "c", Value: "-0.25"}}
+				c.AddressAnnotations = []storagetx.AddressAnnotation{{Key: "e", Value: common.Address{1}}}
+				c.Bytes32Annotations = []storagetx.Bytes32Annotation{{Key: "f", Value: common.Hash{1}}}
+			}),
+		},
+		{
+			name: "string and decimal",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.StringAnnotations = []storagetx.StringAnnotation{{Key: "a", Value: "x"}}
+				c.DecimalAnnotations = []storagetx.DecimalAnnotation{{Key: "a", Value: "1"}}
+			}),
+			err: "create[0] decimal annotation key a is duplicated",
+		},
+		{
+			name: "string and int",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.StringAnnotations = []storagetx.StringAnnotation{{Key: "a", Value: "x"}}
+				c.IntAnnotations = []storagetx.IntAnnotation{storagetx.NewIntAnnotation("a", 1)}
+			}),
+			err: "create[0] int annotation key a is duplicated",
+		},
+		{
+			name: "numeric and bool",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.NumericAnnotations = []storagetx.NumericAnnotation{{Key: "a", Value: 1}}
+				c.BoolAnnotations = []storagetx.BoolAnnotation{{Key: "a", Value: true}}
+			}),
+			err: "create[0] bool annotation key a is duplicated",
+		},
+		{
+			name: "invalid identifier",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.AddressAnnotations = []storagetx.AddressAnnotation{{Key: "1a"}}
+			}),
+			err: "invalid annotation identifier",
+		},
+		{
+			name: "int overflow",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.IntAnnotations = []storagetx.IntAnnotation{{Key: "a", Value: "9223372036854775808"}}
+			}),
+			err: "invalid int annotation value",
+		},
+		{
+			name: "non-canonical int",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.IntAnnotations = []storagetx.IntAnnotation{{Key: "a", Value: "+1"}}
+			}),
+			err: "invalid int annotation value",
+		},
+		{
+			name: "too many fractional digits",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.DecimalAnnotations = []storagetx.DecimalAnnotation{{Key: "a", Value: "0.0000000000000000001"}}
+			}),
+			err: "invalid decimal annotation value",
+		},
+		{
+			name: "exponent",
+			tx: create(func(c *storagetx.ArkivCreate) {
+				c.DecimalAnnotations = []storagetx.DecimalAnnotation{{Key: "a", Value: "1e3"}}
+			}),
+			err: "invalid decimal annotation value",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tx.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+// TestTypedAnnotationsEncoding checks that transactions without typed
+// annotations encode as before, and that typed annotations survive a round trip.
+func TestTypedAnnotationsEncoding(t *testing.T) {
+	type legacyCreate struct {
+		BTL                uint64
+		ContentType        string
+		Payload            []byte
+		StringAnnotations  []storagetx.StringAnnotation
+		NumericAnnotations []storagetx.NumericAnnotation
+	}
+	create := storagetx.ArkivCreate{
+		BTL:                10,
+		ContentType:        "text/plain",
+		Payload:            []byte("hello"),
+		StringAnnotations:  []storagetx.StringAnnotation{{Key: "a", Value: "x"}},
+		NumericAnnotations: []storagetx.NumericAnnotation{{Key: "b", Value: 1}},
+	}
+	encoded, err := rlp.EncodeToBytes(&storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{create}})
+	require.NoError(t, err)
+	legacy, err := rlp.EncodeToBytes([]interface{}{
+		[]legacyCreate{{create.BTL, create.ContentType, create.Payload, create.StringAnnotations, create.NumericAnnotations}},
+		[]interface{}{}, []interface{}{}, []interface{}{}, []interface{}{},
+	})
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(legacy, encoded))
+
+	tx := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:                10,
+			ContentType:        "text/plain",
+			Payload:            []byte("hello"),
+			StringAnnotations:  []storagetx.StringAnnotation{},
+			NumericAnnotations: []storagetx.NumericAnnotation{},
+			BoolAnnotations:    []storagetx.BoolAnnotation{{Key: "done", Value: true}},
+		}},
+		Update: []storagetx.ArkivUpdate{{
+			EntityKey:          common.Hash{1},
+			ContentType:        "text/plain",
+			BTL:                10,
+			StringAnnotations:  []storagetx.StringAnnotation{},
+			NumericAnnotations: []storagetx.NumericAnnotation{},
+			IntAnnotations:     []storagetx.IntAnnotation{storagetx.NewIntAnnotation("temp", -5)},
+			DecimalAnnotations: []storagetx.DecimalAnnotation{{Key: "price", Value: "1.5"}},
+			BoolAnnotations:    []storagetx.BoolAnnotation{},
+			AddressAnnotations: []storagetx.AddressAnnotation{},
+			Bytes32Annotations: []storagetx.Bytes32Annotation{{Key: "hash", Value: common.Hash{2}}},
+		}},
+	}
+	encoded, err = rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+
+	var decoded storagetx.ArkivTransaction
+	require.NoError(t, rlp.DecodeBytes(encoded, &decoded))
+	require.Equal(t, tx.Create[0].BoolAnnotations, decoded.Create[0].BoolAnnotations)
+	require.Equal(t, tx.Update[0].Annotations(), decoded.Update[0].Annotations())
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CheckConditions validates the entity preconditions of a conditional
+// transaction against the state.
+func CheckConditions(access StateAccess, conditions []types.ArkivEntityCondition) error {
+	for _, cond := range conditions {
+		md, err := GetEntityMetaData(access, cond.Key)
+		if err != nil {
+			return fmt.Errorf("failed to get entity meta data for %s: %w", cond.Key.Hex(), err)
+		}
+
+		// The metadata of every existing entity has an owner and an expiration
+		exists := md.ExpiresAtBlock != 0
+		if cond.Exists != nil && *cond.Exists != exists {
+			if exists {
+				return fmt.Errorf("failed entity %s constraint: entity exists", cond.Key.Hex())
+			}
+			return fmt.Errorf("failed entity %s constraint: entity does not exist", cond.Key.Hex())
+		}
+		if (cond.Owner != nil || cond.ExpiresAfter != nil) && !exists {
+			return fmt.Errorf("failed entity %s constraint: entity does not exist", cond.Key.Hex())
+		}
+		if cond.Owner != nil && md.Owner != *cond.Owner {
+			return fmt.Errorf("failed entity %s owner constraint. Got %s, Expected %s", cond.Key.Hex(), md.Owner.Hex(), cond.Owner.Hex())
+		}
+		if cond.ExpiresAfter != nil && md.ExpiresAtBlock <= *cond.ExpiresAfter {
+			return fmt.Errorf("failed entity %s expiration constraint. Expires at %d, Expected after %d", cond.Key.Hex(), md.ExpiresAtBlock, *cond.ExpiresAfter)
+		}
+	}
+	return nil
+}
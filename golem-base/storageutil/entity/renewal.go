@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/holiman/uint256"
+)
+
+var EntityRenewalSalt = []byte("arkivEntityRenewal")
+
+// RenewalFeePerBlock is the fee in wei that housekeeping deducts from the
+// renewal budget of an entity for every block it extends the entity by.
+var RenewalFeePerBlock = uint256.NewInt(1_000_000_000)
+
+// MaxRenewalBudget is the largest renewal budget an entity can have, as the
+// budget is stored in the 24 bytes of the renewal slot after the period.
+var MaxRenewalBudget = new(uint256.Int).SubUint64(new(uint256.Int).Lsh(uint256.NewInt(1), 192), 1)
+
+// EntityRenewal is the automatic renewal of an entity. When the entity expires
+// and the budget covers the fee of the period, housekeeping extends the entity
+// by the period instead of deleting it, and deducts the fee from the budget.
+// A period of 0 disables the renewal and keeps the budget.
+type EntityRenewal struct {
+	Period uint64      `json:"period"`
+	Budget uint256.Int `json:"budget"`
+}
+
+func (r *EntityRenewal) Marshal() common.Hash {
+	bytes := r.Budget.Bytes32()
+	binary.BigEndian.PutUint64(bytes[:8], r.Period)
+	return common.Hash(bytes)
+}
+
+func (r *EntityRenewal) Unmarshal(hash common.Hash) {
+	r.Period = binary.BigEndian.Uint64(hash[:8])
+	r.Budget.SetBytes(hash[8:])
+}
+
+// Fee returns the fee of extending the entity by the renewal period.
+func (r *EntityRenewal) Fee() *uint256.Int {
+	return new(uint256.Int).Mul(RenewalFeePerBlock, uint256.NewInt(r.Period))
+}
+
+// Renews reports whether housekeeping renews the entity when it expires, which
+// takes a period and a budget covering its fee.
+func (r *EntityRenewal) Renews() bool {
+	return r.Period != 0 && !r.Budget.Lt(r.Fee())
+}
+
+func GetEntityRenewal(access StateAccess, key common.Hash) *EntityRenewal {
+	value := access.GetState(address.ArkivProcessorAddress, crypto.Keccak256Hash(EntityRenewalSalt, key[:]))
+
+	r := &EntityRenewal{}
+	r.Unmarshal(value)
+
+	return r
+}
+
+// StoreEntityRenewal stores the renewal of an entity. Storing a renewal without
+// a period and a budget clears its slot.
+func StoreEntityRenewal(access StateAccess, key common.Hash, r EntityRenewal) {
+	access.SetState(
+		address.ArkivProcessorAddress,
+		crypto.Keccak256Hash(EntityRenewalSalt, key[:]),
+		r.Marshal(),
+	)
+}
+
+func DeleteEntityRenewal(access StateAccess, key common.Hash) {
+	access.SetState(
+		address.ArkivProcessorAddress,
+		crypto.Keccak256Hash(EntityRenewalSalt, key[:]),
+		common.Hash{},
+	)
+}
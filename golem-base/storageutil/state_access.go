@@ -2,9 +2,19 @@ package storageutil
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/holiman/uint256"
 )
 
 type StateAccess interface {
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash) common.Hash
 }
+
+// StateDB is the state the Arkiv transactions are executed on. Besides the
+// storage of the processor, the execution burns the renewal budgets forfeited
+// by deleted entities from the balance of the processor.
+type StateDB interface {
+	StateAccess
+	SubBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -22,6 +24,12 @@ var (
 	sendRawTxConditionalAcceptedCounter = metrics.NewRegisteredCounter("sequencer/sendRawTransactionConditional/accepted", nil)
 )
 
+// arkivQueryChecker is implemented by backends that can check the query
+// preconditions of conditional transactions against the Arkiv store.
+type arkivQueryChecker interface {
+	CheckArkivQueryConditions(ctx context.Context, number uint64, conditions []types.ArkivQueryCondition) error
+}
+
 type sendRawTxCond struct {
 	b           ethapi.Backend
 	seqRPC      *rpc.Client
@@ -75,6 +83,10 @@ func (s *sendRawTxCond) SendRawTransactionConditional(ctx context.Context, txByt
 		}
 	}
 
+	if err := s.checkArkivConditional(ctx, state, header, cond.Arkiv); err != nil {
+		return common.Hash{}, err
+	}
+
 	// State is checked against an older block to remove the MEV incentive for this endpoint compared with sendRawTransaction
 	parentBlock := rpc.BlockNumberOrHash{BlockHash: &header.ParentHash}
 	parentState, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, parentBlock)
@@ -87,6 +99,14 @@ func (s *sendRawTxCond) SendRawTransactionConditional(ctx context.Context, txByt
 			Code:    params.TransactionConditionalRejectedErrCode,
 		}
 	}
+	if cond.Arkiv != nil {
+		if err := entity.CheckConditions(parentState, cond.Arkiv.Entities); err != nil {
+			return common.Hash{}, &rpc.JsonError{
+				Message: fmt.Sprintf("failed parent block %s arkiv entity check: %s", header.ParentHash, err),
+				Code:    params.TransactionConditionalRejectedErrCode,
+			}
+		}
+	}
 
 	// enforce rate limit on the cost to be observed
 	if err := s.costLimiter.WaitN(ctx, cost); err != nil {
@@ -127,3 +147,38 @@ func (s *sendRawTxCond) SendRawTransactionConditional(ctx context.Context, txByt
 		return ethapi.SubmitTransaction(ctx, s.b, tx)
 	}
 }
+
+// checkArkivConditional validates the Arkiv preconditions against the state and
+// the store at the given header. Query preconditions are only supported if the
+// backend has access to the Arkiv store.
+func (s *sendRawTxCond) checkArkivConditional(ctx context.Context, state *state.StateDB, header *types.Header, cond *types.ArkivConditional) error {
+	if cond == nil {
+		return nil
+	}
+	if err := entity.CheckConditions(state, cond.Entities); err != nil {
+		return &rpc.JsonError{
+			Message: fmt.Sprintf("failed arkiv entity check: %s", err),
+			Code:    params.TransactionConditionalRejectedErrCode,
+		}
+	}
+	if len(cond.Queries) == 0 {
+		return nil
+	}
+	checker, ok := s.b.(arkivQueryChecker)
+	if !ok {
+		return &rpc.JsonError{
+			Message: "arkiv query conditions are not supported",
+			Code:    params.TransactionConditionalRejectedErrCode,
+		}
+	}
+	if err := checker.CheckArkivQueryConditions(ctx, header.Number.Uint64(), cond.Queries); err != nil {
+		if errors.Is(err, types.ErrArkivStoreNotReady) {
+			return err
+		}
+		return &rpc.JsonError{
+			Message: fmt.Sprintf("failed arkiv query check: %s", err),
+			Code:    params.TransactionConditionalRejectedErrCode,
+		}
+	}
+	return nil
+}
@@ -53,6 +53,15 @@ type BackendWithHistoricalState interface {
 	StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, readOnly bool, preferDisk bool) (*state.StateDB, tracers.StateReleaseFunc, error)
 }
 
+// BackendWithArkiv is implemented by backends that can check the query
+// preconditions of conditional transactions against the Arkiv store.
+type BackendWithArkiv interface {
+	// CheckArkivQueryConditions validates the query preconditions against the store,
+	// which must be at the block with the given number, otherwise
+	// types.ErrArkivStoreNotReady is returned. The deleted entities aren't counted.
+	CheckArkivQueryConditions(ctx context.Context, number uint64, conditions []types.ArkivQueryCondition, deleted []common.Hash) error
+}
+
 type BackendWithInterop interface {
 	CheckAccessList(ctx context.Context, inboxEntries []common.Hash, minSafety interoptypes.SafetyLevel, executingDescriptor interoptypes.ExecutingDescriptor) error
 
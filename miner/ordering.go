@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 )
 
@@ -47,6 +48,16 @@ func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee
 			tip = tx.GasTipCap
 		}
 	}
+	// Arkiv transactions are charged their intrinsic gas only, but the payload
+	// they store is what they really cost the chain. The tip they pay for their
+	// gas is spread over the larger of their gas and their payload priced like
+	// calldata, which ranks large payloads below small ones while keeping the
+	// fees a tip per gas, comparable with those of the other transactions.
+	if tx.ArkivGas > 0 {
+		weight := max(tx.ArkivGas, tx.ArkivBytes*params.TxDataNonZeroGasEIP2028)
+		tip = new(uint256.Int).Mul(tip, uint256.NewInt(tx.ArkivGas))
+		tip.Div(tip, uint256.NewInt(weight))
+	}
 	return &txWithMinerFee{
 		tx:   tx,
 		from: from,
@@ -194,3 +194,56 @@ func TestTransactionTimeSort(t *testing.T) {
 		}
 	}
 }
+
+// Tests that Arkiv transactions are ordered by the tip they pay for the gas they
+// use, weighted by their payload, independently of their gas limit.
+func TestArkivTransactionFeePerByteSort(t *testing.T) {
+	t.Parallel()
+
+	signer := types.HomesteadSigner{}
+	groups := map[common.Address][]*txpool.LazyTransaction{}
+
+	for _, tt := range []struct {
+		price    int64
+		gasLimit uint64
+		gas      uint64
+		bytes    uint64
+	}{
+		// A high gas price for a large payload ranks last: 10 * 30000 / (10000 * 16)
+		{price: 10, gasLimit: 100000, gas: 30000, bytes: 10000},
+		// A small payload pays its full tip per gas
+		{price: 5, gasLimit: 100000, gas: 30000, bytes: 100},
+		// A huge gas limit doesn't inflate the priority
+		{price: 4, gasLimit: 10000000, gas: 30000, bytes: 100},
+		// Not an Arkiv transaction
+		{price: 3, gasLimit: 100000},
+	} {
+		key, _ := crypto.GenerateKey()
+		tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(0), tt.gasLimit, big.NewInt(tt.price), nil), signer, key)
+		groups[crypto.PubkeyToAddress(key.PublicKey)] = []*txpool.LazyTransaction{{
+			Hash:       tx.Hash(),
+			Tx:         tx,
+			Time:       tx.Time(),
+			GasFeeCap:  uint256.MustFromBig(tx.GasFeeCap()),
+			GasTipCap:  uint256.MustFromBig(tx.GasTipCap()),
+			Gas:        tx.Gas(),
+			ArkivBytes: tt.bytes,
+			ArkivGas:   tt.gas,
+		}}
+	}
+	txset := newTransactionsByPriceAndNonce(signer, groups, nil)
+
+	for _, want := range []struct {
+		price int64
+		fees  uint64
+	}{{5, 5}, {4, 4}, {3, 3}, {10, 1}} {
+		tx, fees := txset.Peek()
+		if tx.Tx.GasPrice().Int64() != want.price {
+			t.Fatalf("expected the transaction with gas price %d, got %v", want.price, tx.Tx.GasPrice())
+		}
+		if fees.Uint64() != want.fees {
+			t.Errorf("invalid fees of gas price %d: have %v, want %d", want.price, fees, want.fees)
+		}
+		txset.Shift()
+	}
+}
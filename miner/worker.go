@@ -21,6 +21,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -36,6 +38,10 @@ import (
 	"github.com/ethereum/go-ethereum/core/types/interoptypes"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	arkivlogs "github.com/ethereum/go-ethereum/golem-base/logs"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/golem-base/storageutil/entity"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -51,6 +57,8 @@ const (
 var (
 	errTxConditionalInvalid = errors.New("transaction conditional failed")
 
+	errArkivConditionalDeferred = errors.New("arkiv query conditional can't be checked after arkiv changes in the block")
+
 	errBlockInterruptedByNewHead  = errors.New("new head arrived while building block")
 	errBlockInterruptedByRecommit = errors.New("recommit interrupt while building block")
 	errBlockInterruptedByTimeout  = errors.New("timeout while building block")
@@ -61,6 +69,8 @@ var (
 
 	txConditionalRejectedCounter = metrics.NewRegisteredCounter("miner/transactionConditional/rejected", nil)
 	txConditionalMinedTimer      = metrics.NewRegisteredTimer("miner/transactionConditional/elapsedtime", nil)
+
+	txArkivConditionalDeferredCounter = metrics.NewRegisteredCounter("miner/transactionConditional/arkiv/deferred", nil)
 )
 
 // environment is the worker's current environment and holds all
@@ -77,6 +87,11 @@ type environment struct {
 	// OP-Stack addition: DA footprint block limit
 	daFootprintGasScalar uint16
 
+	arkivUsage   storagetx.BlockUsage // load of the Arkiv transactions in the block, checked against the Arkiv block limits
+	arkivChanged bool                 // whether transactions in the block, including deposits, changed the Arkiv storage layer other than by deletions and renewals
+	arkivDeleted []common.Hash        // entities deleted or expired by transactions in the block
+	arkivRenewed bool                 // whether the housekeeping of the block renewed entities
+
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
@@ -455,6 +470,11 @@ func (miner *Miner) commitTransaction(env *environment, tx *types.Transaction) e
 		if err := env.state.CheckTransactionConditional(conditional); err != nil {
 			return fmt.Errorf("failed state check: %s: %w", err, errTxConditionalInvalid)
 		}
+		if conditional.Arkiv != nil {
+			if err := miner.checkArkivConditional(env, conditional.Arkiv); err != nil {
+				return err
+			}
+		}
 	}
 
 	receipt, err := miner.applyTransaction(env, tx, env.tcount)
@@ -465,9 +485,63 @@ func (miner *Miner) commitTransaction(env *environment, tx *types.Transaction) e
 	env.receipts = append(env.receipts, receipt)
 	env.size += tx.Size()
 	env.tcount++
+	for _, l := range receipt.Logs {
+		if l.Address != address.ArkivProcessorAddress || len(l.Topics) == 0 {
+			continue
+		}
+		switch l.Topics[0] {
+		case arkivlogs.ArkivEntityDeleted, arkivlogs.ArkivEntityExpired:
+			env.arkivDeleted = append(env.arkivDeleted, l.Topics[1])
+		case arkivlogs.ArkivEntityRenewed:
+			env.arkivRenewed = true
+		default:
+			env.arkivChanged = true
+		}
+	}
+	return nil
+}
+
+// checkArkivConditional validates the Arkiv preconditions of a transaction. The
+// entity preconditions are checked against the state of the block being built,
+// the query preconditions against the store at the parent block. As the store
+// doesn't reflect the transactions of the block being built, the entities they
+// deleted, including the housekeeping of expired entities, aren't counted, and
+// a transaction with query preconditions is left for a later block if other
+// changes to the Arkiv storage layer may have changed the results.
+func (miner *Miner) checkArkivConditional(env *environment, cond *types.ArkivConditional) error {
+	if err := entity.CheckConditions(env.state, cond.Entities); err != nil {
+		return fmt.Errorf("failed arkiv entity check: %s: %w", err, errTxConditionalInvalid)
+	}
+	if len(cond.Queries) == 0 {
+		return nil
+	}
+	// Renewals only change the expiration of entities
+	if env.arkivChanged || env.arkivRenewed && slices.ContainsFunc(cond.Queries, refersToExpiration) {
+		txArkivConditionalDeferredCounter.Inc(1)
+		return errArkivConditionalDeferred
+	}
+	backend, ok := miner.backend.(BackendWithArkiv)
+	if !ok {
+		return fmt.Errorf("arkiv query conditions are not supported: %w", errTxConditionalInvalid)
+	}
+	err := backend.CheckArkivQueryConditions(context.Background(), env.header.Number.Uint64()-1, cond.Queries, env.arkivDeleted)
+	switch {
+	case errors.Is(err, types.ErrArkivStoreNotReady):
+		// The store is catching up, the transaction may be included in a later block
+		return err
+	case err != nil:
+		return fmt.Errorf("failed arkiv query check: %s: %w", err, errTxConditionalInvalid)
+	}
 	return nil
 }
 
+// refersToExpiration reports whether the result of a query condition may depend
+// on the expiration of the entities. A string value containing the attribute
+// name only leaves the transaction for a later block needlessly.
+func refersToExpiration(cond types.ArkivQueryCondition) bool {
+	return strings.Contains(cond.Query, "$expiration")
+}
+
 func (miner *Miner) commitBlobTransaction(env *environment, tx *types.Transaction) error {
 	sc := tx.BlobTxSidecar()
 	if sc == nil {
@@ -502,12 +576,32 @@ func (miner *Miner) applyTransaction(env *environment, tx *types.Transaction, tx
 		snap = env.state.Snapshot()
 		gp   = env.gasPool.Gas()
 	)
-	receipt, err := core.ApplyTransaction(env.evm, env.gasPool, env.state, env.header, tx, txIx, &env.header.GasUsed)
+	msg, err := core.TransactionToMessage(tx, types.MakeSigner(miner.chainConfig, env.header.Number, env.header.Time), env.header.BaseFee, env.header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	// Arkiv addition: the Arkiv transactions issued by contract calls are only known
+	// after execution, so the Arkiv block limits are checked before the state of the
+	// transaction is finalised and can still be reverted.
+	usage := env.arkivUsage
+	if limits := miner.chainConfig.ArkivBlockLimits(env.header.Time); limits != nil {
+		msg.PostValidation = func(evm *vm.EVM, result *core.ExecutionResult) error {
+			if msg.IsDepositTx || result.Failed() {
+				return nil
+			}
+			logs := env.state.GetLogs(tx.Hash(), env.header.Number.Uint64(), common.Hash{}, env.header.Time)
+			usage = env.arkivUsage.Add(storagetx.ExecutionUsage(tx.To(), tx.Data(), logs))
+			return usage.CheckLimits(limits)
+		}
+	}
+	receipt, err := core.ApplyTransactionWithEVM(msg, env.gasPool, env.state, env.header.Number, env.header.Hash(), env.header.Time, tx, txIx, &env.header.GasUsed, env.evm)
 	if err != nil {
 		env.state.RevertToSnapshot(snap)
 		env.gasPool.SetGas(gp)
+		return receipt, err
 	}
-	return receipt, err
+	env.arkivUsage = usage
+	return receipt, nil
 }
 
 func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *transactionsByPriceAndNonce, interrupt *atomic.Int32) error {
@@ -627,6 +721,17 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			}
 		}
 
+		// Arkiv addition: skip Arkiv transactions that don't fit the remaining Arkiv
+		// block limits without executing them
+		if arkiv := miner.chainConfig.ArkivBlockLimits(env.header.Time); arkiv != nil && ltx.ArkivOps != 0 {
+			usage := env.arkivUsage.Add(storagetx.BlockUsage{PayloadBytes: ltx.ArkivBytes, Ops: ltx.ArkivOps})
+			if err := usage.CheckLimits(arkiv); err != nil {
+				log.Debug("Not enough Arkiv space left for transaction", "hash", ltx.Hash, "err", err)
+				txs.Pop()
+				continue
+			}
+		}
+
 		// Transaction seems to fit, pull it up from the pool
 		tx := ltx.Resolve()
 		if tx == nil {
@@ -670,6 +775,16 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			log.Warn("Skipping account, transaction with failed conditional", "sender", from, "hash", ltx.Hash, "err", err)
 			txs.Pop()
 
+		case errors.Is(err, errArkivConditionalDeferred):
+			// The Arkiv changes in the block may affect the query results, the store can check them in a later block
+			log.Debug("Skipping account, Arkiv query conditional deferred", "sender", from, "hash", ltx.Hash, "err", err)
+			txs.Pop()
+
+		case errors.Is(err, storagetx.ErrBlockLimitExceeded):
+			// The transaction doesn't fit the remaining Arkiv block limits, smaller ones may
+			log.Debug("Skipping account, Arkiv block limit reached", "sender", from, "hash", ltx.Hash, "err", err)
+			txs.Pop()
+
 		case env.rpcCtx != nil && env.rpcCtx.Err() != nil && errors.Is(err, env.rpcCtx.Err()):
 			log.Warn("Transaction processing aborted due to RPC context error", "err", err)
 			txs.Pop() // RPC timeout. Tx could not be checked, and thus not included, but not rejected yet.
@@ -218,6 +218,18 @@ type Config struct {
 	ArkivHistoricBlocksFlag uint64 `toml:",omitempty"`
 
 	ArkivDatabaseDisabled bool `toml:",omitempty"`
+
+	// ArkivSinkFile is the path of an append-only file receiving the Arkiv event stream.
+	ArkivSinkFile string `toml:",omitempty"`
+
+	// ArkivSinkFileFormat is the encoding of ArkivSinkFile, jsonl or rlp.
+	ArkivSinkFileFormat string `toml:",omitempty"`
+
+	// ArkivSinkWebhook is the URL the Arkiv event stream batches are posted to.
+	ArkivSinkWebhook string `toml:",omitempty"`
+
+	// ArkivSinkPlugins are paths of Go plugins consuming the Arkiv event stream.
+	ArkivSinkPlugins []string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -4,6 +4,9 @@ const (
 	// An inclusive limit on the max cost for the conditional attached to a tx
 	TransactionConditionalMaxCost = 1000
 
+	// The cost of a query precondition on the Arkiv storage layer
+	TransactionConditionalArkivQueryCost = 100
+
 	TransactionConditionalRejectedErrCode        = -32003
 	TransactionConditionalCostExceededMaxErrCode = -32005
 )
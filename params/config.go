@@ -220,24 +220,29 @@ var (
 	}
 
 	AllDevChainProtocolChanges = &ChainConfig{
-		ChainID:                 big.NewInt(1337),
-		HomesteadBlock:          big.NewInt(0),
-		EIP150Block:             big.NewInt(0),
-		EIP155Block:             big.NewInt(0),
-		EIP158Block:             big.NewInt(0),
-		ByzantiumBlock:          big.NewInt(0),
-		ConstantinopleBlock:     big.NewInt(0),
-		PetersburgBlock:         big.NewInt(0),
-		IstanbulBlock:           big.NewInt(0),
-		MuirGlacierBlock:        big.NewInt(0),
-		BerlinBlock:             big.NewInt(0),
-		LondonBlock:             big.NewInt(0),
-		ArrowGlacierBlock:       big.NewInt(0),
-		GrayGlacierBlock:        big.NewInt(0),
-		ShanghaiTime:            newUint64(0),
-		CancunTime:              newUint64(0),
-		TerminalTotalDifficulty: big.NewInt(0),
-		PragueTime:              newUint64(0),
+		ChainID:                   big.NewInt(1337),
+		HomesteadBlock:            big.NewInt(0),
+		EIP150Block:               big.NewInt(0),
+		EIP155Block:               big.NewInt(0),
+		EIP158Block:               big.NewInt(0),
+		ByzantiumBlock:            big.NewInt(0),
+		ConstantinopleBlock:       big.NewInt(0),
+		PetersburgBlock:           big.NewInt(0),
+		IstanbulBlock:             big.NewInt(0),
+		MuirGlacierBlock:          big.NewInt(0),
+		BerlinBlock:               big.NewInt(0),
+		LondonBlock:               big.NewInt(0),
+		ArrowGlacierBlock:         big.NewInt(0),
+		GrayGlacierBlock:          big.NewInt(0),
+		ShanghaiTime:              newUint64(0),
+		CancunTime:                newUint64(0),
+		TerminalTotalDifficulty:   big.NewInt(0),
+		PragueTime:                newUint64(0),
+		ArkivPrecompileTime:       newUint64(0),
+		ArkivContractCallsTime:    newUint64(0),
+		ArkivRenewalTime:          newUint64(0),
+		ArkivTypedAnnotationsTime: newUint64(0),
+		ArkivBlockLimitsTime:      newUint64(0),
 		BlobScheduleConfig: &BlobScheduleConfig{
 			Cancun: DefaultCancunBlobConfig,
 			Prague: DefaultPragueBlobConfig,
@@ -514,6 +519,12 @@ type ChainConfig struct {
 
 	InteropTime *uint64 `json:"interopTime,omitempty"` // Interop switch time (nil = no fork, 0 = already on optimism interop)
 
+	ArkivPrecompileTime       *uint64 `json:"arkivPrecompileTime,omitempty"`       // Arkiv entity metadata precompile switch time (nil = no fork, 0 = already active)
+	ArkivContractCallsTime    *uint64 `json:"arkivContractCallsTime,omitempty"`    // Arkiv transactions issued by contracts switch time (nil = no fork, 0 = already active)
+	ArkivRenewalTime          *uint64 `json:"arkivRenewalTime,omitempty"`          // Arkiv automatic entity renewal switch time (nil = no fork, 0 = already active)
+	ArkivTypedAnnotationsTime *uint64 `json:"arkivTypedAnnotationsTime,omitempty"` // Arkiv typed annotations switch time (nil = no fork, 0 = already active)
+	ArkivBlockLimitsTime      *uint64 `json:"arkivBlockLimitsTime,omitempty"`      // Arkiv block limits switch time (nil = no fork, 0 = already active)
+
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
 	TerminalTotalDifficulty *big.Int `json:"terminalTotalDifficulty,omitempty"`
@@ -540,6 +551,10 @@ type ChainConfig struct {
 
 	// Optimism config, nil if not active
 	Optimism *OptimismConfig `json:"optimism,omitempty"`
+
+	// Arkiv config, nil if the Arkiv transactions of a block are not limited. The
+	// limits are enforced since ArkivBlockLimitsTime.
+	Arkiv *ArkivConfig `json:"arkiv,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -573,6 +588,26 @@ func (o *OptimismConfig) String() string {
 	return "optimism"
 }
 
+// ArkivConfig is the Arkiv config. It limits the load that the Arkiv transactions
+// of a block put on the indexers, which is not reflected in the gas they use.
+type ArkivConfig struct {
+	MaxBlockPayloadBytes uint64 `json:"maxBlockPayloadBytes,omitempty"` // Maximum decompressed Arkiv payload bytes per block (0 = unlimited)
+	MaxBlockOps          uint64 `json:"maxBlockOps,omitempty"`          // Maximum Arkiv operations per block (0 = unlimited)
+}
+
+// String implements the stringer interface, returning the Arkiv block limits.
+func (a *ArkivConfig) String() string {
+	return fmt.Sprintf("arkiv(maxBlockPayloadBytes: %d, maxBlockOps: %d)", a.MaxBlockPayloadBytes, a.MaxBlockOps)
+}
+
+// equal returns whether both configs set the same Arkiv block limits.
+func (a *ArkivConfig) equal(b *ArkivConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // Description returns a human-readable description of ChainConfig.
 func (c *ChainConfig) Description() string {
 	var banner string
@@ -692,6 +727,27 @@ func (c *ChainConfig) Description() string {
 	if c.InteropTime != nil {
 		banner += fmt.Sprintf(" - Interop:                     @%-10v\n", *c.InteropTime)
 	}
+	if c.ArkivPrecompileTime != nil {
+		banner += fmt.Sprintf(" - Arkiv precompile:            @%-10v\n", *c.ArkivPrecompileTime)
+	}
+	if c.ArkivContractCallsTime != nil {
+		banner += fmt.Sprintf(" - Arkiv contract calls:        @%-10v\n", *c.ArkivContractCallsTime)
+	}
+	if c.ArkivRenewalTime != nil {
+		banner += fmt.Sprintf(" - Arkiv renewal:               @%-10v\n", *c.ArkivRenewalTime)
+	}
+	if c.ArkivTypedAnnotationsTime != nil {
+		banner += fmt.Sprintf(" - Arkiv typed annotations:     @%-10v\n", *c.ArkivTypedAnnotationsTime)
+	}
+	if c.ArkivBlockLimitsTime != nil {
+		banner += fmt.Sprintf(" - Arkiv block limits:          @%-10v\n", *c.ArkivBlockLimitsTime)
+	}
+	if c.Arkiv != nil {
+		banner += "\n"
+		banner += "Arkiv block limits (0 = unlimited):\n"
+		banner += fmt.Sprintf(" - Decompressed payload bytes:  %d\n", c.Arkiv.MaxBlockPayloadBytes)
+		banner += fmt.Sprintf(" - Operations:                  %d\n", c.Arkiv.MaxBlockOps)
+	}
 	return banner
 }
 
@@ -905,6 +961,45 @@ func (c *ChainConfig) IsInterop(time uint64) bool {
 	return isTimestampForked(c.InteropTime, time)
 }
 
+// IsArkivPrecompile returns whether time is either equal to the Arkiv precompile
+// fork time or greater.
+func (c *ChainConfig) IsArkivPrecompile(time uint64) bool {
+	return isTimestampForked(c.ArkivPrecompileTime, time)
+}
+
+// IsArkivContractCalls returns whether time is either equal to the Arkiv contract
+// calls fork time or greater.
+func (c *ChainConfig) IsArkivContractCalls(time uint64) bool {
+	return isTimestampForked(c.ArkivContractCallsTime, time)
+}
+
+// IsArkivRenewal returns whether time is either equal to the Arkiv renewal fork
+// time or greater.
+func (c *ChainConfig) IsArkivRenewal(time uint64) bool {
+	return isTimestampForked(c.ArkivRenewalTime, time)
+}
+
+// IsArkivTypedAnnotations returns whether time is either equal to the Arkiv typed
+// annotations fork time or greater.
+func (c *ChainConfig) IsArkivTypedAnnotations(time uint64) bool {
+	return isTimestampForked(c.ArkivTypedAnnotationsTime, time)
+}
+
+// IsArkivBlockLimits returns whether time is either equal to the Arkiv block
+// limits fork time or greater.
+func (c *ChainConfig) IsArkivBlockLimits(time uint64) bool {
+	return isTimestampForked(c.ArkivBlockLimitsTime, time)
+}
+
+// ArkivBlockLimits returns the Arkiv block limits enforced at time, or nil if the
+// Arkiv transactions of a block are not limited.
+func (c *ChainConfig) ArkivBlockLimits(time uint64) *ArkivConfig {
+	if !c.IsArkivBlockLimits(time) {
+		return nil
+	}
+	return c.Arkiv
+}
+
 // IsOptimism returns whether the node is an optimism node or not.
 func (c *ChainConfig) IsOptimism() bool {
 	return c.Optimism != nil
@@ -1222,6 +1317,25 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.InteropTime, newcfg.InteropTime, headTimestamp, genesisTimestamp) {
 		return newTimestampCompatError("Interop fork timestamp", c.InteropTime, newcfg.InteropTime)
 	}
+	if isForkTimestampIncompatible(c.ArkivPrecompileTime, newcfg.ArkivPrecompileTime, headTimestamp, genesisTimestamp) {
+		return newTimestampCompatError("Arkiv precompile fork timestamp", c.ArkivPrecompileTime, newcfg.ArkivPrecompileTime)
+	}
+	if isForkTimestampIncompatible(c.ArkivContractCallsTime, newcfg.ArkivContractCallsTime, headTimestamp, genesisTimestamp) {
+		return newTimestampCompatError("Arkiv contract calls fork timestamp", c.ArkivContractCallsTime, newcfg.ArkivContractCallsTime)
+	}
+	if isForkTimestampIncompatible(c.ArkivRenewalTime, newcfg.ArkivRenewalTime, headTimestamp, genesisTimestamp) {
+		return newTimestampCompatError("Arkiv renewal fork timestamp", c.ArkivRenewalTime, newcfg.ArkivRenewalTime)
+	}
+	if isForkTimestampIncompatible(c.ArkivTypedAnnotationsTime, newcfg.ArkivTypedAnnotationsTime, headTimestamp, genesisTimestamp) {
+		return newTimestampCompatError("Arkiv typed annotations fork timestamp", c.ArkivTypedAnnotationsTime, newcfg.ArkivTypedAnnotationsTime)
+	}
+	if isForkTimestampIncompatible(c.ArkivBlockLimitsTime, newcfg.ArkivBlockLimitsTime, headTimestamp, genesisTimestamp) {
+		return newTimestampCompatError("Arkiv block limits fork timestamp", c.ArkivBlockLimitsTime, newcfg.ArkivBlockLimitsTime)
+	}
+	// The Arkiv block limits can't change once they are enforced.
+	if c.IsArkivBlockLimits(headTimestamp) && !c.Arkiv.equal(newcfg.Arkiv) {
+		return newTimestampCompatError("Arkiv block limits", c.ArkivBlockLimitsTime, newcfg.ArkivBlockLimitsTime)
+	}
 	return nil
 }
 
@@ -1503,6 +1617,8 @@ type Rules struct {
 	IsOptimismCanyon, IsOptimismFjord                       bool
 	IsOptimismGranite, IsOptimismHolocene                   bool
 	IsOptimismIsthmus, IsOptimismJovian                     bool
+	IsArkivPrecompile, IsArkivContractCalls                 bool
+	IsArkivRenewal, IsArkivTypedAnnotations                 bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -1543,6 +1659,11 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsOptimismHolocene: isMerge && c.IsOptimismHolocene(timestamp),
 		IsOptimismIsthmus:  isMerge && c.IsOptimismIsthmus(timestamp),
 		IsOptimismJovian:   isMerge && c.IsOptimismJovian(timestamp),
+		// Arkiv
+		IsArkivPrecompile:       isMerge && c.IsArkivPrecompile(timestamp),
+		IsArkivContractCalls:    isMerge && c.IsArkivContractCalls(timestamp),
+		IsArkivRenewal:          isMerge && c.IsArkivRenewal(timestamp),
+		IsArkivTypedAnnotations: isMerge && c.IsArkivTypedAnnotations(timestamp),
 	}
 }
 
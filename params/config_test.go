@@ -167,6 +167,22 @@ func TestCheckCompatible(t *testing.T) {
 				NewTime:      newUint64(20),
 				RewindToTime: 9,
 			},
+		},		{
+			stored:        &ChainConfig{ArkivBlockLimitsTime: newUint64(10), Arkiv: &ArkivConfig{MaxBlockOps: 100}},
+			new:           &ChainConfig{ArkivBlockLimitsTime: newUint64(10), Arkiv: &ArkivConfig{MaxBlockOps: 200}},
+			headTimestamp: 9,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{ArkivBlockLimitsTime: newUint64(10), Arkiv: &ArkivConfig{MaxBlockOps: 100}},
+			new:           &ChainConfig{ArkivBlockLimitsTime: newUint64(10), Arkiv: &ArkivConfig{MaxBlockOps: 200}},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "Arkiv block limits",
+				StoredTime:   newUint64(10),
+				NewTime:      newUint64(10),
+				RewindToTime: 9,
+			},
 		},
 	}
 
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -46,7 +47,7 @@ const (
 	// ExternalAPIVersion -- see extapi_changelog.md
 	ExternalAPIVersion = "6.1.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.1.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -226,6 +227,8 @@ type (
 		Transaction apitypes.SendTxArgs       `json:"transaction"`
 		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
 		Meta        Metadata                  `json:"meta"`
+		// The decoded operations, if the transaction is sent to the Arkiv processor
+		Arkiv *storagetx.ArkivTransaction `json:"arkiv,omitempty"`
 	}
 	// SignTxResponse result from SignTxRequest
 	SignTxResponse struct {
@@ -548,6 +551,7 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 	if err != nil {
 		return nil, err
 	}
+	arkiv := decodeArkivTransaction(&args, msgs)
 	// If we are in 'rejectMode', then reject rather than show the user warnings
 	if api.rejectMode {
 		if err := msgs.GetWarnings(); err != nil {
@@ -567,6 +571,7 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 		Transaction: args,
 		Meta:        MetadataFromContext(ctx),
 		Callinfo:    msgs.Messages,
+		Arkiv:       arkiv,
 	}
 	// Process approval
 	result, err = api.UI.ApproveTx(&req)
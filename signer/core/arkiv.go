@@ -0,0 +1,101 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// decodeArkivTransaction decodes the operations of a transaction sent to the
+// Arkiv processor, so that they can be shown to the user and checked by the
+// rules. It returns nil for any other transaction. Transactions the processor
+// would reject are flagged with a warning.
+func decodeArkivTransaction(args *apitypes.SendTxArgs, msgs *apitypes.ValidationMessages) *storagetx.ArkivTransaction {
+	if args.To == nil || args.To.Address() != address.ArkivProcessorAddress {
+		return nil
+	}
+	var data []byte
+	if args.Input != nil {
+		data = *args.Input
+	} else if args.Data != nil {
+		data = *args.Data
+	}
+	tx, err := storagetx.UnpackArkivTransaction(data)
+	if err != nil {
+		msgs.Warn(fmt.Sprintf("Invalid Arkiv transaction: %v", err))
+		return nil
+	}
+	if err := tx.Validate(); err != nil {
+		msgs.Warn(fmt.Sprintf("Invalid Arkiv transaction: %v", err))
+	}
+	msgs.Info(fmt.Sprintf("Arkiv transaction: %d create, %d update, %d delete, %d extend, %d change owner, %d renew",
+		len(tx.Create), len(tx.Update), len(tx.Delete), len(tx.Extend), len(tx.ChangeOwner), len(tx.Renew)))
+	return tx
+}
+
+// showArkivOperations prints the operations of an Arkiv transaction.
+func showArkivOperations(tx *storagetx.ArkivTransaction) {
+	fmt.Printf("\nArkiv operations:\n")
+	for i, op := range tx.Create {
+		fmt.Printf("  create[%d]:       btl %d, content type %q, %d payload bytes\n", i, op.BTL, op.ContentType, len(op.Payload))
+		showArkivAnnotations(op.Annotations())
+	}
+	for i, op := range tx.Update {
+		fmt.Printf("  update[%d]:       %v, btl %d, content type %q, %d payload bytes\n", i, op.EntityKey, op.BTL, op.ContentType, len(op.Payload))
+		showArkivAnnotations(op.Annotations())
+	}
+	for i, key := range tx.Delete {
+		fmt.Printf("  delete[%d]:       %v\n", i, key)
+	}
+	for i, op := range tx.Extend {
+		fmt.Printf("  extend[%d]:       %v, by %d blocks\n", i, op.EntityKey, op.NumberOfBlocks)
+	}
+	for i, op := range tx.ChangeOwner {
+		fmt.Printf("  changeOwner[%d]:  %v, to %v\n", i, op.EntityKey, op.NewOwner)
+	}
+	for i, op := range tx.Renew {
+		fmt.Printf("  renew[%d]:        %v, every %d blocks, deposit %v wei\n", i, op.EntityKey, op.Period, op.Deposit)
+	}
+}
+
+func showArkivAnnotations(annotations storagetx.Annotations) {
+	for _, a := range annotations.String {
+		fmt.Printf("    %s = %q\n", a.Key, a.Value)
+	}
+	for _, a := range annotations.Numeric {
+		fmt.Printf("    %s = %d\n", a.Key, a.Value)
+	}
+	for _, a := range annotations.Int {
+		fmt.Printf("    %s = %s (int)\n", a.Key, a.Value)
+	}
+	for _, a := range annotations.Decimal {
+		fmt.Printf("    %s = %s (decimal)\n", a.Key, a.Value)
+	}
+	for _, a := range annotations.Bool {
+		fmt.Printf("    %s = %t\n", a.Key, a.Value)
+	}
+	for _, a := range annotations.Address {
+		fmt.Printf("    %s = %v\n", a.Key, a.Value)
+	}
+	for _, a := range annotations.Bytes32 {
+		fmt.Printf("    %s = %v\n", a.Key, a.Value)
+	}
+}
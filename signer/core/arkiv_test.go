@@ -0,0 +1,89 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arkiv/compression"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func TestDecodeArkivTransaction(t *testing.T) {
+	t.Parallel()
+	processor := common.NewMixedcaseAddress(address.ArkivProcessorAddress)
+	other := common.NewMixedcaseAddress(common.HexToAddress("0xdead"))
+	pack := func(tx *storagetx.ArkivTransaction) *hexutil.Bytes {
+		data := hexutil.Bytes(compression.MustBrotliCompress(rlp.AppendUint64(nil, 0)))
+		if tx != nil {
+			enc, err := rlp.EncodeToBytes(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = compression.MustBrotliCompress(enc)
+		}
+		return &data
+	}
+	valid := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{
+			BTL:               100,
+			ContentType:       "text/plain",
+			Payload:           []byte("hello"),
+			StringAnnotations: []storagetx.StringAnnotation{{Key: "kind", Value: "greeting"}},
+		}},
+		Extend: []storagetx.ExtendBTL{{EntityKey: common.HexToHash("0x01"), NumberOfBlocks: 10}},
+	}
+	invalid := &storagetx.ArkivTransaction{
+		Create: []storagetx.ArkivCreate{{BTL: 0, ContentType: "text/plain"}},
+	}
+
+	for i, tt := range []struct {
+		args     apitypes.SendTxArgs
+		want     *storagetx.ArkivTransaction
+		warnings bool
+	}{
+		// Not sent to the processor
+		{apitypes.SendTxArgs{To: &other, Input: pack(valid)}, nil, false},
+		{apitypes.SendTxArgs{Input: pack(valid)}, nil, false},
+		// Valid operations, given as input or as data
+		{apitypes.SendTxArgs{To: &processor, Input: pack(valid)}, valid, false},
+		{apitypes.SendTxArgs{To: &processor, Data: pack(valid)}, valid, false},
+		// Decodable, but rejected by the processor
+		{apitypes.SendTxArgs{To: &processor, Input: pack(invalid)}, invalid, true},
+		// Not an Arkiv transaction
+		{apitypes.SendTxArgs{To: &processor, Input: pack(nil)}, nil, true},
+		{apitypes.SendTxArgs{To: &processor, Input: &hexutil.Bytes{0x01, 0x02}}, nil, true},
+	} {
+		msgs := new(apitypes.ValidationMessages)
+		got := decodeArkivTransaction(&tt.args, msgs)
+		// Compared by encoding, as decoding yields empty rather than nil lists
+		gotEnc, _ := rlp.EncodeToBytes(got)
+		wantEnc, _ := rlp.EncodeToBytes(tt.want)
+		if (got == nil) != (tt.want == nil) || !bytes.Equal(gotEnc, wantEnc) {
+			t.Errorf("test %d: got %+v, want %+v", i, got, tt.want)
+		}
+		if warnings := msgs.GetWarnings() != nil; warnings != tt.warnings {
+			t.Errorf("test %d: warnings %v, want %v: %v", i, warnings, tt.warnings, msgs.Messages)
+		}
+	}
+}
@@ -148,6 +148,9 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 			fmt.Printf("data:     %v\n", hexutil.Encode(d))
 		}
 	}
+	if request.Arkiv != nil {
+		showArkivOperations(request.Arkiv)
+	}
 	if request.Callinfo != nil {
 		fmt.Printf("\nTransaction validation:\n")
 		for _, m := range request.Callinfo {
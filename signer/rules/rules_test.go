@@ -26,6 +26,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/golem-base/address"
+	"github.com/ethereum/go-ethereum/golem-base/storagetx"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/signer/core"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
@@ -196,6 +198,54 @@ func TestSignTxRequest(t *testing.T) {
 	}
 }
 
+func TestArkivTxRequest(t *testing.T) {
+	t.Parallel()
+	js := `
+	function count(ops) { return ops ? ops.length : 0 }
+	function ApproveTx(r){
+		if (!r.arkiv) { return "Reject" }
+		var a = r.arkiv;
+		if (count(a.create) + count(a.update) + count(a.delete) + count(a.changeOwner) > 0) { return "Reject" }
+		for (var i = 0; i < count(a.extend); i++) {
+			if (a.extend[i].numberOfBlocks > 1000) { return "Reject" }
+		}
+		return "Approve"
+	}`
+
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	from, _ := mixAddr("0000000000000000000000000000000000001337")
+	to := common.NewMixedcaseAddress(address.ArkivProcessorAddress)
+	key := common.HexToHash("0x01")
+
+	for i, tt := range []struct {
+		arkiv    *storagetx.ArkivTransaction
+		approved bool
+	}{
+		{nil, false},
+		{&storagetx.ArkivTransaction{Extend: []storagetx.ExtendBTL{{EntityKey: key, NumberOfBlocks: 100}}}, true},
+		{&storagetx.ArkivTransaction{Extend: []storagetx.ExtendBTL{{EntityKey: key, NumberOfBlocks: 5000}}}, false},
+		{&storagetx.ArkivTransaction{
+			Delete: []common.Hash{key},
+			Extend: []storagetx.ExtendBTL{{EntityKey: key, NumberOfBlocks: 100}},
+		}, false},
+		{&storagetx.ArkivTransaction{Create: []storagetx.ArkivCreate{{BTL: 100, ContentType: "text/plain"}}}, false},
+	} {
+		resp, err := r.ApproveTx(&core.SignTxRequest{
+			Transaction: apitypes.SendTxArgs{From: *from, To: &to},
+			Arkiv:       tt.arkiv,
+		})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approved %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+}
+
 type dummyUI struct {
 	calls []string
 }